@@ -0,0 +1,313 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdCompletion = &command{
+	Name:      "completion",
+	UsageLine: "completion bash|zsh|fish",
+	Short:     "print a shell completion script",
+	Long: `
+Completion prints a script completing envfile commands, their flags and
+file arguments for the given shell. Commands taking key names, such as
+get, complete the keys of the file Load would select in the current
+directory.
+
+To enable completion, add the matching line to the shell's startup file:
+
+	source <(envfile completion bash)          # ~/.bashrc
+	source <(envfile completion zsh)           # ~/.zshrc
+	envfile completion fish | source           # ~/.config/fish/config.fish
+`,
+}
+
+var cmdMan = &command{
+	Name:      "man",
+	UsageLine: "man [-dir dir]",
+	Short:     "generate manual pages",
+	Long: `
+Man prints the envfile(1) manual page, describing every command, in roff
+format. With -dir, it instead writes envfile.1 and one envfile-COMMAND.1
+page per command to the directory, ready to be installed in a man1
+directory.
+`,
+}
+
+// cmdComplete is invoked by the completion scripts. It is not listed in
+// "envfile help".
+var cmdComplete = &command{
+	Name:      "__complete",
+	UsageLine: "__complete keys",
+	Short:     "list completion candidates",
+}
+
+var manDir string
+
+func init() {
+	cmdCompletion.Run = runCompletion
+	cmdMan.Run = runMan
+	cmdMan.Flag.StringVar(&manDir, "dir", "", "write one page per command to `dir`")
+	cmdComplete.Run = runComplete
+}
+
+func runCompletion(cmd *command, args []string) error {
+	if len(args) != 1 {
+		cmd.usage()
+		return exitError(2)
+	}
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout)
+	case "zsh":
+		writeZshCompletion(os.Stdout)
+	case "fish":
+		writeFishCompletion(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "envfile completion: unsupported shell '%s'\n", args[0])
+		return exitError(2)
+	}
+	return nil
+}
+
+func runComplete(cmd *command, args []string) error {
+	if len(args) != 1 || args[0] != "keys" {
+		return exitError(2)
+	}
+	env, err := envfile.New(envfile.WithLogger(nil)).Environment()
+	if err != nil {
+		// Nothing to complete.
+		return nil
+	}
+	for _, key := range env.Keys() {
+		fmt.Println(key)
+	}
+	return nil
+}
+
+// commandNames returns the names of the listed commands, including help.
+func commandNames() []string {
+	names := []string{"help"}
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// flagNames returns the flags of cmd, each with a leading dash.
+func flagNames(cmd *command) []string {
+	var names []string
+	cmd.Flag.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintf(w, `# bash completion for envfile
+_envfile() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	if [ "$COMP_CWORD" -eq 1 ]; then
+		COMPREPLY=($(compgen -W %q -- "$cur"))
+		return
+	fi
+	case "${COMP_WORDS[1]}" in
+	help)
+		COMPREPLY=($(compgen -W %q -- "$cur"))
+		;;
+`, strings.Join(commandNames(), " "), strings.Join(commandNames()[1:], " "))
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%s)\n", cmd.Name)
+		fmt.Fprintf(w, "\t\tif [[ \"$cur\" == -* ]]; then\n")
+		fmt.Fprintf(w, "\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(flagNames(cmd), " "))
+		if cmd.CompleteKeys {
+			fmt.Fprintf(w, "\t\telse\n")
+			fmt.Fprintf(w, "\t\t\tCOMPREPLY=($(compgen -W \"$(envfile __complete keys 2>/dev/null)\" -- \"$cur\"))\n")
+		}
+		fmt.Fprintf(w, "\t\tfi\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, `	esac
+}
+complete -o default -F _envfile envfile
+`)
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, "#compdef envfile\n# zsh completion for envfile\n_envfile() {\n\tlocal -a commands\n\tcommands=(\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote(cmd.Name+":"+cmd.Short))
+	}
+	fmt.Fprintf(w, `	)
+	if (( CURRENT == 2 )); then
+		_describe 'command' commands
+		return
+	fi
+	case $words[2] in
+	help)
+		_describe 'command' commands
+		;;
+`)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%s)\n", cmd.Name)
+		fmt.Fprintf(w, "\t\tif [[ $PREFIX == -* ]]; then\n")
+		fmt.Fprintf(w, "\t\t\tcompadd -- %s\n", strings.Join(flagNames(cmd), " "))
+		fmt.Fprintf(w, "\t\telse\n")
+		if cmd.CompleteKeys {
+			fmt.Fprintf(w, "\t\t\tcompadd -- ${(f)\"$(envfile __complete keys 2>/dev/null)\"}\n")
+		} else {
+			fmt.Fprintf(w, "\t\t\t_files\n")
+		}
+		fmt.Fprintf(w, "\t\tfi\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, `	esac
+}
+if [ "$funcstack[1]" = "_envfile" ]; then
+	_envfile "$@"
+else
+	compdef _envfile envfile
+fi
+`)
+}
+
+// shellQuote quotes s for sh-like shells, zsh and fish.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for envfile\ncomplete -c envfile -f\n")
+	fmt.Fprintf(w, "complete -c envfile -n __fish_use_subcommand -a help -d 'show help for a command'\n")
+	fmt.Fprintf(w, "complete -c envfile -n '__fish_seen_subcommand_from help' -a %s\n", shellQuote(strings.Join(commandNames()[1:], " ")))
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c envfile -n __fish_use_subcommand -a %s -d %s\n", cmd.Name, shellQuote(cmd.Short))
+		condition := shellQuote("__fish_seen_subcommand_from " + cmd.Name)
+		cmd.Flag.VisitAll(func(f *flag.Flag) {
+			_, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(w, "complete -c envfile -n %s -o %s -d %s\n", condition, f.Name, shellQuote(usage))
+		})
+		if cmd.CompleteKeys {
+			fmt.Fprintf(w, "complete -c envfile -n %s -a '(envfile __complete keys 2>/dev/null)'\n", condition)
+		} else {
+			fmt.Fprintf(w, "complete -c envfile -n %s -F\n", condition)
+		}
+	}
+}
+
+func runMan(cmd *command, args []string) error {
+	if len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+	if manDir == "" {
+		writeManPage(os.Stdout, nil)
+		return nil
+	}
+
+	if err := os.MkdirAll(manDir, 0o755); err != nil {
+		return err
+	}
+	pages := map[string]*command{"envfile.1": nil}
+	for _, c := range commands {
+		pages["envfile-"+c.Name+".1"] = c
+	}
+	names := make([]string, 0, len(pages))
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file, err := os.Create(filepath.Join(manDir, name))
+		if err != nil {
+			return err
+		}
+		writeManPage(file, pages[name])
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeManPage writes the page of cmd, or the envfile(1) page describing
+// every command if cmd is nil.
+func writeManPage(w io.Writer, cmd *command) {
+	date := time.Now().Format("January 2006")
+	if cmd == nil {
+		fmt.Fprintf(w, ".TH ENVFILE 1 %q\n", date)
+		fmt.Fprintf(w, ".SH NAME\nenvfile \\- inspect and manipulate .env files\n")
+		fmt.Fprintf(w, ".SH SYNOPSIS\n.B envfile\n\\fIcommand\\fR [\\fIarguments\\fR]\n")
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, c := range commands {
+			fmt.Fprintf(w, ".SS %s\n", roffEscape(c.Name))
+			writeManCommand(w, c)
+		}
+		fmt.Fprintf(w, ".SH SEE ALSO\nRun \\fBenvfile help\\fR \\fIcommand\\fR for the same information.\n")
+		return
+	}
+
+	fmt.Fprintf(w, ".TH ENVFILE\\-%s 1 %q\n", strings.ToUpper(roffEscape(cmd.Name)), date)
+	fmt.Fprintf(w, ".SH NAME\nenvfile\\-%s \\- %s\n", roffEscape(cmd.Name), roffEscape(cmd.Short))
+	fmt.Fprintf(w, ".SH SYNOPSIS\n")
+	writeManCommand(w, cmd)
+	fmt.Fprintf(w, ".SH SEE ALSO\n.BR envfile (1)\n")
+}
+
+// writeManCommand writes the usage, description and flags of cmd.
+func writeManCommand(w io.Writer, cmd *command) {
+	fmt.Fprintf(w, ".B envfile %s\n", roffEscape(cmd.UsageLine))
+	if long := strings.TrimSpace(cmd.Long); long != "" {
+		fmt.Fprintf(w, ".PP\n")
+		indented := false
+		for _, line := range strings.Split(long, "\n") {
+			if strings.HasPrefix(line, "\t") != indented {
+				indented = !indented
+				if indented {
+					fmt.Fprintf(w, ".RS\n.nf\n")
+				} else {
+					fmt.Fprintf(w, ".fi\n.RE\n")
+				}
+			}
+			switch {
+			case line == "":
+				fmt.Fprintf(w, ".PP\n")
+			case indented:
+				fmt.Fprintf(w, "%s\n", roffEscape(strings.TrimPrefix(line, "\t")))
+			default:
+				fmt.Fprintf(w, "%s\n", roffEscape(line))
+			}
+		}
+		if indented {
+			fmt.Fprintf(w, ".fi\n.RE\n")
+		}
+	}
+	if hasFlags(&cmd.Flag) {
+		fmt.Fprintf(w, ".PP\nFlags:\n")
+		cmd.Flag.VisitAll(func(f *flag.Flag) {
+			name, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(w, ".TP\n\\fB\\-%s\\fR", roffEscape(f.Name))
+			if name != "" {
+				fmt.Fprintf(w, " \\fI%s\\fR", roffEscape(name))
+			}
+			fmt.Fprintf(w, "\n%s\n", roffEscape(usage))
+		})
+	}
+}
+
+// roffEscape escapes s for use in a roff text line.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdDump = &command{
+	Name:      "dump",
+	UsageLine: "dump [-dir dir] [-profile name] [-unsafe] [-o file]",
+	Short:     "write the effective environment for a bug report",
+	Long: `
+Dump writes the effective environment, as envfile.DumpEffective does:
+the variables of the file Load would select, of the defaults and of the
+process environment where Load would keep it, fully interpolated and
+merged, as .env content preceded by a comment with the time and the
+profile. Attach the output to a bug report about a failing deployment.
+
+Values that look like secrets are masked unless -unsafe is set. The
+output goes to the file named by -o, created readable only by its owner,
+or to standard output.
+
+The profile is read from GO_ENV unless -profile is given.
+`,
+}
+
+var (
+	dumpDir     string
+	dumpProfile string
+	dumpUnsafe  bool
+	dumpOutput  string
+)
+
+func init() {
+	cmdDump.Run = runDump
+	cmdDump.Flag.StringVar(&dumpDir, "dir", "", "search `dir` instead of the current directory")
+	cmdDump.Flag.StringVar(&dumpProfile, "profile", "", "profile `name` to use instead of GO_ENV")
+	cmdDump.Flag.BoolVar(&dumpUnsafe, "unsafe", false, "write secret values instead of masking them")
+	cmdDump.Flag.StringVar(&dumpOutput, "o", "", "write to `file` instead of standard output")
+}
+
+func runDump(cmd *command, args []string) error {
+	if len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+
+	opts := []envfile.Option{envfile.WithLogger(nil)}
+	if dumpDir != "" {
+		opts = append(opts, envfile.WithDir(dumpDir))
+	}
+	if dumpProfile != "" {
+		opts = append(opts, envfile.WithProfile(dumpProfile))
+	}
+	if dumpUnsafe {
+		opts = append(opts, envfile.WithUnsafeDump())
+	}
+
+	loader := envfile.New(opts...)
+	if dumpOutput != "" {
+		return loader.DumpEffective(dumpOutput)
+	}
+	return loader.WriteEffective(os.Stdout)
+}
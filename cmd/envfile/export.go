@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+	"github.com/lucap9056/go-envfile/envfile/convert"
+)
+
+var cmdExport = &command{
+	Name:      "export",
+	UsageLine: "export [-format format] [-paths mapping] [-secrets-from arn] [-environment-file path] [-o file] [files...]",
+	Short:     "write variables for a CI system",
+	Long: `
+Export reads the given files, or selects a file the same way Load does
+when none are given, and writes the variables in a format that a CI
+system uses to pass environment variables between steps or jobs, or
+that infrastructure tools read.
+
+The -format flag selects the output format:
+
+	dotenv  plain .env content (the default)
+	github  the GitHub Actions GITHUB_ENV file
+	gitlab  a GitLab CI dotenv report artifact
+	tfvars  a Terraform terraform.tfvars file
+	helm    a Helm values.yaml fragment
+	ecs     the environment JSON of an ECS container definition
+	systemd a systemd drop-in setting Environment= of a service
+	systemd-env
+	        a file for the EnvironmentFile= of a systemd service
+
+With -format helm, the -paths flag maps keys to dotted paths in the
+values tree as comma-separated KEY=path pairs, such as
+IMAGE_TAG=image.tag,REPLICAS=replicaCount. Keys that are not mapped are
+skipped. Without -paths, every key is written at the top level.
+
+With -format ecs, the -secrets-from flag writes the variables that look
+like secrets as "secrets" entries whose valueFrom is the given ARN
+prefix followed by the key, such as
+
+	envfile export -format ecs -secrets-from arn:aws:ssm:eu-west-1:123456789012:parameter/app/ .env.prod
+
+so that ECS reads them from SSM Parameter Store or Secrets Manager and
+their values never appear in the task definition.
+
+With -format systemd, the -environment-file flag writes a drop-in that
+references the given absolute path with EnvironmentFile= instead of
+listing the variables, which keeps their values out of the unit
+configuration; write that file with -format systemd-env:
+
+	envfile export -format systemd-env -o /etc/app/env .env.prod
+	envfile export -format systemd -environment-file /etc/app/env > /etc/systemd/system/app.service.d/env.conf
+
+The variables are written to standard output, or appended to the file
+named by -o. For example, in a GitHub Actions step:
+
+	envfile export -format github -o "$GITHUB_ENV" .env
+`,
+}
+
+var (
+	exportFormat  string
+	exportOutput  string
+	exportPaths   string
+	exportARN     string
+	exportEnvFile string
+)
+
+func init() {
+	cmdExport.Run = runExport
+	cmdExport.Flag.StringVar(&exportFormat, "format", "dotenv", "output format: dotenv, github, gitlab, tfvars, helm, ecs, systemd or systemd-env")
+	cmdExport.Flag.StringVar(&exportPaths, "paths", "", "with -format helm, map keys to values paths as comma-separated KEY=path `pairs`")
+	cmdExport.Flag.StringVar(&exportARN, "secrets-from", "", "with -format ecs, write secrets as references to the `arn` prefix followed by the key")
+	cmdExport.Flag.StringVar(&exportEnvFile, "environment-file", "", "with -format systemd, reference the variables in the file at `path` instead of listing them")
+	cmdExport.Flag.StringVar(&exportOutput, "o", "", "append to `file` instead of writing to standard output")
+}
+
+func runExport(cmd *command, args []string) error {
+	var write func(io.Writer, *envfile.Environment) error
+	switch exportFormat {
+	case "dotenv":
+		write = writeDotenv
+	case "github":
+		write = convert.WriteGitHubEnv
+	case "gitlab":
+		write = convert.WriteGitLabDotenv
+	case "tfvars":
+		write = convert.WriteTFVars
+	case "helm":
+		paths, err := helmPaths(exportPaths)
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer, env *envfile.Environment) error {
+			return convert.WriteHelmValues(w, env, paths)
+		}
+	case "ecs":
+		var secrets *convert.ECSSecrets
+		if exportARN != "" {
+			secrets = &convert.ECSSecrets{ValueFrom: exportARN}
+		}
+		write = func(w io.Writer, env *envfile.Environment) error {
+			return convert.WriteECS(w, env, secrets)
+		}
+	case "systemd":
+		dropIn := &convert.SystemdDropIn{EnvironmentFile: exportEnvFile}
+		write = func(w io.Writer, env *envfile.Environment) error {
+			return convert.WriteSystemdDropIn(w, env, dropIn)
+		}
+	case "systemd-env":
+		write = convert.WriteSystemdEnvironmentFile
+	default:
+		return fmt.Errorf("unknown format '%s'", exportFormat)
+	}
+
+	env, err := readEnvironment(args)
+	if err != nil {
+		return err
+	}
+
+	// Render everything first, so that an invalid variable never leaves
+	// a partial file behind.
+	var buf bytes.Buffer
+	if err := write(&buf, env); err != nil {
+		return err
+	}
+
+	if exportOutput == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	f, err := os.OpenFile(exportOutput, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeDotenv(w io.Writer, env *envfile.Environment) error {
+	content, err := env.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// helmPaths parses the -paths flag. An empty flag returns nil, which
+// writes every key at the top level.
+func helmPaths(flag string) (map[string]string, error) {
+	if flag == "" {
+		return nil, nil
+	}
+	paths := make(map[string]string)
+	for _, pair := range strings.Split(flag, ",") {
+		key, p, found := strings.Cut(pair, "=")
+		if !found || key == "" || p == "" {
+			return nil, fmt.Errorf("invalid -paths pair '%s': expected KEY=path", pair)
+		}
+		paths[key] = p
+	}
+	return paths, nil
+}
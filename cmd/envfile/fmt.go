@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdFmt = &command{
+	Name:      "fmt",
+	UsageLine: "fmt [-l] [-w [-backup]] [-sort] [-align] [files...]",
+	Short:     "format .env files",
+	Long: `
+Fmt rewrites each file (".env" by default), or standard input if the
+only file is "-", in canonical form: definitions are written as
+KEY=value, without indentation or spaces around '=', trailing whitespace
+and runs of blank lines are removed, and inline comments are separated
+from values by a single space. Values, including their quotes, are never
+changed.
+
+With -sort, the definitions of each block of lines separated by blank
+lines are sorted by key, together with the comments above them. With
+-align, the inline comments of consecutive definitions are aligned.
+
+By default the formatted content is printed. With -w, files are
+rewritten atomically in place instead, keeping their previous content as
+timestamped .bak files with -backup. With -l, the names of the files whose
+formatting differs are printed and, unless -w is also given, fmt exits
+with status 1 if there are any, which suits pre-commit hooks.
+`,
+}
+
+var (
+	fmtList   bool
+	fmtWrite  bool
+	fmtSort   bool
+	fmtAlign  bool
+	fmtBackup bool
+)
+
+func init() {
+	cmdFmt.Run = runFmt
+	cmdFmt.Flag.BoolVar(&fmtList, "l", false, "list files whose formatting differs")
+	cmdFmt.Flag.BoolVar(&fmtWrite, "w", false, "write the result to the files instead of standard output")
+	cmdFmt.Flag.BoolVar(&fmtSort, "sort", false, "sort the keys of each block")
+	cmdFmt.Flag.BoolVar(&fmtAlign, "align", false, "align inline comments")
+	cmdFmt.Flag.BoolVar(&fmtBackup, "backup", false, "with -w, keep the previous content as a timestamped .bak file")
+}
+
+func runFmt(cmd *command, args []string) error {
+	if len(args) == 0 {
+		args = []string{".env"}
+	}
+	formatter := &envfile.Formatter{SortKeys: fmtSort, AlignComments: fmtAlign}
+
+	if len(args) == 1 && args[0] == "-" {
+		if fmtWrite {
+			return fmt.Errorf("cannot use -w with standard input")
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		formatted, err := formatter.Format(src)
+		if err != nil {
+			return err
+		}
+		if fmtList {
+			if !bytes.Equal(src, formatted) {
+				fmt.Fprintln(os.Stdout, "<standard input>")
+				return exitError(1)
+			}
+			return nil
+		}
+		_, err = os.Stdout.Write(formatted)
+		return err
+	}
+
+	differ := false
+	for _, path := range args {
+		changed, err := formatFile(formatter, path)
+		if err != nil {
+			return err
+		}
+		if fmtList && changed {
+			fmt.Fprintln(os.Stdout, path)
+			differ = true
+		}
+	}
+	if differ && !fmtWrite {
+		return exitError(1)
+	}
+	return nil
+}
+
+// formatFile formats the file at path, rewriting it with -w and printing
+// the result unless -l or -w is set, and reports whether its formatting
+// differs.
+func formatFile(formatter *envfile.Formatter, path string) (bool, error) {
+	if fmtWrite {
+		lock, err := envfile.LockFile(path)
+		if err != nil {
+			return false, err
+		}
+		defer lock.Unlock()
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := formatter.Format(src)
+	if err != nil {
+		var pe *envfile.ParseError
+		if errors.As(err, &pe) {
+			pe.File = path
+		}
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	changed := !bytes.Equal(src, formatted)
+
+	if fmtWrite && changed {
+		var opts []envfile.Option
+		if fmtBackup {
+			opts = append(opts, envfile.WithBackup())
+		}
+		if err := envfile.WriteFileAtomic(path, formatted, 0o600, opts...); err != nil {
+			return false, err
+		}
+	}
+	if !fmtList && !fmtWrite {
+		if _, err := os.Stdout.Write(formatted); err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
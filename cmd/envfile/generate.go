@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdGenerate = &command{
+	Name:      "generate",
+	UsageLine: "generate [-package name] [-o file] [example]",
+	Short:     "generate a typed Go config package",
+	Long: `
+Generate reads an example or schema file, .env.example by default, and
+writes Go source for a typed configuration package: a constant for the
+name of every key, a Config struct with a field of the declared type per
+key, and a Load function that loads the .env files and decodes the
+process environment into a Config. Code can then refer to
+config.KeyDatabaseURL and cfg.DatabaseURL instead of calling os.Getenv
+with string literals.
+
+Descriptions become doc comments, defaults become default tags, and
+required keys are tagged as required. Declared types map to Go types:
+int to int64, uint to uint64, float to float64, bool to bool, duration
+to time.Duration, and string and url to string.
+
+The output goes to the file named by -o, or to standard output. The
+package name defaults to the name of the directory containing the
+output file. Generate is meant to run from go:generate:
+
+	//go:generate go run github.com/lucap9056/go-envfile/cmd/envfile generate -o config_gen.go ../.env.example
+`,
+}
+
+var (
+	generatePackage string
+	generateOutput  string
+)
+
+func init() {
+	cmdGenerate.Run = runGenerate
+	cmdGenerate.Flag.StringVar(&generatePackage, "package", "", "package `name` of the generated file")
+	cmdGenerate.Flag.StringVar(&generateOutput, "o", "", "write to `file` instead of standard output")
+}
+
+func runGenerate(cmd *command, args []string) error {
+	if len(args) > 1 {
+		cmd.usage()
+		return exitError(2)
+	}
+	example := ".env.example"
+	if len(args) == 1 {
+		example = args[0]
+	}
+
+	pkg := generatePackage
+	if pkg == "" {
+		dir := "."
+		if generateOutput != "" {
+			dir = filepath.Dir(generateOutput)
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		pkg = packageName(filepath.Base(abs))
+	}
+
+	entries, err := envfile.ReadExample(example)
+	if err != nil {
+		return err
+	}
+	src, err := generateConfig(pkg, filepath.Base(example), entries)
+	if err != nil {
+		return err
+	}
+
+	if generateOutput == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return envfile.WriteFileAtomic(generateOutput, src, 0o644)
+}
+
+// goTypes maps declared types to the Go types of the generated fields.
+var goTypes = map[string]string{
+	"":         "string",
+	"string":   "string",
+	"url":      "string",
+	"int":      "int64",
+	"uint":     "uint64",
+	"float":    "float64",
+	"bool":     "bool",
+	"duration": "time.Duration",
+}
+
+// generateConfig returns the formatted source of the config package for
+// entries, read from the example file named source.
+func generateConfig(pkg, source string, entries []envfile.ExampleEntry) ([]byte, error) {
+	names := make(map[string]string, len(entries))
+	usesTime := false
+	for _, entry := range entries {
+		name := goName(entry.Key)
+		if name == "" {
+			return nil, fmt.Errorf("key '%s' has no Go name", entry.Key)
+		}
+		if other, exists := names[name]; exists {
+			return nil, fmt.Errorf("keys '%s' and '%s' both map to the Go name '%s'", other, entry.Key, name)
+		}
+		names[name] = entry.Key
+		typ, known := goTypes[entry.Type]
+		if !known {
+			return nil, fmt.Errorf("key '%s' has the unknown type '%s'", entry.Key, entry.Type)
+		}
+		if typ == "time.Duration" {
+			usesTime = true
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by envfile generate from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "// Package %s provides typed access to the variables described in %s.\n", pkg, source)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"errors\"\n")
+	if usesTime {
+		b.WriteString("\t\"time\"\n")
+	}
+	b.WriteString("\n\t\"github.com/lucap9056/go-envfile/envfile\"\n)\n\n")
+
+	fmt.Fprintf(&b, "// Names of the variables described in %s.\nconst (\n", source)
+	for _, entry := range entries {
+		writeDoc(&b, entry.Description, "\t")
+		fmt.Fprintf(&b, "\tKey%s = %s\n", goName(entry.Key), strconv.Quote(entry.Key))
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// Config holds the variables described in %s.\ntype Config struct {\n", source)
+	for _, entry := range entries {
+		writeDoc(&b, entry.Description, "\t")
+		tag := "env:" + strconv.Quote(entry.Key)
+		if entry.Required && entry.Default == "" {
+			tag = "env:" + strconv.Quote(entry.Key+",required")
+		}
+		if entry.Default != "" {
+			tag += " default:" + strconv.Quote(entry.Default)
+		}
+		fmt.Fprintf(&b, "\t%s %s %s\n", goName(entry.Key), goTypes[entry.Type], "`"+tag+"`")
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString(`// Load loads the .env files like envfile.Load, tolerating the absence
+// of a file, and decodes the process environment into a Config.
+func Load(opts ...envfile.Option) (*Config, error) {
+	if _, err := envfile.Load(opts...); err != nil && !errors.Is(err, envfile.ErrNoFileLoaded) {
+		return nil, err
+	}
+	var c Config
+	if err := envfile.Unmarshal(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+`)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %v", err)
+	}
+	return src, nil
+}
+
+// writeDoc writes description as a comment, if it is not empty.
+func writeDoc(b *bytes.Buffer, description, indent string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// initialisms are the words written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DB": true, "DNS": true, "DSN": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "JWT": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// goName turns a key such as DATABASE_URL into an exported Go name such
+// as DatabaseURL. It returns an empty string if the key has no letters
+// or digits.
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		lower := []rune(strings.ToLower(word))
+		lower[0] = unicode.ToUpper(lower[0])
+		b.WriteString(string(lower))
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "V" + name
+	}
+	return name
+}
+
+// packageName turns a directory name into a valid package name.
+func packageName(dir string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(dir) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		return "config"
+	}
+	return name
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdGet = &command{
+	Name:         "get",
+	UsageLine:    "get key [files...]",
+	Short:        "print the value of a key",
+	CompleteKeys: true,
+	Long: `
+Get prints the value of key in the given files, later files overriding
+earlier ones, or in the file Load would select when no files are given.
+
+Get exits with status 1, printing nothing, if the key is not set.
+`,
+}
+
+var cmdSet = &command{
+	Name:         "set",
+	UsageLine:    "set [-backup] [-f file] key=value...",
+	Short:        "set keys in a .env file",
+	CompleteKeys: true,
+	Long: `
+Set updates each key in the file (".env" by default), replacing the value
+of its existing definition in place and keeping comments and the rest of
+the file unchanged, or appends a new definition. The file is created if
+it does not exist. The file is replaced atomically, keeping its mode; with
+-backup, its previous content is kept as a timestamped .bak file.
+
+Values containing spaces, '#' or a line break are written double-quoted,
+with backslashes, quotes and line breaks escaped, so that they read back
+unchanged. Set refuses values that cannot be written even quoted, such as
+values containing a {$VAR} reference, and values invalid for the declared
+type of the key.
+`,
+}
+
+var (
+	setFile   string
+	setBackup bool
+)
+
+func init() {
+	cmdGet.Run = runGet
+	cmdSet.Run = runSet
+	cmdSet.Flag.StringVar(&setFile, "f", ".env", "`file` to update")
+	cmdSet.Flag.BoolVar(&setBackup, "backup", false, "keep the previous content as a timestamped .bak file")
+}
+
+func runGet(cmd *command, args []string) error {
+	if len(args) == 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+	env, err := readEnvironment(args[1:])
+	if err != nil {
+		return err
+	}
+	value, exists := env.Lookup(args[0])
+	if !exists {
+		return exitError(1)
+	}
+	fmt.Fprintln(os.Stdout, value)
+	return nil
+}
+
+func runSet(cmd *command, args []string) error {
+	if len(args) == 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+	for _, arg := range args {
+		if !strings.Contains(arg, "=") {
+			cmd.usage()
+			return exitError(2)
+		}
+	}
+	var opts []envfile.Option
+	if setBackup {
+		opts = append(opts, envfile.WithBackup())
+	}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		if err := envfile.Set(setFile, key, value, opts...); err != nil {
+			return err
+		}
+		// Back up the content from before the command only.
+		opts = nil
+	}
+	return nil
+}
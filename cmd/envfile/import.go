@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+	"github.com/lucap9056/go-envfile/envfile/convert"
+)
+
+var cmdImport = &command{
+	Name:      "import",
+	UsageLine: "import [-format format] [-section name] file | import -docker ref",
+	Short:     "convert another configuration format to .env",
+	Long: `
+Import converts a configuration file from another ecosystem and prints
+it as .env content, sorted by key.
+
+The -format flag selects the input format:
+
+	properties      Java .properties
+	rails           Rails secrets.yml or decrypted credentials
+	launchsettings  .NET launchSettings.json
+	docker          docker inspect output of a container or image
+
+When -format is not set, it is chosen from the file name. A file named
+"-" is read from standard input.
+
+The -section flag selects the Rails environment, such as production, the
+launchSettings.json profile, or the container or image of a docker
+inspect output that describes several. Without it, a Rails file is
+converted as a whole and the first launch profile with commandName
+"Project" is used.
+
+The -docker flag asks the Docker daemon for the environment of a
+running container, or of an image, instead of reading a file:
+
+	envfile import -docker web > .env
+`,
+}
+
+var (
+	importFormat  string
+	importSection string
+	importDocker  string
+)
+
+func init() {
+	cmdImport.Run = runImport
+	cmdImport.Flag.StringVar(&importFormat, "format", "", "input format: properties, rails, launchsettings or docker")
+	cmdImport.Flag.StringVar(&importSection, "section", "", "Rails environment, launch profile or docker object to convert")
+	cmdImport.Flag.StringVar(&importDocker, "docker", "", "read the environment of the container or image `ref` from the Docker daemon")
+}
+
+func runImport(cmd *command, args []string) error {
+	if importDocker != "" {
+		if len(args) != 0 {
+			cmd.usage()
+			return exitError(2)
+		}
+		values, err := convert.DockerEnv(context.Background(), importDocker)
+		if err != nil {
+			return err
+		}
+		return writeImported(values)
+	}
+
+	if len(args) != 1 {
+		cmd.usage()
+		return exitError(2)
+	}
+	filePath := args[0]
+
+	format := importFormat
+	if format == "" {
+		format = detectImportFormat(filePath)
+		if format == "" {
+			return fmt.Errorf("cannot tell the format of '%s'; set -format", filePath)
+		}
+	}
+
+	f := os.Stdin
+	if filePath != "-" {
+		var err error
+		if f, err = os.Open(filePath); err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+
+	var values map[string]string
+	var err error
+	switch format {
+	case "properties":
+		values, err = convert.Properties(f)
+	case "rails":
+		values, err = convert.RailsSecrets(f, importSection)
+	case "launchsettings":
+		values, err = convert.LaunchSettings(f, importSection)
+	case "docker":
+		values, err = convert.DockerInspect(f, importSection)
+	default:
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+	if err != nil {
+		return err
+	}
+	return writeImported(values)
+}
+
+// writeImported prints values as .env content.
+func writeImported(values map[string]string) error {
+	content, err := envfile.Marshal(values)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(content)
+	return err
+}
+
+// detectImportFormat chooses an input format from the file name.
+func detectImportFormat(filePath string) string {
+	name := strings.ToLower(filepath.Base(filePath))
+	switch {
+	case name == "launchsettings.json":
+		return "launchsettings"
+	case strings.HasSuffix(name, ".properties"):
+		return "properties"
+	case strings.HasSuffix(name, ".yml"), strings.HasSuffix(name, ".yaml"):
+		return "rails"
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdInit = &command{
+	Name:      "init",
+	UsageLine: "init [-example file] [-o file] [-y]",
+	Short:     "create a .env file from an example",
+	Long: `
+Init reads an example file (".env.example" by default) and prompts for
+the value of each key the output file (".env" by default) does not define
+yet, showing the comment above the key and its example value, which is
+used when the answer is empty. Values of keys with a declared type are
+validated and asked for again if invalid.
+
+The answers are appended to the output file, which is replaced atomically
+or created with mode 0600 if needed, together with the descriptions as comments. With -y, the
+example values are used without prompting.
+`,
+}
+
+var (
+	initExample string
+	initOutput  string
+	initYes     bool
+)
+
+func init() {
+	cmdInit.Run = runInit
+	cmdInit.Flag.StringVar(&initExample, "example", ".env.example", "example `file` listing the expected keys")
+	cmdInit.Flag.StringVar(&initOutput, "o", ".env", "output `file`")
+	cmdInit.Flag.BoolVar(&initYes, "y", false, "use the example values without prompting")
+}
+
+func runInit(cmd *command, args []string) error {
+	if len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+
+	entries, err := envfile.ReadExample(initExample)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(initOutput)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	defined := make(map[string]bool)
+	if len(existing) > 0 {
+		current, err := envfile.ParseExample(bytes.NewReader(existing))
+		if err != nil {
+			return err
+		}
+		for _, entry := range current {
+			defined[entry.Key] = true
+		}
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	var buf bytes.Buffer
+	added := 0
+	for _, entry := range entries {
+		if defined[entry.Key] {
+			continue
+		}
+		value := entry.Default
+		if !initYes {
+			if value, err = prompt(in, entry); err != nil {
+				return err
+			}
+		}
+
+		if buf.Len() > 0 || len(existing) > 0 {
+			buf.WriteByte('\n')
+		}
+		for _, line := range strings.Split(entry.Description, "\n") {
+			if line != "" {
+				fmt.Fprintf(&buf, "# %s\n", line)
+			}
+		}
+		content, err := envfile.Marshal(map[string]string{entry.Key: value})
+		if err != nil {
+			return err
+		}
+		buf.Write(content)
+		added++
+	}
+
+	if added == 0 {
+		fmt.Fprintf(os.Stderr, "%s already defines every key in %s\n", initOutput, initExample)
+		return nil
+	}
+
+	// Append to the file as it is now, in case it changed while prompting.
+	lock, err := envfile.LockFile(initOutput)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	content, err := os.ReadFile(initOutput)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	content = append(content, buf.Bytes()...)
+	if err := envfile.WriteFileAtomic(initOutput, content, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d variables to %s\n", added, initOutput)
+	return nil
+}
+
+// prompt asks for the value of entry until a valid one is given.
+func prompt(in *bufio.Reader, entry envfile.ExampleEntry) (string, error) {
+	if entry.Description != "" {
+		fmt.Fprintf(os.Stderr, "\n# %s\n", strings.ReplaceAll(entry.Description, "\n", "\n# "))
+	}
+	label := entry.Key
+	if entry.Type != "" {
+		label += " (" + entry.Type + ")"
+	}
+	if entry.Default != "" {
+		label += " [" + entry.Default + "]"
+	}
+
+	for {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+		line, err := in.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return "", fmt.Errorf("unexpected end of input while reading %s", entry.Key)
+		} else if err != nil && err != io.EOF {
+			return "", err
+		}
+
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = entry.Default
+		}
+		if entry.Type != "" && value != "" {
+			if err := envfile.CheckType(entry.Type, value); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid value: %v\n", err)
+				continue
+			}
+		}
+		if _, err := envfile.Marshal(map[string]string{entry.Key: value}); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid value: %v\n", err)
+			continue
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdLint = &command{
+	Name:      "lint",
+	UsageLine: "lint [-format text|json|sarif] [-example file] [-rules list] [files...]",
+	Short:     "report problems in .env files",
+	Long: `
+Lint checks each file (".env" by default) for duplicate keys, lowercase
+keys, unquoted values containing spaces, trailing whitespace, whitespace
+around '=', values that look truncated, secrets in files tracked by git,
+and keys missing compared to an example file.
+
+If -example is not given and a .env.example file exists next to a linted
+file, it is used automatically.
+
+Lint exits with status 1 if any issue is reported.
+`,
+}
+
+var (
+	lintFormat  string
+	lintExample string
+	lintRules   string
+)
+
+func init() {
+	cmdLint.Run = runLint
+	cmdLint.Flag.StringVar(&lintFormat, "format", "text", "output `format`: text, json or sarif")
+	cmdLint.Flag.StringVar(&lintExample, "example", "", "example `file` listing the expected keys")
+	cmdLint.Flag.StringVar(&lintRules, "rules", "", "comma-separated `list` of rules to run (default all)")
+}
+
+// fileIssues groups the issues reported for one file.
+type fileIssues struct {
+	File   string          `json:"file"`
+	Issues []envfile.Issue `json:"issues"`
+}
+
+func runLint(cmd *command, args []string) error {
+	if len(args) == 0 {
+		args = []string{".env"}
+	}
+
+	var rules []envfile.Rule
+	if lintRules != "" {
+		for _, name := range strings.Split(lintRules, ",") {
+			rules = append(rules, envfile.Rule(strings.TrimSpace(name)))
+		}
+	}
+
+	var results []fileIssues
+	total := 0
+	for _, path := range args {
+		linter := &envfile.Linter{
+			Rules:   rules,
+			Tracked: isTracked(path),
+		}
+
+		example := lintExample
+		if example == "" {
+			candidate := filepath.Join(filepath.Dir(path), ".env.example")
+			if _, err := os.Stat(candidate); err == nil && filepath.Clean(candidate) != filepath.Clean(path) {
+				example = candidate
+			}
+		}
+		if example != "" {
+			entries, err := envfile.ReadExample(example)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				linter.Example = append(linter.Example, entry.Key)
+			}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		issues, err := linter.Lint(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		total += len(issues)
+		results = append(results, fileIssues{File: path, Issues: issues})
+	}
+
+	var err error
+	switch lintFormat {
+	case "text":
+		err = writeLintText(os.Stdout, results)
+	case "json":
+		err = writeJSON(os.Stdout, results)
+	case "sarif":
+		err = writeLintSARIF(os.Stdout, results)
+	default:
+		return fmt.Errorf("unknown format '%s'", lintFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if total > 0 {
+		return exitError(1)
+	}
+	return nil
+}
+
+// isTracked reports whether path is tracked by git. It returns false if
+// git is not available or the file is outside a repository.
+func isTracked(path string) bool {
+	cmd := exec.Command("git", "ls-files", "--error-unmatch", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	return cmd.Run() == nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeLintText(w io.Writer, results []fileIssues) error {
+	for _, result := range results {
+		for _, issue := range result.Issues {
+			if _, err := fmt.Fprintf(w, "%s:%s\n", result.File, issue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeLintSARIF writes results as a SARIF 2.1.0 log, the format consumed
+// by code scanning tools such as GitHub code scanning.
+func writeLintSARIF(w io.Writer, results []fileIssues) error {
+	type region struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *region `json:"region,omitempty"`
+	}
+	type location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	type message struct {
+		Text string `json:"text"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	type rule struct {
+		ID string `json:"id"`
+	}
+
+	var rules []rule
+	for _, r := range envfile.Rules {
+		rules = append(rules, rule{ID: string(r)})
+	}
+
+	sarifResults := []result{}
+	for _, file := range results {
+		for _, issue := range file.Issues {
+			var loc location
+			loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(file.File)
+			if issue.Line > 0 {
+				loc.PhysicalLocation.Region = &region{StartLine: issue.Line, StartColumn: issue.Column}
+			}
+			sarifResults = append(sarifResults, result{
+				RuleID:    string(issue.Rule),
+				Level:     string(issue.Severity),
+				Message:   message{Text: issue.Message},
+				Locations: []location{loc},
+			})
+		}
+	}
+
+	type driver struct {
+		Name  string `json:"name"`
+		Rules []rule `json:"rules"`
+	}
+	type run struct {
+		Tool struct {
+			Driver driver `json:"driver"`
+		} `json:"tool"`
+		Results []result `json:"results"`
+	}
+
+	var r run
+	r.Tool.Driver = driver{Name: "envfile", Rules: rules}
+	r.Results = sarifResults
+
+	return writeJSON(w, map[string]any{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs":    []run{r},
+	})
+}
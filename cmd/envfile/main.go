@@ -0,0 +1,181 @@
+// Command envfile inspects and manipulates .env files.
+//
+// Usage:
+//
+//	envfile <command> [arguments]
+//
+// Run "envfile help <command>" for more information about a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// command is a single envfile subcommand.
+type command struct {
+	// Name is the word used to invoke the command.
+	Name string
+	// UsageLine is the one-line usage message, without the program name.
+	UsageLine string
+	// Short is a one-line description shown in the command list.
+	Short string
+	// Long is the detailed description shown by "envfile help <command>".
+	Long string
+	// Flag holds the flags specific to this command.
+	Flag flag.FlagSet
+	// CompleteKeys reports whether shell completion offers the keys of
+	// the selected .env file for the arguments.
+	CompleteKeys bool
+	// Run executes the command with the arguments left after flag parsing.
+	Run func(cmd *command, args []string) error
+}
+
+func (c *command) usage() {
+	fmt.Fprintf(os.Stderr, "usage: envfile %s\n", c.UsageLine)
+	if c.Long != "" {
+		fmt.Fprintf(os.Stderr, "\n%s\n", strings.TrimSpace(c.Long))
+	}
+	if hasFlags(&c.Flag) {
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		c.Flag.SetOutput(os.Stderr)
+		c.Flag.PrintDefaults()
+	}
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// exitError asks main to exit with the given status without printing
+// anything further; the command has already reported the problem.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// commands lists every subcommand in the order shown by "envfile help".
+var commands []*command
+
+func init() {
+	commands = []*command{
+		cmdChecksum,
+		cmdCompletion,
+		cmdDump,
+		cmdExport,
+		cmdFmt,
+		cmdGenerate,
+		cmdGet,
+		cmdImport,
+		cmdInit,
+		cmdLint,
+		cmdMan,
+		cmdPatch,
+		cmdPreview,
+		cmdPrint,
+		cmdReport,
+		cmdRun,
+		cmdSet,
+		cmdSign,
+		cmdTUI,
+		cmdVerify,
+	}
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	if name == cmdComplete.Name {
+		return cmdComplete
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "envfile inspects and manipulates .env files.\n\nUsage:\n\n\tenvfile <command> [arguments]\n\nThe commands are:\n\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10s %s\n", cmd.Name, cmd.Short)
+	}
+	fmt.Fprintf(os.Stderr, "\nUse \"envfile help <command>\" for more information about a command.\n")
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("envfile: ")
+
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	if args[0] == "help" {
+		if len(args) < 2 {
+			usage()
+			return
+		}
+		cmd := lookupCommand(args[1])
+		if cmd == nil {
+			fmt.Fprintf(os.Stderr, "envfile help %s: unknown command\n", args[1])
+			os.Exit(2)
+		}
+		cmd.usage()
+		return
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "envfile %s: unknown command\nRun 'envfile help' for usage.\n", args[0])
+		os.Exit(2)
+	}
+
+	cmd.Flag.Init(cmd.Name, flag.ExitOnError)
+	cmd.Flag.Usage = cmd.usage
+	if err := cmd.Flag.Parse(args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	if err := cmd.Run(cmd, cmd.Flag.Args()); err != nil {
+		var exit exitError
+		if errors.As(err, &exit) {
+			os.Exit(int(exit))
+		}
+		fmt.Fprintf(os.Stderr, "envfile %s: %v\n", cmd.Name, err)
+		printExcerpts(err)
+		os.Exit(1)
+	}
+}
+
+// printExcerpts prints the offending lines of the parse errors in err,
+// each under its location, in the FILE:LINE:COLUMN form editors link.
+func printExcerpts(err error) {
+	for _, pe := range envfile.ParseErrors(err) {
+		excerpt := pe.Excerpt()
+		if excerpt == "" {
+			continue
+		}
+		if pe.File != "" {
+			location := fmt.Sprintf("%s:%d", pe.File, pe.Line)
+			if pe.Column > 0 {
+				location += fmt.Sprintf(":%d", pe.Column)
+			}
+			fmt.Fprintf(os.Stderr, "%s:\n", location)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", excerpt)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "github.com/lucap9056/go-envfile/envfile"
+
+var cmdPatch = &command{
+	Name:      "patch",
+	UsageLine: "patch [-backup] base patch",
+	Short:     "apply a patch file onto a .env file",
+	Long: `
+Patch applies the env file patch onto the env file base, in place. Every
+KEY=value line of the patch updates the definition of KEY in base, keeping
+the comments, order and formatting of base, or appends a new definition.
+A "-KEY" line, or an empty "KEY=" line, deletes KEY from base.
+
+Base is replaced atomically, and left unchanged if any line of the patch
+cannot be applied, such as a value invalid for the declared type of the
+key. With -backup, its previous content is kept as a timestamped .bak
+file.
+`,
+}
+
+var patchBackup bool
+
+func init() {
+	cmdPatch.Run = runPatch
+	cmdPatch.Flag.BoolVar(&patchBackup, "backup", false, "keep the previous content as a timestamped .bak file")
+}
+
+func runPatch(cmd *command, args []string) error {
+	if len(args) != 2 {
+		cmd.usage()
+		return exitError(2)
+	}
+	var opts []envfile.Option
+	if patchBackup {
+		opts = append(opts, envfile.WithBackup())
+	}
+	return envfile.ApplyPatch(args[0], args[1], opts...)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdPreview = &command{
+	Name:      "preview",
+	UsageLine: "preview [-conflicts] [-dir dir] [-profile name] [-unsafe]",
+	Short:     "show how the candidate files of a profile combine",
+	Long: `
+Preview parses every candidate file of the profile that exists, in order
+of precedence, and prints each key with its winning value and the file
+it comes from, followed by the value given by every other file defining
+the key:
+
+	DATABASE_URL=postgres://prod/app    .env.production.local
+	    .env.production: postgres://staging/app
+	    .env: postgres://localhost/app
+
+With -conflicts, only keys defined with different values are printed.
+The profile is read from GO_ENV unless -profile is given. Values that
+look like secrets are masked unless -unsafe is set.
+`,
+}
+
+var (
+	previewConflicts bool
+	previewDir       string
+	previewProfile   string
+	previewUnsafe    bool
+)
+
+func init() {
+	cmdPreview.Run = runPreview
+	cmdPreview.Flag.BoolVar(&previewConflicts, "conflicts", false, "print only keys defined with different values")
+	cmdPreview.Flag.StringVar(&previewDir, "dir", "", "search `dir` instead of the current directory")
+	cmdPreview.Flag.StringVar(&previewProfile, "profile", "", "profile `name` to use instead of GO_ENV")
+	cmdPreview.Flag.BoolVar(&previewUnsafe, "unsafe", false, "print secret values instead of masking them")
+}
+
+func runPreview(cmd *command, args []string) error {
+	if len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+
+	opts := []envfile.Option{envfile.WithLogger(nil)}
+	if previewDir != "" {
+		opts = append(opts, envfile.WithDir(previewDir))
+	}
+	cascade, err := envfile.Preview(previewProfile, opts...)
+	if err != nil {
+		return err
+	}
+
+	keys := cascade.Keys
+	if previewConflicts {
+		keys = cascade.Conflicts()
+	}
+	mask := func(key, value string) string {
+		if !previewUnsafe && envfile.IsSecret(key, value) {
+			return envfile.Mask
+		}
+		return value
+	}
+	for _, k := range keys {
+		fmt.Fprintf(os.Stdout, "%s=%s\t%s\n", k.Key, mask(k.Key, k.Value), k.File)
+		for _, d := range k.Definitions[1:] {
+			fmt.Fprintf(os.Stdout, "    %s: %s\n", d.File, mask(k.Key, d.Value))
+		}
+	}
+	return nil
+}
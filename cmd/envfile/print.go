@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdPrint = &command{
+	Name:      "print",
+	UsageLine: "print [-keys patterns] [-sort] [-unsafe] [files...]",
+	Short:     "print the variables of .env files",
+	Long: `
+Print reads the given files, or selects a file the same way Load does
+when none are given, and prints the resulting variables as key=value
+lines in definition order, or sorted by key with -sort. With -keys, only
+the keys matching one of the comma-separated glob patterns are printed.
+
+Values that look like secrets are masked unless -unsafe is set.
+`,
+}
+
+var (
+	printKeys   string
+	printSort   bool
+	printUnsafe bool
+)
+
+func init() {
+	cmdPrint.Run = runPrint
+	cmdPrint.Flag.StringVar(&printKeys, "keys", "", "print only keys matching the comma-separated `patterns`")
+	cmdPrint.Flag.BoolVar(&printSort, "sort", false, "print the keys in sorted order")
+	cmdPrint.Flag.BoolVar(&printUnsafe, "unsafe", false, "print secret values instead of masking them")
+}
+
+func runPrint(cmd *command, args []string) error {
+	var opts []envfile.Option
+	if printKeys != "" {
+		opts = append(opts, envfile.WithKeys(strings.Split(printKeys, ",")...))
+	}
+	env, err := readEnvironment(args, opts...)
+	if err != nil {
+		return err
+	}
+
+	if !printUnsafe {
+		env = env.Masked()
+	}
+
+	keys := env.Keys()
+	if printSort {
+		sort.Strings(keys)
+	}
+	for _, key := range keys {
+		fmt.Fprintf(os.Stdout, "%s=%s\n", key, env.Get(key))
+	}
+	return nil
+}
+
+// readEnvironment reads the given files, or the file selected by
+// envfile.LoadEnvironment when no files are given, with opts.
+func readEnvironment(files []string, opts ...envfile.Option) (*envfile.Environment, error) {
+	if len(files) == 0 {
+		return envfile.LoadEnvironment(opts...)
+	}
+	return envfile.New(opts...).Read(files...)
+}
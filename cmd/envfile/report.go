@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdReport = &command{
+	Name:      "report",
+	UsageLine: "report [-dir dir] [-profile name]",
+	Short:     "print a JSON report of what Load would do",
+	Long: `
+Report loads the .env file Load would select, like a program calling
+envfile.Load with envfile.WithReport, and prints the JSON report: the
+profile, the candidate files and whether each exists and loaded, the
+file chosen, the keys set with secret values masked, the warnings logged
+and the time taken.
+
+The profile is read from GO_ENV unless -profile is given. Report exits
+with status 1 if no file could be loaded, after printing the report.
+`,
+}
+
+var (
+	reportDir     string
+	reportProfile string
+)
+
+func init() {
+	cmdReport.Run = runReport
+	cmdReport.Flag.StringVar(&reportDir, "dir", "", "search `dir` instead of the current directory")
+	cmdReport.Flag.StringVar(&reportProfile, "profile", "", "profile `name` to use instead of GO_ENV")
+}
+
+func runReport(cmd *command, args []string) error {
+	if len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+
+	opts := []envfile.Option{envfile.WithLogger(nil), envfile.WithReport(os.Stdout)}
+	if reportDir != "" {
+		opts = append(opts, envfile.WithDir(reportDir))
+	}
+	if reportProfile != "" {
+		opts = append(opts, envfile.WithProfile(reportProfile))
+	}
+
+	if _, err := envfile.New(opts...).Load(); err != nil {
+		if errors.Is(err, envfile.ErrNoFileLoaded) {
+			return exitError(1)
+		}
+		return err
+	}
+	return nil
+}
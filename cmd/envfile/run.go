@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdRun = &command{
+	Name:      "run",
+	UsageLine: "run [-clean] [-allow patterns] [-f files] [--] command [args...]",
+	Short:     "run a command with the variables of .env files",
+	Long: `
+Run reads the comma-separated files given with -f, or selects a file the
+same way Load does when none are given, and runs the command with the
+resulting variables added to its environment. Variables of the files
+override those of the process.
+
+With -clean, the command does not inherit the environment of envfile:
+it gets only the variables of the files and the process variables
+needed to run at all, such as PATH and HOME, which reproduces the
+environment of a CI job locally. -allow replaces that list with the
+comma-separated glob patterns given.
+
+Run exits with the exit status of the command.
+`,
+}
+
+var (
+	runClean bool
+	runAllow string
+	runFiles string
+)
+
+func init() {
+	cmdRun.Run = runRun
+	cmdRun.Flag.BoolVar(&runClean, "clean", false, "do not inherit the process environment")
+	cmdRun.Flag.StringVar(&runAllow, "allow", "", "with -clean, inherit only keys matching the comma-separated `patterns`")
+	cmdRun.Flag.StringVar(&runFiles, "f", "", "comma-separated `files` to read")
+}
+
+func runRun(cmd *command, args []string) error {
+	if len(args) == 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+
+	var files []string
+	if runFiles != "" {
+		files = strings.Split(runFiles, ",")
+	}
+	env, err := readEnvironment(files)
+	if err != nil {
+		return err
+	}
+
+	child := exec.Command(args[0], args[1:]...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	if runClean {
+		allow := envfile.DefaultCleanAllowlist
+		if runAllow != "" {
+			allow = strings.Split(runAllow, ",")
+		}
+		env.ApplyCleanTo(child, allow...)
+	} else {
+		env.ApplyTo(child)
+	}
+
+	if err := child.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			if code := exit.ExitCode(); code > 0 {
+				return exitError(code)
+			}
+			return exitError(1)
+		}
+		return err
+	}
+	return nil
+}
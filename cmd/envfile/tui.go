@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdTUI = &command{
+	Name:      "tui",
+	UsageLine: "tui [-dir dir] [-profile name]",
+	Short:     "browse and edit the effective configuration",
+	Long: `
+Tui shows the merged environment of the candidate files of a profile in
+the terminal, numbering every key and showing its winning value and the
+file it comes from, and reads commands from standard input:
+
+	/text        show only keys or files containing text; "/" shows all
+	N            show every file defining key number N and its value
+	N=value      set key number N in the file it comes from
+	KEY=value    set KEY in the file it comes from, or in the file of
+	             highest precedence if no file defines it
+	m            toggle the masking of values that look like secrets
+	q            quit
+
+Edits are written with the same in-place update as "envfile set", which
+keeps the comments and the rest of the file unchanged. The profile is
+read from GO_ENV unless -profile is given.
+`,
+}
+
+var (
+	tuiDir     string
+	tuiProfile string
+)
+
+func init() {
+	cmdTUI.Run = runTUI
+	cmdTUI.Flag.StringVar(&tuiDir, "dir", "", "search `dir` instead of the current directory")
+	cmdTUI.Flag.StringVar(&tuiProfile, "profile", "", "profile `name` to use instead of GO_ENV")
+}
+
+func runTUI(cmd *command, args []string) error {
+	if len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+
+	opts := []envfile.Option{envfile.WithLogger(nil)}
+	if tuiDir != "" {
+		opts = append(opts, envfile.WithDir(tuiDir))
+	}
+	b := &browser{
+		loader: envfile.New(opts...),
+		out:    os.Stdout,
+		clear:  isTerminal(os.Stdout),
+		masked: true,
+	}
+	return b.run(bufio.NewReader(os.Stdin))
+}
+
+// isTerminal reports whether f is a character device, in which case the
+// screen is cleared before every redraw.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// browser holds the state of the tui command.
+type browser struct {
+	loader *envfile.Loader
+	out    io.Writer
+	clear  bool
+
+	cascade *envfile.Cascade
+	// visible lists the keys matching filter, numbered from 1 on screen.
+	visible []envfile.CascadeKey
+	filter  string
+	masked  bool
+	// detail is the key whose definitions are shown, if any.
+	detail  string
+	message string
+}
+
+func (b *browser) run(in *bufio.Reader) error {
+	for {
+		if err := b.refresh(); err != nil {
+			return err
+		}
+		b.draw()
+
+		line, err := in.ReadString('\n')
+		if err == io.EOF && line == "" {
+			fmt.Fprintln(b.out)
+			return nil
+		} else if err != nil && err != io.EOF {
+			return err
+		}
+		if quit := b.handle(strings.TrimSpace(line)); quit {
+			return nil
+		}
+	}
+}
+
+// refresh reads the cascade again, so that edits and changes made by
+// other programs are shown.
+func (b *browser) refresh() error {
+	cascade, err := b.loader.Preview(tuiProfile)
+	if err != nil {
+		return err
+	}
+	b.cascade = cascade
+	b.visible = b.visible[:0]
+	filter := strings.ToLower(b.filter)
+	for _, k := range cascade.Keys {
+		if filter == "" || strings.Contains(strings.ToLower(k.Key), filter) || strings.Contains(strings.ToLower(k.File), filter) {
+			b.visible = append(b.visible, k)
+		}
+	}
+	return nil
+}
+
+func (b *browser) draw() {
+	if b.clear {
+		io.WriteString(b.out, "\x1b[H\x1b[2J")
+	}
+	files := "(none)"
+	if len(b.cascade.Files) > 0 {
+		files = strings.Join(b.cascade.Files, ", ")
+	}
+	masking := "on"
+	if !b.masked {
+		masking = "off"
+	}
+	fmt.Fprintf(b.out, "files: %s\nmasking: %s", files, masking)
+	if b.filter != "" {
+		fmt.Fprintf(b.out, "  filter: %s", b.filter)
+	}
+	fmt.Fprintf(b.out, "  keys: %d of %d\n\n", len(b.visible), len(b.cascade.Keys))
+
+	w := tabwriter.NewWriter(b.out, 0, 4, 2, ' ', 0)
+	for i, k := range b.visible {
+		fmt.Fprintf(w, "%d\t%s=%s\t%s\n", i+1, k.Key, b.mask(k.Key, k.Value), k.File)
+	}
+	w.Flush()
+
+	for _, k := range b.cascade.Keys {
+		if k.Key != b.detail {
+			continue
+		}
+		fmt.Fprintf(b.out, "\n%s is defined by:\n", k.Key)
+		for i, d := range k.Definitions {
+			marker := " "
+			if i == 0 {
+				marker = "*"
+			}
+			fmt.Fprintf(b.out, "  %s %s: %s\n", marker, d.File, b.mask(k.Key, d.Value))
+		}
+	}
+
+	if b.message != "" {
+		fmt.Fprintf(b.out, "\n%s\n", b.message)
+		b.message = ""
+	}
+	fmt.Fprint(b.out, "\n/text search, N details, N=value or KEY=value edit, m mask, q quit\n> ")
+}
+
+func (b *browser) mask(key, value string) string {
+	if b.masked && envfile.IsSecret(key, value) {
+		return envfile.Mask
+	}
+	return value
+}
+
+// handle runs the command line and reports whether to quit.
+func (b *browser) handle(line string) bool {
+	switch {
+	case line == "":
+		b.detail = ""
+	case line == "q":
+		return true
+	case line == "m":
+		b.masked = !b.masked
+	case strings.HasPrefix(line, "/"):
+		b.filter = strings.TrimSpace(line[1:])
+	case strings.Contains(line, "="):
+		target, value, _ := strings.Cut(line, "=")
+		b.edit(strings.TrimSpace(target), value)
+	default:
+		if k, ok := b.lookup(line); ok {
+			b.detail = k.Key
+		} else {
+			b.message = fmt.Sprintf("no key number %s", line)
+		}
+	}
+	return false
+}
+
+// lookup returns the visible key numbered n on screen.
+func (b *browser) lookup(n string) (envfile.CascadeKey, bool) {
+	i, err := strconv.Atoi(n)
+	if err != nil || i < 1 || i > len(b.visible) {
+		return envfile.CascadeKey{}, false
+	}
+	return b.visible[i-1], true
+}
+
+// edit sets the key named or numbered by target to value, in the file the
+// key comes from.
+func (b *browser) edit(target, value string) {
+	key, file := target, ""
+	if k, ok := b.lookup(target); ok {
+		key, file = k.Key, k.File
+	} else {
+		for _, k := range b.cascade.Keys {
+			if k.Key == target {
+				file = k.File
+			}
+		}
+	}
+	if file == "" {
+		if len(b.cascade.Files) > 0 {
+			file = b.cascade.Files[0]
+		} else {
+			file = filepath.Join(tuiDir, ".env")
+		}
+	}
+
+	if err := envfile.Set(file, key, value); err != nil {
+		b.message = err.Error()
+		return
+	}
+	b.detail = key
+	b.message = fmt.Sprintf("set %s in %s", key, file)
+}
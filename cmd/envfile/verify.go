@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var cmdVerify = &command{
+	Name:      "verify",
+	UsageLine: "verify -schema file [-against file] [-allow-unknown] [-format text|json]",
+	Short:     "check .env files against a schema",
+	Long: `
+Verify reads the schema file, written like a .env.example file, and checks
+the file given by -against, or the file Load would select, for required
+keys that are missing, values invalid for their declared types, and keys
+the schema does not list.
+
+A key is required if the schema gives it no example value, unless it is
+annotated with "# @optional KEY"; "# @required KEY" makes a key with a
+value required. Types are declared with KEY:type=value or
+"# @type KEY type".
+
+Verify exits with status 1 if any issue is reported. With -format json,
+the issues are printed in the same format as lint, for CI annotations.
+`,
+}
+
+var (
+	verifySchema       string
+	verifyAgainst      string
+	verifyAllowUnknown bool
+	verifyFormat       string
+)
+
+func init() {
+	cmdVerify.Run = runVerify
+	cmdVerify.Flag.StringVar(&verifySchema, "schema", "", "schema `file`")
+	cmdVerify.Flag.StringVar(&verifyAgainst, "against", "", "`file` to check (default the file Load selects)")
+	cmdVerify.Flag.BoolVar(&verifyAllowUnknown, "allow-unknown", false, "accept keys the schema does not list")
+	cmdVerify.Flag.StringVar(&verifyFormat, "format", "text", "output `format`: text or json")
+}
+
+func runVerify(cmd *command, args []string) error {
+	if verifySchema == "" || len(args) != 0 {
+		cmd.usage()
+		return exitError(2)
+	}
+	if verifyFormat != "text" && verifyFormat != "json" {
+		return fmt.Errorf("unknown format '%s'", verifyFormat)
+	}
+
+	schema, err := envfile.ReadSchema(verifySchema)
+	if err != nil {
+		return err
+	}
+	schema.AllowUnknown = verifyAllowUnknown
+
+	var files []string
+	if verifyAgainst != "" {
+		files = []string{verifyAgainst}
+	}
+	env, err := readEnvironment(files)
+	if err != nil {
+		return err
+	}
+
+	name := verifyAgainst
+	if name == "" {
+		name = "environment"
+	}
+	result := fileIssues{File: name, Issues: schema.Validate(env)}
+
+	if verifyFormat == "json" {
+		err = writeJSON(os.Stdout, []fileIssues{result})
+	} else {
+		err = writeVerifyText(os.Stdout, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(result.Issues) > 0 {
+		return exitError(1)
+	}
+	return nil
+}
+
+func writeVerifyText(w io.Writer, result fileIssues) error {
+	for _, issue := range result.Issues {
+		if _, err := fmt.Fprintf(w, "%s: %s: %s (%s)\n", result.File, issue.Severity, issue.Message, issue.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
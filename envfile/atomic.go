@@ -0,0 +1,118 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupTimeFormat is the layout of the timestamp in the names of backup
+// files, which sorts in the order the backups were made.
+const backupTimeFormat = "20060102-150405.000"
+
+// WithBackup makes the functions that rewrite an existing file, such as
+// Set, ApplyPatch, WriteChecksum and DumpEffective, keep its previous
+// content as FILE.TIMESTAMP.bak next to it, such as
+// .env.20240501-142233.517.bak.
+func WithBackup() Option {
+	return func(o *options) {
+		o.backup = true
+	}
+}
+
+// WriteFileAtomic writes data to the file at filePath without ever
+// leaving a partly written file behind: data is written and synced to a
+// temporary file in the same directory, which is then renamed over
+// filePath. A crash leaves either the old or the new content. An existing
+// file keeps its mode, and a symbolic link is followed so that the file
+// it points to is replaced; a new file is created with perm. With
+// WithBackup, the previous content of an existing file is kept as a
+// timestamped .bak file.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode, opts ...Option) error {
+	return writeFileAtomic(filePath, data, perm, newOptions(opts).backup)
+}
+
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode, backup bool) error {
+	if target, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = target
+	}
+	info, err := os.Stat(filePath)
+	exists := err == nil
+	switch {
+	case exists:
+		perm = info.Mode().Perm()
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("error: unable to stat file '%s': %w: %w", filePath, err, ErrIO)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error: unable to write file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error: unable to write file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fail(err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fail(err)
+	}
+
+	if exists && backup {
+		if err := backupFile(filePath); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error: unable to write file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	return nil
+}
+
+// backupFile keeps the content of the file at filePath as a timestamped
+// .bak file. The backup is a hard link when possible, which is itself
+// atomic, and a copy otherwise.
+func backupFile(filePath string) error {
+	backup := filePath + "." + time.Now().Format(backupTimeFormat) + ".bak"
+	if err := os.Link(filePath, backup); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("error: unable to back up file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("error: unable to back up file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	dst, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("error: unable to back up file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(backup)
+		return fmt.Errorf("error: unable to back up file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(backup)
+		return fmt.Errorf("error: unable to back up file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	return nil
+}
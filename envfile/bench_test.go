@@ -0,0 +1,82 @@
+package envfile_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// benchContent returns env file content with n variables, half of them
+// referencing a template variable, like the large generated files loaded
+// in serverless cold starts.
+func benchContent(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString("$REGION=eu-west-1\n")
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "# comment %d\nKEY_%d=value-%d\n", i, i, i)
+		} else {
+			fmt.Fprintf(&b, "URL_%d=https://svc-%d.{$REGION}.example.com\n", i, i)
+		}
+	}
+	return b.Bytes()
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	for _, n := range []int{10, 1000} {
+		data := benchContent(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := envfile.ParseBytes(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	data := benchContent(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := envfile.Parse(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	data := benchContent(1000)
+	path := filepath.Join(b.TempDir(), ".env")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		envfile.DisableCache()
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := envfile.Read(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		envfile.EnableCache()
+		defer envfile.DisableCache()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := envfile.Read(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
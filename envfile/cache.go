@@ -0,0 +1,184 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheKey identifies a cached parse: the same file parsed with different
+// options is cached separately.
+type cacheKey struct {
+	path    string
+	options parseOptions
+}
+
+// fileStamp records the metadata of a file at the time it was parsed.
+type fileStamp struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func stampFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{path: path, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// current reports whether the file still has the recorded metadata.
+func (s fileStamp) current() bool {
+	now, err := stampFile(s.path)
+	return err == nil && now.modTime.Equal(s.modTime) && now.size == s.size
+}
+
+// cacheEntry holds the parsed variables of a file together with the
+// metadata of the file and of every file it included, as it was when the
+// file was parsed.
+type cacheEntry struct {
+	files     []fileStamp
+	envReads  map[string]string
+	variables []variable
+}
+
+// current reports whether the cached files are unchanged and every
+// process variable consulted by conditional sections still has the same
+// value.
+func (e cacheEntry) current() bool {
+	for _, stamp := range e.files {
+		if !stamp.current() {
+			return false
+		}
+	}
+	for key, value := range e.envReads {
+		if os.Getenv(key) != value {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	cacheMu      sync.Mutex
+	cacheEnabled bool
+	cache        = make(map[cacheKey]cacheEntry)
+)
+
+// EnableCache turns on the in-memory cache of parsed env files. While the
+// cache is enabled, repeated loads of a file whose modification time and
+// size have not changed are served from memory instead of being parsed
+// again. The cache is disabled by default.
+func EnableCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheEnabled = true
+}
+
+// DisableCache turns off the parsed file cache and discards every cached
+// entry.
+func DisableCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheEnabled = false
+	cache = make(map[cacheKey]cacheEntry)
+}
+
+// Invalidate removes the cached entry for the given file, forcing the
+// next load to parse it again.
+func Invalidate(filePath string) {
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		path = filePath
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	for key := range cache {
+		if key.path == path {
+			delete(cache, key)
+		}
+	}
+}
+
+// InvalidateAll removes every cached entry while leaving the cache
+// enabled.
+func InvalidateAll() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache = make(map[cacheKey]cacheEntry)
+}
+
+// parseFileCached behaves like parseFile, but consults the cache first
+// when it is enabled and neither templates, decoders nor an event handler
+// are in use. A cached entry is only used if the modification time and
+// size of the file, and of every file it includes, still match. Expiries
+// are checked on every call.
+func parseFileCached(filePath string, po parseOptions) ([]variable, error) {
+	variables, err := cachedParse(filePath, po)
+	if err != nil {
+		return nil, err
+	}
+	return variables, checkExpired(filePath, variables, po)
+}
+
+// cachedParse returns the cached variables of a file or parses it.
+func cachedParse(filePath string, po parseOptions) ([]variable, error) {
+	cacheMu.Lock()
+	enabled := cacheEnabled
+	cacheMu.Unlock()
+
+	if !enabled || po.template != nil || po.decoders != nil || po.events != nil {
+		return parseFile(filePath, po)
+	}
+
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		path = filePath
+	}
+	key := cacheKey{path: path, options: po.cacheKey()}
+
+	stamp, err := stampFile(path)
+	if err != nil {
+		return parseFile(filePath, po)
+	}
+
+	cacheMu.Lock()
+	entry, exists := cache[key]
+	cacheMu.Unlock()
+
+	if exists && entry.current() {
+		return entry.variables, nil
+	}
+
+	variables, p, err := parseFileIncludes(filePath, po)
+	if err != nil {
+		return nil, err
+	}
+	if p.volatile {
+		return variables, nil
+	}
+
+	files := []fileStamp{stamp}
+	for _, include := range p.includes {
+		includeStamp, err := stampFile(include)
+		if err != nil {
+			return variables, nil
+		}
+		files = append(files, includeStamp)
+	}
+
+	cacheMu.Lock()
+	if cacheEnabled {
+		cache[key] = cacheEntry{
+			files:     files,
+			envReads:  p.envReads,
+			variables: variables,
+		}
+	}
+	cacheMu.Unlock()
+
+	return variables, nil
+}
@@ -0,0 +1,93 @@
+package envfile
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// CaseSensitivity controls whether keys that differ only in case are
+// treated as the same variable.
+type CaseSensitivity int
+
+const (
+	// CaseAuto follows the platform: keys are case-insensitive on Windows,
+	// where the process environment is, and case-sensitive elsewhere. It
+	// is the default.
+	CaseAuto CaseSensitivity = iota
+	// CaseSensitive treats keys that differ in case as distinct variables.
+	CaseSensitive
+	// CaseInsensitive treats keys that differ only in case as the same
+	// variable.
+	CaseInsensitive
+)
+
+// WithCaseSensitivity controls whether keys that differ only in case, such
+// as Path and PATH, are the same variable. When keys are case-insensitive,
+// every occurrence of a key takes the spelling of its first occurrence
+// and the last value wins, just like a key defined twice with the same
+// spelling. Environment.Lookup and Get then match keys in any case, and
+// WithOverride(false) and merge strategies find process variables spelled
+// differently; if several process variables match, the one spelled
+// exactly like the key is used, or else the first in sorted order.
+func WithCaseSensitivity(sensitivity CaseSensitivity) Option {
+	return func(o *options) {
+		o.caseSensitivity = sensitivity
+	}
+}
+
+func (o *options) foldCase() bool {
+	switch o.caseSensitivity {
+	case CaseSensitive:
+		return false
+	case CaseInsensitive:
+		return true
+	default:
+		return runtime.GOOS == "windows"
+	}
+}
+
+// foldKeys returns variables with every key respelled like the first key
+// that matches it case-insensitively. The input slice is not modified, as
+// it may be shared with the parse cache.
+func foldKeys(variables []variable) []variable {
+	spelling := make(map[string]string, len(variables))
+	folded := make([]variable, len(variables))
+	for i, v := range variables {
+		upper := strings.ToUpper(v.key)
+		if first, exists := spelling[upper]; exists {
+			v.key = first
+		} else {
+			spelling[upper] = v.key
+		}
+		folded[i] = v
+	}
+	return folded
+}
+
+// foldIndex maps the upper-cased spelling of each key to the key.
+func foldIndex(keys []string) map[string]string {
+	index := make(map[string]string, len(keys))
+	for _, key := range keys {
+		index[strings.ToUpper(key)] = key
+	}
+	return index
+}
+
+// lookupEnv is like os.LookupEnv, but matches key case-insensitively if
+// fold is set, preferring the exact spelling and then the first matching
+// spelling in sorted order.
+func lookupEnv(key string, fold bool) (string, bool) {
+	if value, exists := os.LookupEnv(key); exists || !fold {
+		return value, exists
+	}
+	var match, value string
+	found := false
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, value, found = k, v, true
+		}
+	}
+	return value, found
+}
@@ -0,0 +1,168 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// condition is one level of an #if ... #endif block.
+type condition struct {
+	// active reports whether lines in the current branch are used.
+	active bool
+	// taken reports whether any branch of the block has been active.
+	taken bool
+	// parentActive reports whether the enclosing block is active.
+	parentActive bool
+	// line is the line number of the #if directive.
+	line int
+}
+
+// active reports whether the current line is outside every conditional
+// block or inside only active branches.
+func (p *parser) active() bool {
+	return len(p.conditions) == 0 || p.conditions[len(p.conditions)-1].active
+}
+
+// conditionalDirective returns the directive of line and its condition
+// if line is an #if, #elif, #else or #endif directive. A line starting
+// with "#if" is only a directive if a valid condition follows, so that a
+// comment such as "#if you need the proxy, set it below" stays a
+// comment.
+func conditionalDirective(line string) (directive, expr string, ok bool) {
+	directive, expr, _ = strings.Cut(strings.TrimSpace(line), " ")
+	expr = strings.TrimSpace(expr)
+	switch directive {
+	case "#if":
+		return directive, expr, validCondition(expr)
+	case "#elif", "#else", "#endif":
+		return directive, expr, true
+	}
+	return "", "", false
+}
+
+// validCondition reports whether expr has one of the forms evaluate
+// supports, with a key made of letters, digits and underscores.
+func validCondition(expr string) bool {
+	key := expr
+	if k, _, found := strings.Cut(expr, "="); found {
+		key = strings.TrimSuffix(k, "!")
+	} else {
+		key = strings.TrimPrefix(key, "!")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// conditional handles #if, #elif, #else and #endif directives. It reports
+// whether line was such a directive.
+func (p *parser) conditional(line string) (bool, error) {
+	directive, expr, ok := conditionalDirective(line)
+	if !ok {
+		return false, nil
+	}
+
+	switch directive {
+	case "#if":
+		parentActive := p.active()
+		active := parentActive && p.evaluate(expr)
+		p.conditions = append(p.conditions, condition{
+			active:       active,
+			taken:        active,
+			parentActive: parentActive,
+			line:         p.lineNumber,
+		})
+	case "#elif":
+		c, err := p.currentCondition(directive)
+		if err != nil {
+			return true, err
+		}
+		if expr == "" {
+			return true, fmt.Errorf("error: '%s' at line %d: #elif requires a condition: %w", p.source, p.lineNumber, ErrSyntax)
+		}
+		c.active = c.parentActive && !c.taken && p.evaluate(expr)
+		c.taken = c.taken || c.active
+	case "#else":
+		c, err := p.currentCondition(directive)
+		if err != nil {
+			return true, err
+		}
+		c.active = c.parentActive && !c.taken
+		c.taken = true
+	case "#endif":
+		if _, err := p.currentCondition(directive); err != nil {
+			return true, err
+		}
+		p.conditions = p.conditions[:len(p.conditions)-1]
+	}
+	return true, nil
+}
+
+func (p *parser) currentCondition(directive string) (*condition, error) {
+	if len(p.conditions) == 0 {
+		return nil, fmt.Errorf("error: '%s' at line %d: %s without #if: %w", p.source, p.lineNumber, directive, ErrSyntax)
+	}
+	return &p.conditions[len(p.conditions)-1], nil
+}
+
+// evaluate evaluates a condition against the process environment. The
+// supported forms are KEY=value, KEY!=value, KEY (set and not empty) and
+// !KEY (unset or empty).
+func (p *parser) evaluate(expr string) bool {
+	if key, value, found := strings.Cut(expr, "!="); found {
+		return p.getenv(strings.TrimSpace(key)) != strings.TrimSpace(value)
+	}
+	if key, value, found := strings.Cut(expr, "="); found {
+		return p.getenv(strings.TrimSpace(key)) == strings.TrimSpace(value)
+	}
+	if strings.HasPrefix(expr, "!") {
+		return p.getenv(strings.TrimSpace(expr[1:])) == ""
+	}
+	return p.getenv(expr) != ""
+}
+
+// getenv reads a process variable and records its value, so that a cached
+// parse can be discarded when a condition would evaluate differently.
+func (p *parser) getenv(key string) string {
+	value := os.Getenv(key)
+	if p.envReads == nil {
+		p.envReads = make(map[string]string)
+	}
+	p.envReads[key] = value
+	return value
+}
+
+// profile returns the profile selected by WithProfile or GO_ENV among
+// the configured profiles, resolving aliases and falling back to
+// "development" like Load does.
+func (p *parser) profile() string {
+	env := p.options.profile
+	if env == "" {
+		env = p.getenv("GO_ENV")
+	}
+	if profile, known := p.options.resolveProfile(env, p.options.profileMap()); known {
+		return profile
+	}
+	return "development"
+}
+
+// splitProfilePrefix splits a line written as "@profile KEY=value" into
+// the profile and the rest of the line.
+func splitProfilePrefix(line string) (profile, rest string, ok bool) {
+	if !strings.HasPrefix(line, "@") {
+		return "", line, false
+	}
+	profile, rest, found := strings.Cut(line[1:], " ")
+	if !found || profile == "" {
+		return "", line, false
+	}
+	return profile, strings.TrimSpace(rest), true
+}
@@ -0,0 +1,92 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// WithConfigDirs makes Load also search the configuration directories of
+// the application app, as returned by ConfigDirs, after the working
+// directory or the directory set with WithDir, so that command-line tools
+// follow the conventions of the platform:
+//
+//	// ./.env, then ~/.config/mytool/.env, then /etc/mytool/.env
+//	result, err := envfile.Load(envfile.WithConfigDirs("mytool"))
+//
+// Every candidate file of the first directory takes precedence over the
+// files of the next one. Directories that do not exist are skipped. A
+// file marked with WithRequiredFiles may be found in any of them.
+func WithConfigDirs(app string) Option {
+	return func(o *options) {
+		o.app = app
+	}
+}
+
+// ConfigDirs returns the configuration directories of the application
+// app in order of precedence, following the XDG Base Directory
+// specification: $XDG_CONFIG_HOME/app, defaulting to ~/.config/app, then
+// app in each directory of $XDG_CONFIG_DIRS, defaulting to /etc/xdg, and
+// finally /etc/app. On Windows, it returns app in the directory of
+// os.UserConfigDir, %AppData%, unless XDG_CONFIG_HOME is set, and in
+// %ProgramData%.
+func ConfigDirs(app string) []string {
+	var dirs []string
+	if home := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(home) {
+		dirs = append(dirs, filepath.Join(home, app))
+	} else if runtime.GOOS == "windows" {
+		if config, err := os.UserConfigDir(); err == nil {
+			dirs = append(dirs, filepath.Join(config, app))
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", app))
+	}
+
+	if runtime.GOOS == "windows" {
+		if data := os.Getenv("ProgramData"); data != "" {
+			dirs = append(dirs, filepath.Join(data, app))
+		}
+		return dirs
+	}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range strings.Split(configDirs, string(os.PathListSeparator)) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, filepath.Join(dir, app))
+		}
+	}
+	return append(dirs, filepath.Join("/etc", app))
+}
+
+// searchDirs returns dir followed by the configuration directories set
+// with WithConfigDirs that exist.
+func (l *Loader) searchDirs(dir string) []string {
+	dirs := []string{dir}
+	if l.o.app == "" {
+		return dirs
+	}
+	for _, configDir := range ConfigDirs(l.o.app) {
+		if info, err := os.Stat(configDir); err == nil && info.IsDir() && configDir != dir {
+			dirs = append(dirs, configDir)
+		}
+	}
+	return dirs
+}
+
+// search returns the paths of the files named by names in dir and then
+// in the configuration directories set with WithConfigDirs, like
+// existing.
+func (l *Loader) search(dir string, names []string) ([]string, error) {
+	var paths []string
+	for _, d := range l.searchDirs(dir) {
+		found, err := l.existing(d, names)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
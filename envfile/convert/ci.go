@@ -0,0 +1,80 @@
+package convert
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// WriteGitHubEnv writes env to w in the format of the GitHub Actions
+// GITHUB_ENV file, so that later steps of a job see the variables:
+//
+//	envfile export -format github .env >> "$GITHUB_ENV"
+//
+// Single-line values are written as KEY=value. Multi-line values use the
+// KEY<<DELIMITER syntax with a random delimiter that does not occur in
+// the value.
+func WriteGitHubEnv(w io.Writer, env *envfile.Environment) error {
+	bw := bufio.NewWriter(w)
+	for _, key := range env.Keys() {
+		value := env.Get(key)
+		if key == "" || strings.ContainsAny(key, "=\r\n") || strings.Contains(key, "<<") {
+			return fmt.Errorf("error: key '%s' cannot be written to GITHUB_ENV", key)
+		}
+
+		if !strings.ContainsAny(value, "\r\n") {
+			fmt.Fprintf(bw, "%s=%s\n", key, value)
+			continue
+		}
+
+		delimiter, err := heredocDelimiter(value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+	}
+	return bw.Flush()
+}
+
+// heredocDelimiter returns a random delimiter that does not appear in
+// value.
+func heredocDelimiter(value string) (string, error) {
+	for {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("error: unable to generate delimiter: %v", err)
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(b)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+var gitLabKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WriteGitLabDotenv writes env to w in the format of a GitLab CI dotenv
+// report artifact (artifacts:reports:dotenv), so that later jobs see the
+// variables. GitLab only accepts keys made of letters, digits and
+// underscores and does not support multi-line values, so such variables
+// are rejected with an error rather than silently dropped by GitLab.
+func WriteGitLabDotenv(w io.Writer, env *envfile.Environment) error {
+	bw := bufio.NewWriter(w)
+	for _, key := range env.Keys() {
+		value := env.Get(key)
+		if !gitLabKeyRegex.MatchString(key) {
+			return fmt.Errorf("error: key '%s' cannot be written to a GitLab dotenv report", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("error: value of '%s' cannot be written to a GitLab dotenv report: it contains a line break", key)
+		}
+		fmt.Fprintf(bw, "%s=%s\n", key, value)
+	}
+	return bw.Flush()
+}
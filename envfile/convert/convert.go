@@ -0,0 +1,104 @@
+// Package convert translates configuration from other ecosystems into
+// environment variables, so that mixed-language teams can keep a single
+// source of truth and generate .env files from it.
+//
+// Each importer returns a map of variables, which envfile.Marshal turns
+// into env file content:
+//
+//	values, err := convert.Properties(f)
+//	content, err := envfile.Marshal(values)
+//
+// The .properties and TOML readers can also serve as front-ends of the
+// Loader, which then reads such files like .env files:
+//
+//	convert.RegisterFormats()
+//	env, err := envfile.Read("config.toml", ".env")
+package convert
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// EnvKey turns a configuration key such as "db.host" or "max-pool-size"
+// into an environment variable name such as DB_HOST or MAX_POOL_SIZE:
+// letters are upper-cased and every run of other characters that are not
+// letters or digits becomes a single underscore.
+func EnvKey(key string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range key {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pending = false
+			b.WriteRune(unicode.ToUpper(r))
+			continue
+		}
+		pending = true
+	}
+	return b.String()
+}
+
+// RegisterFormats registers the .properties and .toml formats of this
+// package with envfile.RegisterFormat, so that the Loader reads such
+// files directly.
+func RegisterFormats() {
+	envfile.RegisterFormat(".properties", PropertiesEntries)
+	envfile.RegisterFormat(".toml", TOMLEntries)
+}
+
+// entryMap returns the values of entries by key, the last value of a
+// repeated key winning.
+func entryMap(entries []envfile.Entry) map[string]string {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Key] = entry.Value
+	}
+	return values
+}
+
+// flatten adds the scalars below node to values, naming each one after
+// its path joined by underscores. Sequences of scalars become
+// comma-separated values; other sequences are flattened by index.
+func flatten(values map[string]string, prefix string, node *yamlNode) {
+	join := func(key string) string {
+		if prefix == "" {
+			return EnvKey(key)
+		}
+		return prefix + "_" + EnvKey(key)
+	}
+
+	switch node.kind {
+	case yamlScalar:
+		if prefix != "" {
+			values[prefix] = node.value
+		}
+	case yamlMapping:
+		for _, key := range node.keys {
+			flatten(values, join(key), node.fields[key])
+		}
+	case yamlSequence:
+		scalars := make([]string, 0, len(node.items))
+		for _, item := range node.items {
+			if item.kind != yamlScalar {
+				scalars = nil
+				break
+			}
+			scalars = append(scalars, item.value)
+		}
+		if scalars != nil || len(node.items) == 0 {
+			if prefix != "" {
+				values[prefix] = strings.Join(scalars, ",")
+			}
+			return
+		}
+		for i, item := range node.items {
+			flatten(values, join(strconv.Itoa(i)), item)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// dockerObject is the part of a container or image, as returned by
+// docker inspect and the Docker Engine API, that holds its environment.
+type dockerObject struct {
+	ID       string   `json:"Id"`
+	Name     string   `json:"Name"`
+	RepoTags []string `json:"RepoTags"`
+	Config   struct {
+		Env []string `json:"Env"`
+	} `json:"Config"`
+}
+
+// matches reports whether ref names the object, by name, tag or a
+// prefix of its ID.
+func (o dockerObject) matches(ref string) bool {
+	if o.Name == ref || strings.TrimPrefix(o.Name, "/") == ref {
+		return true
+	}
+	for _, tag := range o.RepoTags {
+		if tag == ref {
+			return true
+		}
+	}
+	id := strings.TrimPrefix(o.ID, "sha256:")
+	ref = strings.TrimPrefix(ref, "sha256:")
+	return ref != "" && strings.HasPrefix(id, ref)
+}
+
+// env returns the environment of the object.
+func (o dockerObject) env() map[string]string {
+	values := make(map[string]string, len(o.Config.Env))
+	for _, entry := range o.Config.Env {
+		// Docker passes entries without '=' through from the client
+		// environment, so they have no value of their own.
+		if key, value, found := strings.Cut(entry, "="); found && key != "" {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// DockerInspect reads the output of docker inspect, for a container or an
+// image, and returns the environment from its Config.Env, so that the
+// configuration of a container can be reproduced locally:
+//
+//	docker inspect web | envfile import -format docker -
+//
+// The output may be the JSON array printed by docker inspect or a single
+// object as returned by the Docker Engine API. If it describes several
+// objects, ref selects one by container name, image tag or ID prefix; an
+// empty ref requires exactly one object.
+func DockerInspect(r io.Reader, ref string) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read docker inspect output: %v", err)
+	}
+
+	var objects []dockerObject
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var object dockerObject
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, fmt.Errorf("error: unable to decode docker inspect output: %v", err)
+		}
+		objects = []dockerObject{object}
+	} else if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, fmt.Errorf("error: unable to decode docker inspect output: %v", err)
+	}
+
+	if ref == "" {
+		if len(objects) != 1 {
+			return nil, fmt.Errorf("error: docker inspect output describes %d objects; select one", len(objects))
+		}
+		return objects[0].env(), nil
+	}
+	for _, object := range objects {
+		if object.matches(ref) {
+			return object.env(), nil
+		}
+	}
+	return nil, fmt.Errorf("error: docker inspect output has no object '%s'", ref)
+}
+
+// DockerEnv asks the Docker daemon for the environment of the container
+// ref, or of the image ref if no such container exists. The daemon is
+// reached through the unix socket named by DOCKER_HOST, or
+// /var/run/docker.sock; TCP hosts are not supported, since they usually
+// require TLS client certificates.
+func DockerEnv(ctx context.Context, ref string) (map[string]string, error) {
+	socket := "/var/run/docker.sock"
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		path, found := strings.CutPrefix(host, "unix://")
+		if !found {
+			return nil, fmt.Errorf("error: DOCKER_HOST '%s' is not a unix socket", host)
+		}
+		socket = path
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	for _, kind := range []string{"containers", "images"} {
+		values, found, err := dockerGet(ctx, client, "http://docker/"+kind+"/"+url.PathEscape(ref)+"/json")
+		if err != nil || found {
+			return values, err
+		}
+	}
+	return nil, fmt.Errorf("error: no container or image '%s'", ref)
+}
+
+// dockerGet fetches a container or image from the Docker Engine API. It
+// reports false if the daemon does not know the object.
+func dockerGet(ctx context.Context, client *http.Client, u string) (map[string]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("error: unable to reach the Docker daemon: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("error: Docker daemon returned %s for '%s'", resp.Status, req.URL.Path)
+	}
+	values, err := DockerInspect(resp.Body, "")
+	return values, err == nil, err
+}
@@ -0,0 +1,85 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// ECSSecrets selects the variables that WriteECS emits as secrets
+// entries, which ECS resolves from SSM Parameter Store or Secrets Manager
+// when the task starts, instead of as plain environment entries.
+type ECSSecrets struct {
+	// ValueFrom is the ARN prefix to which the key is appended to form
+	// the valueFrom of a secret, such as
+	// "arn:aws:ssm:eu-west-1:123456789012:parameter/app/".
+	ValueFrom string
+	// Detector flags the keys to emit as secrets. It defaults to
+	// envfile.DefaultDetector.
+	Detector *envfile.Detector
+	// Keys lists keys that are always emitted as secrets.
+	Keys []string
+}
+
+// secret reports whether the variable is emitted as a secret.
+func (s *ECSSecrets) secret(key, value string) bool {
+	for _, k := range s.Keys {
+		if k == key {
+			return true
+		}
+	}
+	detector := s.Detector
+	if detector == nil {
+		detector = envfile.DefaultDetector
+	}
+	return detector.IsSecret(key, value)
+}
+
+// ecsEnvironment is the JSON written by WriteECS.
+type ecsEnvironment struct {
+	Environment []ecsVariable `json:"environment"`
+	Secrets     []ecsSecret   `json:"secrets,omitempty"`
+}
+
+type ecsVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ecsSecret struct {
+	Name      string `json:"name"`
+	ValueFrom string `json:"valueFrom"`
+}
+
+// WriteECS writes env to w as the environment of an ECS container
+// definition, in definition order:
+//
+//	{
+//	  "environment": [{"name": "PORT", "value": "8080"}],
+//	  "secrets": [{"name": "DB_PASSWORD", "valueFrom": "arn:aws:ssm:...:parameter/app/DB_PASSWORD"}]
+//	}
+//
+// The environment array has the same shape in Lambda function
+// configuration templates. If secrets is nil, every variable is written
+// to the environment array; otherwise the variables it flags are written
+// as secrets, without their values.
+func WriteECS(w io.Writer, env *envfile.Environment, secrets *ECSSecrets) error {
+	out := ecsEnvironment{Environment: []ecsVariable{}}
+	for _, e := range env.Entries() {
+		if e.Key == "" || strings.ContainsRune(e.Key, '=') {
+			return fmt.Errorf("error: key '%s' cannot be written to an ECS environment", e.Key)
+		}
+		if secrets != nil && secrets.secret(e.Key, e.Value) {
+			out.Secrets = append(out.Secrets, ecsSecret{Name: e.Key, ValueFrom: secrets.ValueFrom + e.Key})
+			continue
+		}
+		out.Environment = append(out.Environment, ecsVariable{Name: e.Key, Value: e.Value})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,146 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+var tfvarsNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// WriteTFVars writes env to w as a Terraform terraform.tfvars file, so
+// that infrastructure code can consume the same variables:
+//
+//	db_host = "localhost"
+//	db_port = "5432"
+//
+// Keys are lower-cased, following the Terraform naming convention, and a
+// TF_VAR_ prefix is removed. Every value is written as a string, with
+// "${" and "%{" escaped so that Terraform does not interpolate them. Keys
+// that are not valid Terraform identifiers are rejected with an error.
+func WriteTFVars(w io.Writer, env *envfile.Environment) error {
+	bw := bufio.NewWriter(w)
+	for _, key := range env.Keys() {
+		name, _ := strings.CutPrefix(key, "TF_VAR_")
+		name = strings.ToLower(name)
+		if !tfvarsNameRegex.MatchString(name) {
+			return fmt.Errorf("error: key '%s' cannot be written to a tfvars file", key)
+		}
+		fmt.Fprintf(bw, "%s = %s\n", name, hclString(env.Get(key)))
+	}
+	return bw.Flush()
+}
+
+// hclString quotes s as an HCL string literal.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			b.WriteString(`\"`)
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case (c == '$' || c == '%') && i+1 < len(s) && s[i+1] == '{':
+			b.WriteByte(c)
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// WriteHelmValues writes env to w as a Helm values.yaml fragment. paths
+// maps keys to dotted paths in the values tree, so that
+//
+//	map[string]string{"IMAGE_TAG": "image.tag", "REPLICAS": "replicaCount"}
+//
+// writes
+//
+//	image:
+//	  tag: "1.4.2"
+//	replicaCount: "3"
+//
+// Keys missing from paths are skipped. A nil paths writes every key at
+// the top level under its own name. Values are always quoted, so that
+// YAML never reinterprets them as numbers or booleans; templates convert
+// them as needed. Paths with empty segments, and paths that are both a
+// value and the parent of another path, are rejected with an error.
+func WriteHelmValues(w io.Writer, env *envfile.Environment, paths map[string]string) error {
+	root := newYAMLMapping()
+	for _, key := range env.Keys() {
+		p := key
+		if paths != nil {
+			var mapped bool
+			if p, mapped = paths[key]; !mapped {
+				continue
+			}
+		}
+		if err := setHelmValue(root, p, env.Get(key)); err != nil {
+			return fmt.Errorf("error: key '%s' cannot be written to Helm values: %v", key, err)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	writeHelmNode(bw, root, 0)
+	return bw.Flush()
+}
+
+// setHelmValue sets the value at the dotted path p in root.
+func setHelmValue(root *yamlNode, p, value string) error {
+	segments := strings.Split(p, ".")
+	node := root
+	for i, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("invalid path '%s'", p)
+		}
+		child := node.fields[segment]
+		if i == len(segments)-1 {
+			if child != nil {
+				return fmt.Errorf("path '%s' is already set", p)
+			}
+			node.set(segment, &yamlNode{kind: yamlScalar, value: value})
+			return nil
+		}
+		if child == nil {
+			child = newYAMLMapping()
+			node.set(segment, child)
+		} else if child.kind != yamlMapping {
+			return fmt.Errorf("path '%s' is below the value '%s'", p, strings.Join(segments[:i+1], "."))
+		}
+		node = child
+	}
+	return nil
+}
+
+var helmPlainKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func writeHelmNode(w *bufio.Writer, node *yamlNode, indent int) {
+	for _, key := range node.keys {
+		child := node.fields[key]
+		name := key
+		if !helmPlainKeyRegex.MatchString(key) {
+			name = strconv.Quote(key)
+		}
+		if child.kind == yamlMapping {
+			fmt.Fprintf(w, "%s%s:\n", strings.Repeat("  ", indent), name)
+			writeHelmNode(w, child, indent+1)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s: %s\n", strings.Repeat("  ", indent), name, strconv.Quote(child.value))
+	}
+}
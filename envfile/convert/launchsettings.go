@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type launchProfile struct {
+	CommandName          string            `json:"commandName"`
+	ApplicationURL       string            `json:"applicationUrl"`
+	EnvironmentVariables map[string]string `json:"environmentVariables"`
+}
+
+// LaunchSettings reads a .NET Properties/launchSettings.json file and
+// returns the environment variables of the named profile. Keys are kept
+// as they are, since they are already variable names; nested .NET
+// configuration keys use "__" as the separator, as in
+// Logging__LogLevel__Default.
+//
+// As with dotnet run, the profile's applicationUrl is returned as
+// ASPNETCORE_URLS unless that variable is set explicitly, and an empty
+// profile name selects the first profile whose commandName is "Project".
+func LaunchSettings(r io.Reader, profile string) (map[string]string, error) {
+	var settings struct {
+		Profiles json.RawMessage `json:"profiles"`
+	}
+	if err := json.NewDecoder(r).Decode(&settings); err != nil {
+		return nil, fmt.Errorf("error: unable to decode launch settings: %v", err)
+	}
+
+	var profiles map[string]launchProfile
+	if len(settings.Profiles) > 0 {
+		if err := json.Unmarshal(settings.Profiles, &profiles); err != nil {
+			return nil, fmt.Errorf("error: unable to decode launch settings profiles: %v", err)
+		}
+	}
+
+	if profile == "" {
+		names, err := objectKeys(settings.Profiles)
+		if err != nil {
+			return nil, fmt.Errorf("error: unable to decode launch settings profiles: %v", err)
+		}
+		for _, name := range names {
+			if profiles[name].CommandName == "Project" {
+				profile = name
+				break
+			}
+		}
+		if profile == "" {
+			return nil, fmt.Errorf("error: launch settings have no profile with commandName 'Project'")
+		}
+	}
+
+	p, exists := profiles[profile]
+	if !exists {
+		return nil, fmt.Errorf("error: launch settings have no profile '%s'", profile)
+	}
+
+	values := make(map[string]string, len(p.EnvironmentVariables)+1)
+	for key, value := range p.EnvironmentVariables {
+		values[key] = value
+	}
+	if _, exists := values["ASPNETCORE_URLS"]; !exists && p.ApplicationURL != "" {
+		values["ASPNETCORE_URLS"] = strings.TrimSpace(p.ApplicationURL)
+	}
+	return values, nil
+}
+
+// objectKeys returns the keys of a JSON object in document order.
+func objectKeys(data json.RawMessage) ([]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,149 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// Properties reads a Java .properties file and returns its entries with
+// keys converted by EnvKey, so that "db.host=localhost" becomes DB_HOST.
+// It follows the format read by java.util.Properties: '#' and '!' start
+// comments, keys are separated from values by '=', ':' or whitespace, a
+// trailing backslash continues the line, and backslash escapes including
+// \uXXXX are decoded.
+func Properties(r io.Reader) (map[string]string, error) {
+	entries, err := PropertiesEntries(r)
+	if err != nil {
+		return nil, err
+	}
+	return entryMap(entries), nil
+}
+
+// PropertiesEntries is like Properties, but returns the entries in file
+// order, as an envfile.FileFormat for envfile.RegisterFormat. A key defined
+// more than once is returned each time, and the last value wins.
+func PropertiesEntries(r io.Reader) ([]envfile.Entry, error) {
+	var entries []envfile.Entry
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	var logical strings.Builder
+	start := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical.Len() == 0 {
+			if line == "" || line[0] == '#' || line[0] == '!' {
+				continue
+			}
+			start = lineNumber
+		}
+
+		if continued(line) {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return nil, fmt.Errorf("error: properties at line %d: %v", start, err)
+		}
+		entries = append(entries, envfile.Entry{Key: EnvKey(key), Value: value})
+		logical.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error: unable to read properties: %v", err)
+	}
+	if logical.Len() > 0 {
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return nil, fmt.Errorf("error: properties at line %d: %v", start, err)
+		}
+		entries = append(entries, envfile.Entry{Key: EnvKey(key), Value: value})
+	}
+	return entries, nil
+}
+
+// continued reports whether line ends in an odd number of backslashes.
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits a logical line into its unescaped key and value.
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape '\\u%s'", s[i+1:i+5])
+			}
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
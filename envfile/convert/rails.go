@@ -0,0 +1,61 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// erbRegex matches a value that is entirely an ERB tag.
+var erbRegex = regexp.MustCompile(`^\s*<%=?.*%>\s*$`)
+
+// RailsSecrets reads a Rails config/secrets.yml file, or a decrypted
+// credentials file, and returns its values as variables. Nested keys are
+// joined with underscores and converted by EnvKey, so
+//
+//	production:
+//	  aws:
+//	    access_key_id: AKIA...
+//
+// becomes AWS_ACCESS_KEY_ID when environment is "production". Keys under
+// "shared" are included and overridden by the environment's own keys.
+// An empty environment converts the whole document, as suits
+// credentials files, which have no environment sections.
+//
+// Values that are ERB tags, such as <%= ENV["SECRET_KEY_BASE"] %>, are
+// left out, since Rails reads them from the environment anyway.
+func RailsSecrets(r io.Reader, environment string) (map[string]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read secrets: %v", err)
+	}
+
+	root, err := parseYAML(string(content), "secrets")
+	if err != nil {
+		return nil, err
+	}
+	if root.kind != yamlMapping {
+		return nil, fmt.Errorf("error: secrets must be a mapping")
+	}
+
+	values := make(map[string]string)
+	if environment == "" {
+		flatten(values, "", root)
+	} else {
+		section, exists := root.fields[environment]
+		if !exists {
+			return nil, fmt.Errorf("error: secrets have no '%s' section", environment)
+		}
+		if shared, exists := root.fields["shared"]; exists {
+			flatten(values, "", shared)
+		}
+		flatten(values, "", section)
+	}
+
+	for key, value := range values {
+		if erbRegex.MatchString(value) {
+			delete(values, key)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,79 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// RegistryScope selects the Windows environment written by WriteRegistry.
+type RegistryScope int
+
+const (
+	// RegistryUser is the environment of the current user, stored under
+	// HKEY_CURRENT_USER\Environment.
+	RegistryUser RegistryScope = iota
+	// RegistryMachine is the system-wide environment, stored under
+	// HKEY_LOCAL_MACHINE\SYSTEM\CurrentControlSet\Control\Session
+	// Manager\Environment. Writing it requires administrator rights.
+	RegistryMachine
+)
+
+func (s RegistryScope) String() string {
+	switch s {
+	case RegistryUser:
+		return "user"
+	case RegistryMachine:
+		return "machine"
+	}
+	return fmt.Sprintf("RegistryScope(%d)", int(s))
+}
+
+// WriteRegistry persists the variables of env in the Windows environment
+// of scope, so that installers and services can set configuration that
+// survives reboots and is seen by every new process, then broadcasts
+// WM_SETTINGCHANGE so that Explorer and other running programs reload
+// it. Values containing '%' are stored as REG_EXPAND_SZ, so that
+// references such as %USERPROFILE% are expanded, and others as REG_SZ.
+// Running processes, including the caller, keep their environment.
+//
+// On other platforms, WriteRegistry fails with an error wrapping
+// errors.ErrUnsupported.
+func WriteRegistry(env *envfile.Environment, scope RegistryScope) error {
+	values := make(map[string]string, env.Len())
+	for _, key := range env.Keys() {
+		value := env.Get(key)
+		if err := checkRegistry(key, value); err != nil {
+			return err
+		}
+		values[key] = value
+	}
+	return writeRegistry(env.Keys(), values, scope)
+}
+
+// DeleteRegistry removes keys from the Windows environment of scope, for
+// uninstallers undoing WriteRegistry, and broadcasts WM_SETTINGCHANGE.
+// Keys that are not set are ignored. On other platforms, it fails with an
+// error wrapping errors.ErrUnsupported.
+func DeleteRegistry(keys []string, scope RegistryScope) error {
+	for _, key := range keys {
+		if err := checkRegistry(key, ""); err != nil {
+			return err
+		}
+	}
+	return deleteRegistry(keys, scope)
+}
+
+// checkRegistry reports whether the variable can be stored in the
+// registry, where names cannot contain '=' and neither names nor values
+// can contain NUL characters.
+func checkRegistry(key, value string) error {
+	if key == "" || strings.ContainsAny(key, "=\x00") {
+		return fmt.Errorf("error: key '%s' cannot be written to the Windows registry", key)
+	}
+	if strings.Contains(value, "\x00") {
+		return fmt.Errorf("error: value of '%s' cannot be written to the Windows registry", key)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+func writeRegistry(keys []string, values map[string]string, scope RegistryScope) error {
+	return fmt.Errorf("error: the Windows registry is not available on %s: %w", runtime.GOOS, errors.ErrUnsupported)
+}
+
+func deleteRegistry(keys []string, scope RegistryScope) error {
+	return fmt.Errorf("error: the Windows registry is not available on %s: %w", runtime.GOOS, errors.ErrUnsupported)
+}
@@ -0,0 +1,104 @@
+//go:build windows
+
+package convert
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32                = syscall.NewLazyDLL("advapi32.dll")
+	procRegSetValueExW      = advapi32.NewProc("RegSetValueExW")
+	procRegDeleteValueW     = advapi32.NewProc("RegDeleteValueW")
+	user32                  = syscall.NewLazyDLL("user32.dll")
+	procSendMessageTimeoutW = user32.NewProc("SendMessageTimeoutW")
+)
+
+const (
+	hwndBroadcast    = 0xffff
+	wmSettingChange  = 0x001a
+	smtoAbortIfHung  = 0x0002
+	broadcastTimeout = 5000 // milliseconds
+)
+
+// openEnvironmentKey opens the registry key holding the environment of
+// scope for writing.
+func openEnvironmentKey(scope RegistryScope) (syscall.Handle, string, error) {
+	root, path, name := syscall.Handle(syscall.HKEY_CURRENT_USER), `Environment`, `HKEY_CURRENT_USER\Environment`
+	if scope == RegistryMachine {
+		root, path = syscall.HKEY_LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+		name = `HKEY_LOCAL_MACHINE\` + path
+	}
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, name, err
+	}
+	var key syscall.Handle
+	if err := syscall.RegOpenKeyEx(root, pathPtr, 0, syscall.KEY_SET_VALUE, &key); err != nil {
+		return 0, name, fmt.Errorf("error: unable to open registry key '%s': %w", name, err)
+	}
+	return key, name, nil
+}
+
+func writeRegistry(keys []string, values map[string]string, scope RegistryScope) error {
+	key, name, err := openEnvironmentKey(scope)
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	for _, k := range keys {
+		value := values[k]
+		kind := uint32(syscall.REG_SZ)
+		if strings.Contains(value, "%") {
+			kind = syscall.REG_EXPAND_SZ
+		}
+		namePtr, err := syscall.UTF16PtrFromString(k)
+		if err != nil {
+			return err
+		}
+		data, err := syscall.UTF16FromString(value)
+		if err != nil {
+			return err
+		}
+		r, _, _ := procRegSetValueExW.Call(uintptr(key), uintptr(unsafe.Pointer(namePtr)), 0, uintptr(kind), uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)*2))
+		if r != 0 {
+			return fmt.Errorf("error: unable to write '%s' to registry key '%s': %w", k, name, syscall.Errno(r))
+		}
+	}
+	broadcastSettingChange()
+	return nil
+}
+
+func deleteRegistry(keys []string, scope RegistryScope) error {
+	key, name, err := openEnvironmentKey(scope)
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	for _, k := range keys {
+		namePtr, err := syscall.UTF16PtrFromString(k)
+		if err != nil {
+			return err
+		}
+		r, _, _ := procRegDeleteValueW.Call(uintptr(key), uintptr(unsafe.Pointer(namePtr)))
+		if r != 0 && syscall.Errno(r) != syscall.ERROR_FILE_NOT_FOUND {
+			return fmt.Errorf("error: unable to delete '%s' from registry key '%s': %w", k, name, syscall.Errno(r))
+		}
+	}
+	broadcastSettingChange()
+	return nil
+}
+
+// broadcastSettingChange tells the running top-level windows that the
+// environment changed. Windows that do not answer within the timeout are
+// skipped, so a hung program cannot block the caller.
+func broadcastSettingChange() {
+	param, _ := syscall.UTF16PtrFromString("Environment")
+	var result uintptr
+	procSendMessageTimeoutW.Call(hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(param)), smtoAbortIfHung, broadcastTimeout, uintptr(unsafe.Pointer(&result)))
+}
@@ -0,0 +1,136 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// DefaultSystemdLineLength is the length of the Environment= lines
+// written by WriteSystemdDropIn unless SystemdDropIn.MaxLineLength is
+// set.
+const DefaultSystemdLineLength = 2048
+
+// SystemdDropIn configures WriteSystemdDropIn.
+type SystemdDropIn struct {
+	// EnvironmentFile is the absolute path of a file holding the
+	// variables, such as one written by WriteSystemdEnvironmentFile. If
+	// set, the drop-in references it with an EnvironmentFile= line
+	// instead of listing the variables, which keeps their values out of
+	// the unit configuration that any user can read with systemctl show.
+	EnvironmentFile string
+	// MaxLineLength is the length an Environment= line is kept under by
+	// starting a new one. A single variable longer than that gets a line
+	// of its own. It defaults to DefaultSystemdLineLength.
+	MaxLineLength int
+}
+
+// WriteSystemdDropIn writes env to w as a systemd drop-in unit snippet,
+// such as /etc/systemd/system/app.service.d/env.conf, setting the
+// variables of the service:
+//
+//	[Service]
+//	Environment="PORT=8080" "LOG_LEVEL=info"
+//	Environment="GREETING=hello \"world\""
+//
+// Every assignment is quoted, with backslashes, double quotes and
+// control characters escaped and '%' doubled so that systemd does not
+// read it as a specifier. If d is nil, the defaults are used; if
+// d.EnvironmentFile is set, env is not used and may be nil.
+func WriteSystemdDropIn(w io.Writer, env *envfile.Environment, d *SystemdDropIn) error {
+	if d == nil {
+		d = &SystemdDropIn{}
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[Service]\n")
+	if d.EnvironmentFile != "" {
+		if strings.ContainsAny(d.EnvironmentFile, "\r\n") {
+			return fmt.Errorf("error: environment file '%s' cannot be written to a systemd unit", d.EnvironmentFile)
+		}
+		fmt.Fprintf(bw, "EnvironmentFile=%s\n", strings.ReplaceAll(d.EnvironmentFile, "%", "%%"))
+		return bw.Flush()
+	}
+
+	limit := d.MaxLineLength
+	if limit <= 0 {
+		limit = DefaultSystemdLineLength
+	}
+	var line strings.Builder
+	for _, key := range env.Keys() {
+		if !gitLabKeyRegex.MatchString(key) {
+			return fmt.Errorf("error: key '%s' cannot be written to a systemd unit", key)
+		}
+		assignment := `"` + systemdEscape(key+"="+env.Get(key)) + `"`
+		if line.Len() > 0 && line.Len()+1+len(assignment) > limit {
+			fmt.Fprintf(bw, "%s\n", line.String())
+			line.Reset()
+		}
+		if line.Len() == 0 {
+			line.WriteString("Environment=")
+		} else {
+			line.WriteByte(' ')
+		}
+		line.WriteString(assignment)
+	}
+	if line.Len() > 0 {
+		fmt.Fprintf(bw, "%s\n", line.String())
+	}
+	return bw.Flush()
+}
+
+// WriteSystemdEnvironmentFile writes env to w in the format systemd reads
+// from an EnvironmentFile=, one KEY="value" assignment per variable, with
+// backslashes, double quotes, '$' and '`' escaped. Line breaks in values
+// are kept, since systemd reads quoted values across lines. It is meant
+// for a file readable only by root, referenced by a drop-in written with
+// SystemdDropIn.EnvironmentFile.
+func WriteSystemdEnvironmentFile(w io.Writer, env *envfile.Environment) error {
+	bw := bufio.NewWriter(w)
+	for _, key := range env.Keys() {
+		if !gitLabKeyRegex.MatchString(key) {
+			return fmt.Errorf("error: key '%s' cannot be written to a systemd environment file", key)
+		}
+		fmt.Fprintf(bw, "%s=\"%s\"\n", key, environmentFileEscape(env.Get(key)))
+	}
+	return bw.Flush()
+}
+
+// systemdEscape escapes s for a double-quoted string of a unit file.
+func systemdEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '%':
+			b.WriteString("%%")
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// environmentFileEscape escapes s for a double-quoted value of an
+// EnvironmentFile=, which follows shell rules.
+func environmentFileEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\\"$`", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,234 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// TOML reads a flat TOML file and returns its values with keys converted
+// by EnvKey. Table headers prefix the keys below them, so "host" in
+// [database.primary] becomes DATABASE_PRIMARY_HOST, as do dotted keys.
+// Strings are unquoted, arrays of scalars become comma-separated values,
+// and other scalars, such as numbers, booleans and dates, are kept as
+// written. Multi-line strings, inline tables, nested arrays and arrays
+// of tables are not supported.
+func TOML(r io.Reader) (map[string]string, error) {
+	entries, err := TOMLEntries(r)
+	if err != nil {
+		return nil, err
+	}
+	return entryMap(entries), nil
+}
+
+// TOMLEntries is like TOML, but returns the entries in file order, as an
+// envfile.FileFormat for envfile.RegisterFormat.
+func TOMLEntries(r io.Reader) ([]envfile.Entry, error) {
+	var entries []envfile.Entry
+	var table []string
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[[") {
+			return nil, fmt.Errorf("error: TOML at line %d: arrays of tables are not supported", lineNumber)
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 || strings.TrimSpace(tomlStripComment(line[end+1:])) != "" {
+				return nil, fmt.Errorf("error: TOML at line %d: malformed table header", lineNumber)
+			}
+			keys, err := tomlKeys(line[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("error: TOML at line %d: %v", lineNumber, err)
+			}
+			table = keys
+			continue
+		}
+
+		keyText, valueText, err := tomlSplit(line)
+		if err != nil {
+			return nil, fmt.Errorf("error: TOML at line %d: %v", lineNumber, err)
+		}
+		keys, err := tomlKeys(keyText)
+		if err != nil {
+			return nil, fmt.Errorf("error: TOML at line %d: %v", lineNumber, err)
+		}
+		value, err := tomlValue(valueText)
+		if err != nil {
+			return nil, fmt.Errorf("error: TOML at line %d: value of '%s': %v", lineNumber, strings.TrimSpace(keyText), err)
+		}
+		path := append(table[:len(table):len(table)], keys...)
+		entries = append(entries, envfile.Entry{Key: EnvKey(strings.Join(path, ".")), Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error: unable to read TOML: %v", err)
+	}
+	return entries, nil
+}
+
+// tomlSplit splits a key/value line at the '=' outside quoted keys.
+func tomlSplit(line string) (string, string, error) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"', '\'':
+			end := closingQuote(line[i:])
+			if end < 0 {
+				return "", "", fmt.Errorf("unterminated quoted key")
+			}
+			i += end
+		case '=':
+			return line[:i], strings.TrimSpace(line[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("expected 'key = value'")
+}
+
+// tomlKeys splits a possibly dotted key into its unquoted parts.
+func tomlKeys(text string) ([]string, error) {
+	var keys []string
+	text = strings.TrimSpace(text)
+	for text != "" {
+		var key string
+		if text[0] == '"' || text[0] == '\'' {
+			end := closingQuote(text)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			unquoted, err := tomlString(text[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			key, text = unquoted, strings.TrimSpace(text[end+1:])
+		} else {
+			end := strings.IndexByte(text, '.')
+			if end < 0 {
+				end = len(text)
+			}
+			key, text = strings.TrimSpace(text[:end]), strings.TrimSpace(text[end:])
+			if key == "" {
+				return nil, fmt.Errorf("empty key")
+			}
+		}
+		keys = append(keys, key)
+		if text == "" {
+			break
+		}
+		if text[0] != '.' {
+			return nil, fmt.Errorf("malformed key")
+		}
+		text = strings.TrimSpace(text[1:])
+		if text == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return keys, nil
+}
+
+// tomlValue returns the flat value of a TOML value with its trailing
+// comment.
+func tomlValue(text string) (string, error) {
+	if text == "" {
+		return "", fmt.Errorf("missing value")
+	}
+	switch {
+	case strings.HasPrefix(text, `"""`), strings.HasPrefix(text, "'''"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case text[0] == '{':
+		return "", fmt.Errorf("inline tables are not supported")
+	case text[0] == '[':
+		return tomlArray(text)
+	case text[0] == '"' || text[0] == '\'':
+		end := closingQuote(text)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if strings.TrimSpace(tomlStripComment(text[end+1:])) != "" {
+			return "", fmt.Errorf("unexpected content after string")
+		}
+		return tomlString(text[:end+1])
+	}
+	return strings.TrimSpace(tomlStripComment(text)), nil
+}
+
+// tomlArray flattens a single-line array of scalars into a
+// comma-separated value.
+func tomlArray(text string) (string, error) {
+	var items []string
+	rest := strings.TrimSpace(text[1:])
+	for {
+		if rest == "" {
+			return "", fmt.Errorf("unterminated array; arrays must be on a single line")
+		}
+		if rest[0] == ']' {
+			break
+		}
+		var item string
+		switch rest[0] {
+		case '[', '{':
+			return "", fmt.Errorf("nested arrays and inline tables are not supported")
+		case '"', '\'':
+			end := closingQuote(rest)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated string")
+			}
+			unquoted, err := tomlString(rest[:end+1])
+			if err != nil {
+				return "", err
+			}
+			item, rest = unquoted, strings.TrimSpace(rest[end+1:])
+		default:
+			end := strings.IndexAny(rest, ",]")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated array; arrays must be on a single line")
+			}
+			item, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+		items = append(items, item)
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if !strings.HasPrefix(rest, "]") {
+			return "", fmt.Errorf("expected ',' or ']' in array")
+		}
+	}
+	if strings.TrimSpace(tomlStripComment(rest[1:])) != "" {
+		return "", fmt.Errorf("unexpected content after array")
+	}
+	return strings.Join(items, ","), nil
+}
+
+// tomlString unquotes a basic or literal TOML string.
+func tomlString(text string) (string, error) {
+	if text[0] == '\'' {
+		return text[1 : len(text)-1], nil
+	}
+	value, err := strconv.Unquote(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", text)
+	}
+	return value, nil
+}
+
+// tomlStripComment removes a trailing comment from unquoted text.
+func tomlStripComment(text string) string {
+	if i := strings.IndexByte(text, '#'); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
@@ -0,0 +1,476 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlKind is the kind of a yamlNode.
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMapping
+	yamlSequence
+)
+
+// yamlNode is a node of the YAML subset read by parseYAML. Mapping keys
+// keep their document order.
+type yamlNode struct {
+	kind   yamlKind
+	value  string
+	keys   []string
+	fields map[string]*yamlNode
+	items  []*yamlNode
+}
+
+func newYAMLMapping() *yamlNode {
+	return &yamlNode{kind: yamlMapping, fields: make(map[string]*yamlNode)}
+}
+
+func (n *yamlNode) set(key string, value *yamlNode) {
+	if _, exists := n.fields[key]; !exists {
+		n.keys = append(n.keys, key)
+	}
+	n.fields[key] = value
+}
+
+// yamlLine is a line of the document with its indentation measured.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+	raw    string
+}
+
+// yamlParser reads the block-style YAML found in configuration files:
+// nested mappings, sequences, plain and quoted scalars, literal and
+// folded block scalars, simple flow sequences, and anchors, aliases and
+// merge keys. Tags, multiple documents and complex keys are not
+// supported.
+type yamlParser struct {
+	source  string
+	lines   []yamlLine
+	pos     int
+	anchors map[string]*yamlNode
+}
+
+func parseYAML(content, source string) (*yamlNode, error) {
+	p := &yamlParser{source: source, anchors: make(map[string]*yamlNode)}
+	for i, raw := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		text := strings.TrimLeft(raw, " ")
+		p.lines = append(p.lines, yamlLine{
+			number: i + 1,
+			indent: len(raw) - len(text),
+			text:   strings.TrimRight(text, " \t"),
+			raw:    raw,
+		})
+	}
+
+	p.skip()
+	if p.pos < len(p.lines) && p.lines[p.pos].text == "---" {
+		p.pos++
+		p.skip()
+	}
+	if p.pos >= len(p.lines) {
+		return newYAMLMapping(), nil
+	}
+
+	node, err := p.block(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if p.pos < len(p.lines) && p.lines[p.pos].text != "..." && p.lines[p.pos].text != "---" {
+		return nil, p.errorf(p.lines[p.pos], "unexpected content")
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].text == "---" {
+		return nil, p.errorf(p.lines[p.pos], "multiple documents are not supported")
+	}
+	return node, nil
+}
+
+func (p *yamlParser) errorf(line yamlLine, format string, args ...any) error {
+	return fmt.Errorf("error: '%s' at line %d: %s", p.source, line.number, fmt.Sprintf(format, args...))
+}
+
+// skip moves past blank and comment lines.
+func (p *yamlParser) skip() {
+	for p.pos < len(p.lines) {
+		text := p.lines[p.pos].text
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return
+		}
+		p.pos++
+	}
+}
+
+// block parses the mapping or sequence starting at the current line.
+func (p *yamlParser) block(indent int) (*yamlNode, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) mapping(indent int) (*yamlNode, error) {
+	node := newYAMLMapping()
+	var merges []*yamlNode
+
+	for p.skip(); p.pos < len(p.lines); p.skip() {
+		line := p.lines[p.pos]
+		if line.indent < indent || isSequenceItem(line.text) || line.text == "---" || line.text == "..." {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf(line, "unexpected indentation")
+		}
+
+		key, rest, err := p.splitKey(line)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+
+		value, err := p.value(line, indent, rest, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "<<" {
+			switch value.kind {
+			case yamlMapping:
+				merges = append(merges, value)
+			case yamlSequence:
+				merges = append(merges, value.items...)
+			default:
+				return nil, p.errorf(line, "merge key needs a mapping")
+			}
+			continue
+		}
+		node.set(key, value)
+	}
+
+	// Explicit keys take precedence over merged ones, and earlier merges
+	// over later ones.
+	for _, merge := range merges {
+		for _, key := range merge.keys {
+			if _, exists := node.fields[key]; !exists {
+				node.set(key, merge.fields[key])
+			}
+		}
+	}
+	return node, nil
+}
+
+func (p *yamlParser) sequence(indent int) (*yamlNode, error) {
+	node := &yamlNode{kind: yamlSequence}
+
+	for p.skip(); p.pos < len(p.lines); p.skip() {
+		line := p.lines[p.pos]
+		if line.indent != indent || !isSequenceItem(line.text) {
+			if line.indent > indent {
+				return nil, p.errorf(line, "unexpected indentation")
+			}
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if rest != "" && !strings.HasPrefix(rest, "#") && p.isMappingEntry(rest) {
+			// "- key: value" starts a mapping indented to the key.
+			p.lines[p.pos].indent = indent + len(line.text) - len(rest)
+			p.lines[p.pos].text = rest
+			item, err := p.mapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			node.items = append(node.items, item)
+			continue
+		}
+
+		p.pos++
+		item, err := p.value(line, indent, rest, false)
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, item)
+	}
+	return node, nil
+}
+
+// isMappingEntry reports whether text starts with a "key:" pair.
+func (p *yamlParser) isMappingEntry(text string) bool {
+	_, _, err := p.splitKey(yamlLine{text: text})
+	return err == nil
+}
+
+// splitKey splits a "key: value" line.
+func (p *yamlParser) splitKey(line yamlLine) (key, rest string, err error) {
+	text := line.text
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", p.errorf(line, "unterminated quoted key")
+		}
+		key, err = unquoteScalar(text[:end+1])
+		if err != nil {
+			return "", "", p.errorf(line, "%v", err)
+		}
+		text = text[end+1:]
+		if !strings.HasPrefix(text, ":") {
+			return "", "", p.errorf(line, "expected ':' after key")
+		}
+		return key, strings.TrimSpace(text[1:]), nil
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), nil
+		}
+	}
+	return "", "", p.errorf(line, "expected 'key: value'")
+}
+
+// value parses the value following a mapping key or sequence dash on
+// line, including any nested block on the following lines.
+func (p *yamlParser) value(line yamlLine, indent int, rest string, inMapping bool) (*yamlNode, error) {
+	var anchor string
+	if strings.HasPrefix(rest, "&") {
+		anchor, rest, _ = strings.Cut(rest[1:], " ")
+		rest = strings.TrimSpace(rest)
+	}
+
+	var node *yamlNode
+	var err error
+	switch {
+	case strings.HasPrefix(rest, "*"):
+		name := strings.TrimSpace(stripComment(rest[1:]))
+		alias, exists := p.anchors[name]
+		if !exists {
+			return nil, p.errorf(line, "unknown alias '%s'", name)
+		}
+		node = alias
+	case rest == "" || strings.HasPrefix(rest, "#"):
+		node, err = p.nested(indent, inMapping)
+	case rest[0] == '|' || rest[0] == '>':
+		node, err = p.blockScalar(line, indent, rest)
+	case rest[0] == '[':
+		node, err = p.flowSequence(line, rest)
+	case rest[0] == '{':
+		if strings.TrimSpace(stripComment(rest)) != "{}" {
+			return nil, p.errorf(line, "flow mappings are not supported")
+		}
+		node = newYAMLMapping()
+	default:
+		var value string
+		value, err = scalar(rest)
+		if err != nil {
+			return nil, p.errorf(line, "%v", err)
+		}
+		node = &yamlNode{kind: yamlScalar, value: value}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if anchor != "" {
+		p.anchors[anchor] = node
+	}
+	return node, nil
+}
+
+// nested parses the block below a key or dash with no inline value, or
+// returns an empty scalar if there is none.
+func (p *yamlParser) nested(indent int, inMapping bool) (*yamlNode, error) {
+	p.skip()
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent {
+			return p.block(next.indent)
+		}
+		// A sequence may sit at the same indentation as its key.
+		if inMapping && next.indent == indent && isSequenceItem(next.text) {
+			return p.sequence(indent)
+		}
+	}
+	return &yamlNode{kind: yamlScalar}, nil
+}
+
+// blockScalar reads a literal (|) or folded (>) block scalar.
+func (p *yamlParser) blockScalar(line yamlLine, indent int, header string) (*yamlNode, error) {
+	header = strings.TrimSpace(stripComment(header))
+	folded := header[0] == '>'
+	chomp := byte(0)
+	for _, c := range []byte(header[1:]) {
+		switch {
+		case c == '-' || c == '+':
+			chomp = c
+		case c >= '1' && c <= '9':
+		default:
+			return nil, p.errorf(line, "invalid block scalar header '%s'", header)
+		}
+	}
+
+	var lines []string
+	contentIndent := -1
+	for p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.text == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if next.indent <= indent {
+			break
+		}
+		if contentIndent < 0 {
+			contentIndent = next.indent
+		}
+		if next.indent < contentIndent {
+			break
+		}
+		lines = append(lines, next.raw[contentIndent:])
+		p.pos++
+	}
+
+	// Trailing blank lines belong to the chomping rule, not the content.
+	trailing := 0
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var value string
+	if folded {
+		var b strings.Builder
+		for i, l := range lines {
+			if i > 0 {
+				if l == "" || lines[i-1] == "" {
+					b.WriteString("\n")
+				} else {
+					b.WriteString(" ")
+				}
+			}
+			b.WriteString(l)
+		}
+		value = b.String()
+	} else {
+		value = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case '+':
+		value += strings.Repeat("\n", trailing+1)
+	case 0:
+		if len(lines) > 0 {
+			value += "\n"
+		}
+	}
+	return &yamlNode{kind: yamlScalar, value: value}, nil
+}
+
+// flowSequence reads a single-line sequence of scalars such as [a, b].
+func (p *yamlParser) flowSequence(line yamlLine, text string) (*yamlNode, error) {
+	text = strings.TrimSpace(stripComment(text))
+	if !strings.HasSuffix(text, "]") {
+		return nil, p.errorf(line, "flow sequences must end on the same line")
+	}
+	node := &yamlNode{kind: yamlSequence}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	if inner == "" {
+		return node, nil
+	}
+	for _, item := range splitFlow(inner) {
+		value, err := scalar(strings.TrimSpace(item))
+		if err != nil {
+			return nil, p.errorf(line, "%v", err)
+		}
+		node.items = append(node.items, &yamlNode{kind: yamlScalar, value: value})
+	}
+	return node, nil
+}
+
+// splitFlow splits s at commas outside of quotes.
+func splitFlow(s string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// scalar returns the value of an inline scalar.
+func scalar(text string) (string, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted string")
+		}
+		if rest := strings.TrimSpace(text[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected content after quoted string")
+		}
+		return unquoteScalar(text[:end+1])
+	}
+
+	value := strings.TrimSpace(stripComment(text))
+	if value == "~" || value == "null" {
+		return "", nil
+	}
+	return value, nil
+}
+
+// stripComment removes a trailing " # comment" from a plain scalar.
+func stripComment(text string) string {
+	if strings.HasPrefix(text, "#") {
+		return ""
+	}
+	if i := strings.Index(text, " #"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// closingQuote returns the index of the quote that closes the quoted
+// string at the start of text, or -1.
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteScalar(text string) (string, error) {
+	if text[0] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	value, err := strconv.Unquote(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted string %s", text)
+	}
+	return value, nil
+}
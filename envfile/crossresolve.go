@@ -0,0 +1,68 @@
+package envfile
+
+// CrossResolve reads the overlay file with its {$NAME} references
+// resolved against the keys and $-prefixed template variables defined in
+// the base file, so that per-service files can share definitions kept in
+// a common file:
+//
+//	# .env.common
+//	REGION=eu-west-1
+//
+//	# .env.api
+//	QUEUE_URL=https://sqs.{$REGION}.amazonaws.com/api
+//
+// Template variables defined in the overlay take precedence over the
+// definitions of base, and a base template variable takes precedence over
+// a base key of the same name. Only the variables of overlay are
+// returned; the process environment is not modified.
+func CrossResolve(base, overlay string, opts ...Option) (*Environment, error) {
+	return New(opts...).CrossResolve(base, overlay)
+}
+
+// CrossResolve reads overlay with its references resolved against base.
+// See the package-level CrossResolve.
+func (l *Loader) CrossResolve(base, overlay string) (*Environment, error) {
+	po, err := l.parseOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.checkFile(base); err != nil {
+		return nil, err
+	}
+	baseVars, bp, err := parseFileIncludes(base, po)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExpired(base, baseVars, po); err != nil {
+		return nil, err
+	}
+	if baseVars, err = l.process(base, baseVars); err != nil {
+		return nil, err
+	}
+
+	if err := l.checkFile(overlay); err != nil {
+		return nil, err
+	}
+	p := newParser(overlay, po)
+	for _, v := range baseVars {
+		p.variables["$"+v.key] = v.value
+	}
+	for name, value := range bp.variables {
+		p.variables[name] = value
+	}
+	if err := p.parseFile(overlay); err != nil {
+		return nil, err
+	}
+	variables, err := p.finish()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExpired(overlay, variables, po); err != nil {
+		return nil, err
+	}
+	if variables, err = l.process(overlay, variables); err != nil {
+		return nil, err
+	}
+	return l.newEnvironment(variables), nil
+}
@@ -0,0 +1,140 @@
+package envfile
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Decoder decodes the data of a value written as "scheme:data", such as
+// "base64:SGVsbG8=". dir is the directory of the env file containing the
+// value, or empty when parsing from a reader, so that decoders can
+// resolve relative paths.
+type Decoder func(data, dir string) (string, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		"base64": decodeBase64,
+		"file":   decodeFile,
+	}
+)
+
+// RegisterDecoder makes d available for values prefixed with "scheme:",
+// replacing any decoder already registered for scheme. The built-in
+// decoders are:
+//
+//	base64:DATA  the standard or URL-safe base64 decoding of DATA
+//	file:PATH    the content of the file at PATH, relative to the env file,
+//	             with one trailing newline removed
+func RegisterDecoder(scheme string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[scheme] = d
+}
+
+// Decoders returns the registered schemes in sorted order.
+func Decoders() []string {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	schemes := make([]string, 0, len(decoders))
+	for scheme := range decoders {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func lookupDecoder(scheme string) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	d, exists := decoders[scheme]
+	return d, exists
+}
+
+// decoderOptions selects the schemes decoded at load time.
+type decoderOptions struct {
+	// schemes lists the enabled schemes, or is empty to enable every
+	// registered scheme.
+	schemes []string
+}
+
+func (d *decoderOptions) enabled(scheme string) bool {
+	if len(d.schemes) == 0 {
+		return true
+	}
+	for _, s := range d.schemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// WithDecoders decodes values written as "scheme:data" with the Decoder
+// registered for scheme, after template variables are substituted. Only
+// the given schemes are decoded, or every registered scheme if none are
+// given; values whose prefix is not an enabled scheme, such as URLs, are
+// kept unchanged. Decoding is off by default, since existing values such
+// as "file:test.db" would otherwise change meaning. Files are never served
+// from the parse cache while decoding is enabled, since decoded content
+// may change between loads.
+func WithDecoders(schemes ...string) Option {
+	return func(o *options) {
+		o.parse.decoders = &decoderOptions{schemes: schemes}
+	}
+}
+
+// decode applies the Decoder for the scheme prefix of value, if it is
+// registered and enabled.
+func (p *parser) decode(key, value string) (string, error) {
+	if p.options.decoders == nil {
+		return value, nil
+	}
+
+	scheme, data, found := strings.Cut(value, ":")
+	if !found || !p.options.decoders.enabled(scheme) {
+		return value, nil
+	}
+	d, exists := lookupDecoder(scheme)
+	if !exists {
+		return value, nil
+	}
+
+	dir := ""
+	if len(p.stack) > 0 {
+		dir = filepath.Dir(p.stack[len(p.stack)-1])
+	}
+	decoded, err := d(data, dir)
+	if err != nil {
+		return "", fmt.Errorf("error: '%s' at line %d: unable to decode value of '%s' as %s: %v", p.source, p.lineNumber, key, scheme, err)
+	}
+	return decoded, nil
+}
+
+func decodeBase64(data, _ string) (string, error) {
+	data = strings.TrimSpace(data)
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(data); err == nil {
+			return string(decoded), nil
+		}
+	}
+	return "", fmt.Errorf("invalid base64 data")
+}
+
+func decodeFile(data, dir string) (string, error) {
+	path := filepath.FromSlash(strings.TrimSpace(data))
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(string(content), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
@@ -0,0 +1,120 @@
+package envfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrDecryption is wrapped by the error returned when a Decryptor fails.
+var ErrDecryption = errors.New("decryption failed")
+
+// Decryptor decrypts a whole file encrypted at rest, such as an age or
+// PGP file, and returns the plain content.
+type Decryptor func(r io.Reader) ([]byte, error)
+
+var (
+	decryptorsMu sync.RWMutex
+	decryptors   = make(map[string]Decryptor)
+)
+
+// RegisterDecryptor makes the Loader decrypt files whose name ends in
+// ext, such as ".age" or ".gpg", with d before parsing them. The file
+// name without ext then selects how the content is parsed, so
+// config.toml.age is read with the FileFormat registered for ".toml".
+//
+// Encrypted files also take part in the cascade of candidate files: a
+// candidate such as .env.production that does not exist is replaced by
+// .env.production.age if that file exists, so that encrypted files keep
+// the precedence of their plain names. The extension is matched
+// case-insensitively, and registering a nil d removes it.
+//
+//	envfile.RegisterDecryptor(".age", envfile.CommandDecryptor("age", "--decrypt", "-i", keyFile))
+//	envfile.RegisterDecryptor(".gpg", envfile.CommandDecryptor("gpg", "--quiet", "--batch", "--decrypt"))
+func RegisterDecryptor(ext string, d Decryptor) {
+	decryptorsMu.Lock()
+	defer decryptorsMu.Unlock()
+	ext = strings.ToLower(ext)
+	if d == nil {
+		delete(decryptors, ext)
+		return
+	}
+	decryptors[ext] = d
+}
+
+// CommandDecryptor returns a Decryptor that runs the named program with
+// args, passing the encrypted file on standard input and reading the
+// plain content from standard output. The program's standard error is
+// included in the error if it fails.
+func CommandDecryptor(name string, args ...string) Decryptor {
+	args = append([]string{}, args...)
+	return func(r io.Reader) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = r
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("%s: %v: %s", name, err, msg)
+			}
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return stdout.Bytes(), nil
+	}
+}
+
+// lookupDecryptor returns the decryptor registered for the extension of
+// filePath.
+func lookupDecryptor(filePath string) (Decryptor, bool) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == "" {
+		return nil, false
+	}
+	decryptorsMu.RLock()
+	defer decryptorsMu.RUnlock()
+	d, found := decryptors[ext]
+	return d, found
+}
+
+// decryptorExtensions returns the registered extensions, sorted.
+func decryptorExtensions() []string {
+	decryptorsMu.RLock()
+	defer decryptorsMu.RUnlock()
+	exts := make([]string, 0, len(decryptors))
+	for ext := range decryptors {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// parseEncrypted decrypts the file at filePath with d and parses the
+// content according to the name without its extension.
+func (l *Loader) parseEncrypted(filePath string, d Decryptor) ([]variable, error) {
+	encrypted, err := l.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := d(bytes.NewReader(encrypted))
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to decrypt '%s': %v: %w", filePath, err, ErrDecryption)
+	}
+
+	inner := strings.TrimSuffix(filePath, filepath.Ext(filePath))
+	if format, found := lookupFormat(inner); found {
+		return formatVariables(filePath, bytes.NewReader(content), format)
+	}
+	po, err := l.parseOpts()
+	if err != nil {
+		return nil, err
+	}
+	return parseData(content, filePath, po)
+}
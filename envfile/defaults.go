@@ -0,0 +1,96 @@
+package envfile
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	defaultsMu sync.RWMutex
+	defaults   = make(map[string]map[string]string)
+)
+
+// SetDefaults registers built-in values for the keys of profile, so that
+// a service can ship sane configuration in code. Load sets a default
+// when neither the loaded file, a Source nor the process environment
+// provides the key, even if no file could be loaded. LoadEnvironment and
+// Reloaders, which describe the files, do not include defaults.
+//
+// Defaults registered for the empty profile apply to every profile, with
+// lower precedence than those of the selected profile. Calling
+// SetDefaults again for a profile adds to its defaults, replacing the
+// values of keys given again. Defaults are subject to WithAllowedKeys,
+// WithDeniedKeys and Hooks like file variables, with "defaults" as the
+// source.
+func SetDefaults(profile string, values map[string]string) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	m := defaults[profile]
+	if m == nil {
+		m = make(map[string]string, len(values))
+		defaults[profile] = m
+	}
+	for key, value := range values {
+		m[key] = value
+	}
+}
+
+// ClearDefaults removes the defaults registered for every profile.
+func ClearDefaults() {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaults = make(map[string]map[string]string)
+}
+
+// defaultVariables returns the defaults of the selected profile for the
+// keys the process environment does not have, sorted by key.
+func (l *Loader) defaultVariables() []variable {
+	var variables []variable
+	for _, v := range l.registeredDefaults() {
+		if _, exists := lookupEnv(v.key, l.o.foldCase()); !exists {
+			variables = append(variables, v)
+		}
+	}
+	return variables
+}
+
+// registeredDefaults returns the defaults of the selected profile, sorted
+// by key.
+func (l *Loader) registeredDefaults() []variable {
+	profile, known := l.o.selectedProfile("")
+	if !known {
+		profile = "development"
+	}
+
+	defaultsMu.RLock()
+	values := make(map[string]string, len(defaults[""])+len(defaults[profile]))
+	for key, value := range defaults[""] {
+		values[key] = value
+	}
+	for key, value := range defaults[profile] {
+		values[key] = value
+	}
+	defaultsMu.RUnlock()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	variables := make([]variable, len(keys))
+	for i, key := range keys {
+		variables[i] = variable{key: key, value: values[key]}
+	}
+	return variables
+}
+
+// applyDefaults sets the defaults of keys the process environment does
+// not have.
+func (l *Loader) applyDefaults(result *Result) error {
+	variables := l.defaultVariables()
+	if len(variables) == 0 {
+		return nil
+	}
+	return l.apply("defaults", variables, result)
+}
@@ -0,0 +1,82 @@
+package envfile
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithDefinitions makes the $-prefixed template variables defined in the
+// file at filePath, such as .env.vars, available to the {$name}
+// references of every file and reader parsed afterwards, so that a
+// multi-file setup can keep shared values in one place:
+//
+//	# .env.vars
+//	$DOMAIN=example.com
+//
+//	# .env.production
+//	API_URL=https://api.{$DOMAIN}
+//
+//	envfile.Load(envfile.WithDefinitions(".env.vars"))
+//
+// The definitions file is read with the other options of the Loader and
+// may use #include. Only its template variables are used; its other keys
+// are ignored, and template variables defined in a file itself take
+// precedence over the definitions. A missing definitions file fails the
+// load with an error wrapping ErrIO.
+func WithDefinitions(filePath string) Option {
+	return func(o *options) {
+		o.definitions = filePath
+	}
+}
+
+// parseOpts returns the parse options of l, with the template
+// variables of the file set with WithDefinitions.
+func (l *Loader) parseOpts() (parseOptions, error) {
+	po := l.o.parse
+	if l.o.definitions == "" {
+		return po, nil
+	}
+	if err := l.checkFile(l.o.definitions); err != nil {
+		return po, err
+	}
+	_, p, err := parseFileIncludes(l.o.definitions, po)
+	if err != nil {
+		return po, err
+	}
+	for key := range p.variables {
+		if !strings.HasPrefix(key, "$") {
+			delete(p.variables, key)
+		}
+	}
+	po.globals = joinGlobals(p.variables)
+	return po, nil
+}
+
+// joinGlobals encodes variables as sorted "name NUL value NUL" pairs, so
+// that the options stay comparable and files parsed with different
+// definitions are cached separately.
+func joinGlobals(variables map[string]string) string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(variables[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// splitGlobals decodes the variables encoded by joinGlobals into
+// variables.
+func splitGlobals(globals string, variables map[string]string) {
+	fields := strings.Split(globals, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		variables[fields[i]] = fields[i+1]
+	}
+}
@@ -0,0 +1,155 @@
+package envfile
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DescribeStruct returns the keys decoded by Unmarshal into the struct v,
+// or the struct v points to, in field order. Each entry takes its key,
+// including the "__"-joined path of nested structs, from the `env` tag,
+// its default from the `default` tag, its description from the `doc`
+// tag, and its type from the field type:
+//
+//	type Config struct {
+//		Port    int           `env:"PORT" default:"8080" doc:"Port the HTTP server listens on."`
+//		Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+//		DSN     string        `env:"SENTRY_DSN,required"`
+//	}
+//
+// A key is required if it is tagged with the required option. Map fields
+// are not listed, since their keys are not known in advance.
+func DescribeStruct(v any) ([]ExampleEntry, error) {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("error: DescribeStruct requires a struct or a pointer to a struct, got %T", v)
+	}
+	return describeStruct(nil, rt), nil
+}
+
+func describeStruct(path []string, rt reflect.Type) []ExampleEntry {
+	var entries []ExampleEntry
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup("env")
+		if !tagged {
+			if isNestedType(field.Type) {
+				entries = append(entries, describeStruct(path, field.Type)...)
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		fieldPath := append(path[:len(path):len(path)], key)
+		if isNestedType(field.Type) {
+			entries = append(entries, describeStruct(fieldPath, field.Type)...)
+			continue
+		}
+		if field.Type.Kind() == reflect.Map {
+			continue
+		}
+
+		entry := ExampleEntry{
+			Key:         strings.Join(fieldPath, "__"),
+			Default:     field.Tag.Get("default"),
+			Type:        fieldTypeName(field.Type),
+			Description: field.Tag.Get("doc"),
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "required" {
+				entry.Required = true
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// isNestedType reports whether fields of type rt are decoded field by
+// field, like isNested.
+func isNestedType(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Struct && rt != urlType && !reflect.PointerTo(rt).Implements(textUnmarshalerType)
+}
+
+// fieldTypeName returns the declarable type of values decoded into rt, or
+// an empty string if none applies.
+func fieldTypeName(rt reflect.Type) string {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == durationType {
+		return "duration"
+	}
+	if reflect.PointerTo(rt).Implements(textUnmarshalerType) {
+		return ""
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return ""
+}
+
+// MarshalExample renders entries as an example file that ParseExample
+// reads back: each key is preceded by its description as comments, typed
+// keys are written as KEY:type=default, and "# @required" or
+// "# @optional" annotations are added where Required differs from what
+// the default implies.
+func MarshalExample(entries []ExampleEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, entry := range entries {
+		value, err := marshalValue(entry.Key, entry.Default)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		for _, line := range strings.Split(entry.Description, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(&buf, "# %s\n", line)
+			}
+		}
+		switch {
+		case entry.Required && entry.Default != "":
+			fmt.Fprintf(&buf, "# @required %s\n", entry.Key)
+		case !entry.Required && entry.Default == "":
+			fmt.Fprintf(&buf, "# @optional %s\n", entry.Key)
+		}
+		buf.WriteString(entry.Key)
+		if entry.Type != "" {
+			buf.WriteString(":" + entry.Type)
+		}
+		buf.WriteString("=" + value + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateExample renders the keys of the struct v, as described by
+// DescribeStruct, as an example file. Running it from go generate keeps
+// .env.example in sync with the configuration struct.
+func GenerateExample(v any) ([]byte, error) {
+	entries, err := DescribeStruct(v)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalExample(entries)
+}
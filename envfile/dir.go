@@ -0,0 +1,177 @@
+package envfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoadDir loads a directory in the daemontools envdir layout, where every
+// file is one variable: the file name is the key and the file content is
+// the value. Kubernetes mounts secrets and config maps in the same shape,
+// so a mounted volume can be loaded directly.
+//
+// A single trailing newline is removed from each value and NUL bytes are
+// turned into newlines, as envdir does. Unlike envdir, the remaining lines
+// are kept, so multi-line values such as certificates survive. An empty
+// file unsets the variable. Files whose names start with a dot, such as
+// the ..data links maintained by Kubernetes, and subdirectories are
+// ignored.
+func LoadDir(dir string, opts ...Option) (*Result, error) {
+	return New(opts...).LoadDir(dir)
+}
+
+// ReadDir is like LoadDir but returns the variables as an Environment
+// instead of setting them on the process environment. Empty files are
+// left out.
+func ReadDir(dir string, opts ...Option) (*Environment, error) {
+	return New(opts...).ReadDir(dir)
+}
+
+// LoadDir loads a directory in the envdir layout. See the package-level
+// LoadDir.
+func (l *Loader) LoadDir(dir string) (*Result, error) {
+	result := &Result{}
+	variables, unset, err := l.readDir(dir)
+	if err != nil {
+		l.o.hooks.error(dir, err)
+		return result, err
+	}
+	if err := l.apply(dir, variables, result); err != nil {
+		l.o.hooks.error(dir, err)
+		return result, err
+	}
+	if err := l.unset(dir, unset, result); err != nil {
+		l.o.hooks.error(dir, err)
+		return result, err
+	}
+	result.File = dir
+	result.Files = []string{dir}
+	return result, nil
+}
+
+// ReadDir reads a directory in the envdir layout into an Environment. See
+// the package-level ReadDir.
+func (l *Loader) ReadDir(dir string) (*Environment, error) {
+	variables, _, err := l.readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return l.newEnvironment(variables), nil
+}
+
+// readDir returns the variables defined by the files in dir, sorted by
+// key, and the keys of the empty files.
+func (l *Loader) readDir(dir string) ([]variable, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error: unable to read directory '%s': %w: %w", dir, err, ErrIO)
+	}
+
+	limits := l.o.parse.limits
+	var variables []variable
+	var unset []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		filePath := filepath.Join(dir, name)
+
+		// Stat rather than use the entry type, so that symlinked files
+		// are followed.
+		info, err := os.Stat(filePath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if limits.MaxFileSize > 0 && info.Size() > limits.MaxFileSize {
+			return nil, nil, limitError(filePath, 0, "file size %d exceeds %d bytes", info.Size(), limits.MaxFileSize)
+		}
+		if err := l.checkFile(filePath); err != nil {
+			return nil, nil, err
+		}
+
+		data, err := l.readFile(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(data) == 0 {
+			unset = append(unset, name)
+			continue
+		}
+
+		value := string(bytes.ReplaceAll(data, []byte{0}, []byte{'\n'}))
+		value = strings.TrimSuffix(value, "\n")
+		value = strings.TrimSuffix(value, "\r")
+		if limits.MaxValueLength > 0 && len(value) > limits.MaxValueLength {
+			return nil, nil, limitError(filePath, 0, "value length %d exceeds %d bytes", len(value), limits.MaxValueLength)
+		}
+		variables = append(variables, variable{key: name, value: value})
+	}
+
+	if limits.MaxVariables > 0 && len(variables) > limits.MaxVariables {
+		return nil, nil, limitError(dir, 0, "number of variables exceeds %d", limits.MaxVariables)
+	}
+	return variables, unset, nil
+}
+
+// unset removes keys from the process environment, subject to the
+// configured key filters and override policy.
+func (l *Loader) unset(source string, keys []string, result *Result) error {
+	o := l.o
+
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+
+	for _, key := range keys {
+		if reason, denied := o.keys.denied(key, o.foldCase()); denied {
+			result.Denied = append(result.Denied, key)
+			o.hooks.skip(key, source, reason)
+			continue
+		}
+		if o.keepsOS(source, result) {
+			continue
+		}
+		if _, exists := os.LookupEnv(key); !exists {
+			continue
+		}
+		loadedRestorePoint.record(key)
+		if err := os.Unsetenv(key); err != nil {
+			return fmt.Errorf("error: unable to unset environment variable '%s': %v", key, err)
+		}
+	}
+	return nil
+}
+
+// WriteDir writes env to dir in the envdir layout read by LoadDir, one
+// file per variable, creating dir if needed. New files are written with
+// mode 0600 since the values are often secrets, each file is replaced
+// atomically, and each value is followed by a newline. Existing files for
+// other keys are left in place.
+func WriteDir(dir string, env *Environment) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("error: unable to create directory '%s': %w: %w", dir, err, ErrIO)
+	}
+
+	keys := env.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "" || strings.HasPrefix(key, ".") || strings.ContainsAny(key, `/\`) {
+			return fmt.Errorf("error: key '%s' cannot be used as a file name", key)
+		}
+		value := env.Get(key)
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("error: value of '%s' contains a NUL byte", key)
+		}
+		// LoadDir removes one trailing newline, so always adding one
+		// round-trips every value, including empty ones.
+		filePath := filepath.Join(dir, key)
+		if err := writeFileAtomic(filePath, []byte(value+"\n"), 0o600, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
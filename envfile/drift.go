@@ -0,0 +1,89 @@
+package envfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Drift compares e with the process environment and returns the keys of
+// e whose process value differs, in the order of e. In each Change, Old
+// is the value in e and New the process value; Removed is set for keys
+// that are not set in the process at all. Process variables that e does
+// not define are not reported.
+//
+// Drift is meant for health checks that detect a process started with
+// stale configuration: a non-empty result means the file changed, or
+// something else set the variables, after the process loaded them.
+func (e *Environment) Drift() []Change {
+	var changes []Change
+	for _, key := range e.keysOrNil() {
+		value := e.values[key]
+		current, exists := lookupEnv(key, e.foldCase)
+		switch {
+		case !exists:
+			changes = append(changes, Change{Key: key, Old: value, Removed: true})
+		case current != value:
+			changes = append(changes, Change{Key: key, Old: value, New: current})
+		}
+	}
+	return changes
+}
+
+// CheckDrift reads the file LoadEnvironment would select with opts and
+// returns its Drift from the process environment.
+func CheckDrift(opts ...Option) ([]Change, error) {
+	return New(opts...).CheckDrift()
+}
+
+// CheckDrift reads the environment with l and returns its Drift from the
+// process environment.
+func (l *Loader) CheckDrift() ([]Change, error) {
+	env, err := l.Environment()
+	if err != nil {
+		return nil, err
+	}
+	return env.Drift(), nil
+}
+
+// DriftError reports configuration drift found by a health check.
+type DriftError struct {
+	Changes []Change
+}
+
+// Error lists the drifted keys without their values, which may be
+// secrets.
+func (e *DriftError) Error() string {
+	var missing, changed []string
+	for _, c := range e.Changes {
+		if c.Removed {
+			missing = append(missing, c.Key)
+		} else {
+			changed = append(changed, c.Key)
+		}
+	}
+	var parts []string
+	if len(changed) > 0 {
+		parts = append(parts, fmt.Sprintf("keys set to different values: %s", strings.Join(changed, ", ")))
+	}
+	if len(missing) > 0 {
+		parts = append(parts, fmt.Sprintf("keys missing from the process: %s", strings.Join(missing, ", ")))
+	}
+	return "error: configuration drift: " + strings.Join(parts, "; ")
+}
+
+// DriftCheck returns nil if the file LoadEnvironment would select with
+// opts matches the process environment, a *DriftError if it does not, or
+// the error reading the file. Its signature suits health check
+// registries:
+//
+//	health.Register("config", func() error { return envfile.DriftCheck() })
+func DriftCheck(opts ...Option) error {
+	changes, err := CheckDrift(opts...)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		return &DriftError{Changes: changes}
+	}
+	return nil
+}
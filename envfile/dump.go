@@ -0,0 +1,67 @@
+package envfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WithUnsafeDump makes DumpEffective and WriteEffective write secret
+// values as they are instead of masking them.
+func WithUnsafeDump() Option {
+	return func(o *options) {
+		o.unsafeDump = true
+	}
+}
+
+// DumpEffective writes the effective configuration, the variables that
+// NewEnvironment with opts returns, fully interpolated and merged, to the
+// file at path as env file content, so that a failing deployment can
+// attach its exact configuration to a bug report. Values that
+// DefaultDetector classifies as secrets are masked unless WithUnsafeDump
+// is set. The file is created readable only by its owner, and replaced
+// atomically if it exists, keeping its previous content with WithBackup.
+func DumpEffective(path string, opts ...Option) error {
+	return New(opts...).DumpEffective(path)
+}
+
+// DumpEffective writes the effective configuration to the file at path.
+// See the package-level DumpEffective.
+func (l *Loader) DumpEffective(path string) error {
+	var buf bytes.Buffer
+	if err := l.WriteEffective(&buf); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0o600, l.o.backup)
+}
+
+// WriteEffective writes the effective configuration to w, like
+// DumpEffective, preceded by comments recording when it was written, the
+// profile and whether secrets are masked.
+func (l *Loader) WriteEffective(w io.Writer) error {
+	env, err := l.NewEnvironment()
+	if err != nil {
+		return err
+	}
+	masking := "Secret values are masked."
+	if l.o.unsafeDump {
+		masking = "Secret values are NOT masked."
+	} else {
+		env = env.Masked()
+	}
+	content, err := env.Marshal()
+	if err != nil {
+		return err
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "# Effective environment written at %s.\n", time.Now().Format(time.RFC3339))
+	if profile := l.o.resultProfile(); profile != "" {
+		fmt.Fprintf(&header, "# Profile: %s\n", profile)
+	}
+	fmt.Fprintf(&header, "# %s\n\n", masking)
+	header.Write(content)
+	_, err = w.Write(header.Bytes())
+	return err
+}
@@ -0,0 +1,163 @@
+package envfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Set sets key to value in the env file at filePath, creating the file
+// with mode 0600 if it does not exist. The last unconditional definition
+// of key is updated in place, keeping its indentation, type suffix and
+// trailing comment; if there is none, a KEY=value line is appended. The
+// rest of the file, including comments and line endings, is left as it
+// is.
+//
+// The file is locked with LockFile while it is updated, and replaced
+// atomically, keeping its mode; opts may include WithBackup to keep its
+// previous content. A value containing spaces, or that would not read
+// back unchanged as written, such as one containing a '#' or a line
+// break, is double-quoted as Format writes it, with backslashes, quotes
+// and line breaks escaped. Set fails if value cannot be written so that
+// it reads back unchanged even quoted, or if it is invalid for a type
+// declared for key in the file.
+func Set(filePath, key, value string, opts ...Option) error {
+	value, err := marshalValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	lock, err := LockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+
+	updated, err := setValue(content, key, value)
+	if err != nil {
+		return fmt.Errorf("error: '%s': %w", filePath, err)
+	}
+	return WriteFileAtomic(filePath, updated, 0o600, opts...)
+}
+
+// setValue returns content with key set to value, written as is, as
+// described for Set.
+func setValue(content []byte, key, value string) ([]byte, error) {
+	newline := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+
+	target := -1
+	definitions, typ := definitionLines(lines, key)
+	if len(definitions) > 0 {
+		target = definitions[len(definitions)-1]
+	}
+
+	if typ != "" {
+		if err := checkType(typ, unquote(value)); err != nil {
+			return nil, fmt.Errorf("value of '%s': %v", key, err)
+		}
+	}
+
+	if target == -1 {
+		var buf bytes.Buffer
+		buf.Write(content)
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+			buf.WriteString(newline)
+		}
+		buf.WriteString(key + "=" + value + newline)
+		return buf.Bytes(), nil
+	}
+
+	line := lines[target]
+	body := strings.TrimRight(line, "\r\n")
+	ending := line[len(body):]
+	// Replace the lines continuing the old value along with it.
+	end := target + 1
+	for continues := continuesLine(body); continues && end < len(lines); end++ {
+		continues = continuesLine(strings.TrimRight(lines[end], "\r\n"))
+	}
+	if end > target+1 {
+		body = body[:len(body)-1]
+		lines = append(lines[:target+1], lines[end:]...)
+	}
+	index := strings.Index(body, "=")
+	if index == -1 {
+		// A bare KEY line defines an empty value.
+		index = len(strings.TrimRight(clearAfterHash(body), " \t"))
+		body = body[:index] + "=" + body[index:]
+	}
+	rest := body[index+1:]
+	var comment string
+	if stripped := clearAfterHash(body); len(stripped) < len(body) {
+		hash := len(stripped) - index - 1
+		comment = rest[hash:]
+		// Keep the spacing between the value and the comment.
+		before := rest[:hash]
+		comment = before[len(strings.TrimRight(before, " \t")):] + comment
+		if comment[0] == '#' {
+			comment = " " + comment
+		}
+	}
+	// Keep the spacing after a spaced '=', as in "KEY = value".
+	var spacing string
+	if strings.TrimSpace(clearAfterHash(rest)) != "" {
+		spacing = rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+	}
+	lines[target] = body[:index+1] + spacing + value + comment + ending
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// definitionLines returns the indexes of the lines that define key
+// unconditionally, in order, and the type declared for key, if any.
+// Lines continuing a value are never definitions.
+func definitionLines(lines []string, key string) ([]int, string) {
+	var definitions []int
+	var typ string
+	depth := 0
+	continued := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		continuation := continued
+		continued = continuesLine(strings.TrimRight(line, "\r\n"))
+		if continuation {
+			continue
+		}
+		directive, _, _ := conditionalDirective(trimmed)
+		switch directive {
+		case "#if":
+			depth++
+			continue
+		case "#endif":
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if name, args, ok := parseAnnotation(trimmed); ok && name == "type" && len(args) == 2 && args[0] == key {
+			typ = args[1]
+			continue
+		}
+		if depth > 0 || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "@") {
+			continue
+		}
+		k, _ := splitLine(clearAfterHash(trimmed))
+		name, declared := splitKeyType(strings.TrimSpace(k))
+		if name == key {
+			definitions = append(definitions, i)
+			if declared != "" {
+				typ = declared
+			}
+		}
+	}
+	return definitions, typ
+}
@@ -0,0 +1,105 @@
+package envfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// encodingSniffLength is the number of bytes inspected to guess the
+// encoding of content without a byte order mark.
+const encodingSniffLength = 512
+
+// detectUTF16 reports the byte order of UTF-16 content, recognized by its
+// byte order mark or, failing that, by the zero bytes that ASCII
+// characters leave in every other position. It returns nil for other
+// content.
+func detectUTF16(data []byte) binary.ByteOrder {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return binary.BigEndian
+	}
+
+	sample := data[:min(len(data), encodingSniffLength)]
+	if len(sample) < 4 || utf8.Valid(sample) && bytes.IndexByte(sample, 0) == -1 {
+		return nil
+	}
+	var evenZeros, oddZeros int
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZeros++
+		}
+		if sample[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	// Mostly-ASCII text has a zero high byte in most code units.
+	pairs := len(sample) / 2
+	switch {
+	case oddZeros*2 > pairs && evenZeros*8 < pairs:
+		return binary.LittleEndian
+	case evenZeros*2 > pairs && oddZeros*8 < pairs:
+		return binary.BigEndian
+	}
+	return nil
+}
+
+// toUTF8 transcodes UTF-16 content to UTF-8 and returns other content
+// unchanged. UTF-32 content, recognized by its byte order mark, is
+// refused with an error naming the encoding instead of being parsed into
+// garbled keys.
+func toUTF8(data []byte, source string) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe, 0, 0}) || bytes.HasPrefix(data, []byte{0, 0, 0xfe, 0xff}) {
+		return nil, fmt.Errorf("error: '%s' is encoded as UTF-32, which is not supported; convert it to UTF-8: %w", source, ErrSyntax)
+	}
+
+	order := detectUTF16(data)
+	if order == nil {
+		return data, nil
+	}
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("error: '%s' looks like UTF-16 but has an odd number of bytes: %w", source, ErrSyntax)
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	if len(units) > 0 && units[0] == 0xfeff {
+		units = units[1:]
+	}
+	runes := utf16.Decode(units)
+
+	buf := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf, nil
+}
+
+// readerToUTF8 returns a reader of r transcoded to UTF-8 if r holds
+// UTF-16 content, reading it entirely in that case only.
+func readerToUTF8(r io.Reader, source string) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, encodingSniffLength)
+	// A short read is reported as an error; the bytes peeked are enough.
+	peeked, _ := br.Peek(encodingSniffLength)
+	utf32 := bytes.HasPrefix(peeked, []byte{0xff, 0xfe, 0, 0}) || bytes.HasPrefix(peeked, []byte{0, 0, 0xfe, 0xff})
+	if !utf32 && detectUTF16(peeked) == nil {
+		return br, nil
+	}
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	data, err = toUTF8(data, source)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,62 @@
+// Package envfiletest provides helpers for using .env files in tests.
+//
+// Variables are set with t.Setenv, so the testing package restores the
+// previous environment automatically when each test finishes. Because
+// t.Setenv cannot be used in parallel tests, neither can these helpers.
+package envfiletest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// LoadForTest reads the .env file at path and sets each of its variables
+// with t.Setenv for the duration of the test. The test fails immediately
+// if the file cannot be read. The parsed Environment is returned for
+// convenience.
+func LoadForTest(t testing.TB, path string) *envfile.Environment {
+	t.Helper()
+
+	env, err := envfile.Read(path)
+	if err != nil {
+		t.Fatalf("envfiletest: unable to read '%s': %v", path, err)
+	}
+
+	for _, key := range env.Keys() {
+		t.Setenv(key, env.Get(key))
+	}
+
+	return env
+}
+
+// Isolate snapshots the entire process environment and restores it when
+// the test finishes, undoing changes that t.Setenv does not track, such
+// as calls to os.Setenv or envfile.Load in the code under test. The test
+// fails if the environment cannot be restored.
+func Isolate(t testing.TB) {
+	t.Helper()
+
+	snapshot := envfile.Snapshot()
+	t.Cleanup(func() {
+		if err := envfile.Restore(snapshot); err != nil {
+			t.Errorf("envfiletest: unable to restore the environment: %v", err)
+		}
+	})
+}
+
+// WithTempEnvFile writes content to a new .env file inside t.TempDir()
+// and returns its path. The file is removed together with the temporary
+// directory when the test finishes.
+func WithTempEnvFile(t testing.TB, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("envfiletest: unable to write '%s': %v", path, err)
+	}
+
+	return path
+}
@@ -0,0 +1,133 @@
+// Package envhttp serves the effective configuration of a process over
+// HTTP, so that sidecars and debugging tools can query it:
+//
+//	reloader := envfile.NewReloader(true)
+//	http.Handle("/debug/env", &envhttp.Handler{
+//		Environment: reloader.Environment,
+//		Token:       os.Getenv("ENV_DEBUG_TOKEN"),
+//	})
+//
+// Requests must carry the token as "Authorization: Bearer TOKEN". Values
+// that look like secrets are masked unless configured otherwise.
+//
+// GET returns every variable, in definition order, as
+//
+//	{"variables": [{"key": "PORT", "value": "8080"}, {"key": "API_TOKEN", "value": "********", "masked": true}]}
+//
+// and GET with a "key" query parameter returns the single entry
+// {"key": "PORT", "value": "8080"}, or status 404 if the key is not set.
+package envhttp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// Handler serves an Environment as JSON.
+type Handler struct {
+	// Environment returns the environment to serve, such as the
+	// Environment method of an envfile.Reloader. It is called for every
+	// request; a nil result is served as an empty environment.
+	Environment func() *envfile.Environment
+	// Token is the bearer token requests must present. If it is empty,
+	// every request is refused, so that configuration is never served
+	// unauthenticated by accident.
+	Token string
+	// Detector classifies the values to mask. It defaults to
+	// envfile.DefaultDetector.
+	Detector *envfile.Detector
+	// Mask lists path.Match patterns of keys whose values are always
+	// masked. Patterns are matched with envfile.MatchKey.
+	Mask []string
+	// Reveal lists path.Match patterns of keys whose values are never
+	// masked by Detector. Mask takes precedence.
+	Reveal []string
+}
+
+// entry is the JSON form of a variable.
+type entry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Masked bool   `json:"masked,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="envfile"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var env *envfile.Environment
+	if h.Environment != nil {
+		env = h.Environment()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if key := r.URL.Query().Get("key"); key != "" {
+		var value string
+		exists := false
+		if env != nil {
+			value, exists = env.Lookup(key)
+		}
+		if !exists {
+			http.Error(w, "key not set", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(h.entry(key, value))
+		return
+	}
+
+	response := struct {
+		Variables []entry `json:"variables"`
+	}{Variables: []entry{}}
+	if env != nil {
+		for _, e := range env.Entries() {
+			response.Variables = append(response.Variables, h.entry(e.Key, e.Value))
+		}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// authorized reports whether r carries the configured token.
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.Token == "" {
+		return false
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) == 1
+}
+
+// entry returns the variable as served, masked if the rules say so.
+func (h *Handler) entry(key, value string) entry {
+	if h.masked(key, value) {
+		return entry{Key: key, Value: envfile.Mask, Masked: true}
+	}
+	return entry{Key: key, Value: value}
+}
+
+func (h *Handler) masked(key, value string) bool {
+	if envfile.MatchKey(key, h.Mask...) {
+		return true
+	}
+	if envfile.MatchKey(key, h.Reveal...) {
+		return false
+	}
+	detector := h.Detector
+	if detector == nil {
+		detector = envfile.DefaultDetector
+	}
+	return detector.IsSecret(key, value)
+}
@@ -0,0 +1,189 @@
+package envfile
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Environment is an immutable snapshot of variables parsed from one or
+// more .env files. It never touches the process environment, and because
+// it cannot be modified after construction, it is safe to share between
+// goroutines without additional locking.
+type Environment struct {
+	values map[string]string
+	keys   []string
+	types  map[string]string
+	// foldCase reports whether keys were folded case-insensitively, which
+	// also makes merging into a process environment case-insensitive.
+	foldCase bool
+	// folded maps upper-cased keys to their spelling when foldCase is
+	// set.
+	folded map[string]string
+	// usage records the keys read, on Environments returned by Tracked.
+	usage *usage
+}
+
+// newEnvironment builds an Environment from variables in file order. When
+// a key appears more than once, the last value wins while the key keeps
+// the position of its first occurrence.
+func newEnvironment(variables []variable) *Environment {
+	e := &Environment{
+		values: make(map[string]string, len(variables)),
+		keys:   make([]string, 0, len(variables)),
+		types:  make(map[string]string),
+	}
+	for _, v := range variables {
+		if _, exists := e.values[v.key]; !exists {
+			e.keys = append(e.keys, v.key)
+		}
+		e.values[v.key] = v.value
+		if v.typ != "" {
+			e.types[v.key] = v.typ
+		}
+	}
+	return e
+}
+
+// Read parses the given .env files and returns their merged variables as
+// an Environment without modifying the process environment. Files are
+// merged in the order given, so values in later files override values in
+// earlier ones.
+func Read(filenames ...string) (*Environment, error) {
+	return New().Read(filenames...)
+}
+
+// LoadEnvironment selects a .env file using the same rules as Load, but
+// returns its variables as an Environment instead of setting them on the
+// process environment.
+func LoadEnvironment(opts ...Option) (*Environment, error) {
+	return New(opts...).Environment()
+}
+
+// Parse reads env file content from r and returns its variables as an
+// Environment without modifying the process environment. Only Options
+// that affect parsing, such as WithLimits, have an effect.
+func Parse(r io.Reader, opts ...Option) (*Environment, error) {
+	return New(opts...).Parse(r)
+}
+
+// ParseBytes is like Parse for content already in memory, such as an
+// embedded file. It avoids copying the content line by line and is the
+// fastest way to parse large generated files.
+func ParseBytes(data []byte, opts ...Option) (*Environment, error) {
+	return New(opts...).ParseBytes(data)
+}
+
+// Get returns the value of key, or an empty string if it is not set.
+func (e *Environment) Get(key string) string {
+	value, _ := e.Lookup(key)
+	return value
+}
+
+// Lookup returns the value of key and whether it is set. If the
+// Environment was read with case-insensitive keys, key matches in any
+// case.
+func (e *Environment) Lookup(key string) (string, bool) {
+	value, exists := e.values[key]
+	if !exists && e.folded != nil {
+		if k, found := e.folded[strings.ToUpper(key)]; found {
+			value, exists = e.values[k]
+			key = k
+		}
+	}
+	if exists && e.usage != nil {
+		e.usage.record(key)
+	}
+	return value, exists
+}
+
+// Keys returns the keys of the environment in the order they were first
+// defined.
+func (e *Environment) Keys() []string {
+	keys := make([]string, len(e.keys))
+	copy(keys, e.keys)
+	return keys
+}
+
+// Entries returns the variables of the environment in the order their
+// keys were first defined, each with its final value. Unlike Map, the
+// result has a stable order, so it suits generated output and
+// deterministic iteration. Source is not set.
+func (e *Environment) Entries() []Entry {
+	entries := make([]Entry, len(e.keys))
+	for i, key := range e.keys {
+		entries[i] = Entry{Key: key, Value: e.values[key]}
+	}
+	return entries
+}
+
+// Len returns the number of variables in the environment.
+func (e *Environment) Len() int {
+	return len(e.keys)
+}
+
+// Map returns a copy of the environment's variables.
+func (e *Environment) Map() map[string]string {
+	m := make(map[string]string, len(e.values))
+	for k, v := range e.values {
+		m[k] = v
+	}
+	return m
+}
+
+// With returns a copy of e in which the variables of overrides are set,
+// replacing the values of existing keys and adding the others after the
+// keys of e in sorted order. e itself, the process environment and any
+// other copy are unchanged, which lets tests and request-scoped
+// sandboxes override a few keys of a shared snapshot:
+//
+//	env := base.With(map[string]string{"FEATURE_X": "true"})
+//	handler := newHandler(env)
+//
+// Overridden keys lose the type declared for them in the file. If e was
+// read with case-insensitive keys, an override replaces the existing key
+// that matches it in any case. If e is tracked, the keys read from the
+// copy are recorded on e as well.
+func (e *Environment) With(overrides map[string]string) *Environment {
+	derived := &Environment{
+		values:   make(map[string]string, len(e.values)+len(overrides)),
+		keys:     e.Keys(),
+		types:    make(map[string]string, len(e.types)),
+		foldCase: e.foldCase,
+		usage:    e.usage,
+	}
+	for key, value := range e.values {
+		derived.values[key] = value
+	}
+	for key, typ := range e.types {
+		derived.types[key] = typ
+	}
+	if e.folded != nil {
+		derived.folded = make(map[string]string, len(e.folded)+len(overrides))
+		for upper, key := range e.folded {
+			derived.folded[upper] = key
+		}
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := overrides[key]
+		if derived.folded != nil {
+			if existing, found := derived.folded[strings.ToUpper(key)]; found {
+				key = existing
+			} else {
+				derived.folded[strings.ToUpper(key)] = key
+			}
+		}
+		if _, exists := derived.values[key]; !exists {
+			derived.keys = append(derived.keys, key)
+		}
+		derived.values[key] = value
+		delete(derived.types, key)
+	}
+	return derived
+}
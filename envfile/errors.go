@@ -0,0 +1,30 @@
+package envfile
+
+import "errors"
+
+// The errors returned while reading and parsing env files wrap one of
+// these sentinels, alongside ErrLimitExceeded, ErrExpired, ErrDecryption
+// and ErrVerification, so that callers can branch on the kind of failure:
+//
+//	if errors.Is(err, envfile.ErrSyntax) {
+//		// report the broken line to the user
+//	}
+var (
+	// ErrSyntax is wrapped by errors for malformed content, such as an
+	// empty key in strict mode, an invalid annotation, an unbalanced #if
+	// or an include cycle.
+	ErrSyntax = errors.New("syntax error")
+
+	// ErrUnresolvedVar is wrapped by the error returned in strict mode for
+	// a {$name} reference that cannot be resolved.
+	ErrUnresolvedVar = errors.New("unresolved variable")
+
+	// ErrDuplicateKey is wrapped by the error returned for a repeated key
+	// when WithUniqueKeys is set.
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrIO is wrapped by errors for files and directories that cannot be
+	// read or written. The underlying error is wrapped as well, so
+	// errors.Is(err, fs.ErrNotExist) also reports a missing file.
+	ErrIO = errors.New("I/O error")
+)
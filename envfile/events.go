@@ -0,0 +1,103 @@
+package envfile
+
+import "fmt"
+
+// EventKind classifies the events delivered to an EventHandler.
+type EventKind int
+
+const (
+	// EventUnresolved is a {$name} reference that cannot be resolved and
+	// is replaced by an empty string.
+	EventUnresolved EventKind = iota
+	// EventSkippedLine is a line that is not a valid definition, such as
+	// one with an empty key, and is skipped.
+	EventSkippedLine
+	// EventTruncated is a value that looks truncated by the format, such
+	// as one cut at a '#' or opened with a quote that is not closed.
+	EventTruncated
+	// EventExpired is a variable whose "# @expires" date has passed.
+	EventExpired
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventUnresolved:
+		return "unresolved"
+	case EventSkippedLine:
+		return "skipped-line"
+	case EventTruncated:
+		return "truncated"
+	case EventExpired:
+		return "expired"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is a problem that parsing logs as a warning and otherwise
+// tolerates, delivered to the handler set with WithEventHandler.
+type Event struct {
+	Kind EventKind
+	// Source is the file or reader the event comes from, and Line the
+	// number of its line, or 0 if the event concerns no single line.
+	Source string
+	Line   int
+	// Key is the variable the event concerns, or for EventUnresolved the
+	// reference, such as "{$HOST}". It is empty for skipped lines.
+	Key string
+	// Message is the text of the warning.
+	Message string
+}
+
+// EventHandler receives events while files are parsed. Returning a
+// non-nil error turns the event into a failure: parsing stops with an
+// error, located at the line of the event, that wraps it.
+type EventHandler func(Event) error
+
+// WithEventHandler delivers every warning about an unresolved reference,
+// a skipped line, a truncated value or an expired variable to handler as
+// an Event, in addition to logging it, so that applications can turn
+// them into metrics, error reports or, by returning an error, hard
+// failures according to their own policy:
+//
+//	envfile.Load(envfile.WithEventHandler(func(e envfile.Event) error {
+//		if e.Kind == envfile.EventUnresolved && os.Getenv("APP_ENV") == "production" {
+//			return errors.New(e.Message)
+//		}
+//		unresolvedRefs.Inc()
+//		return nil
+//	}))
+//
+// Files are not served from the parse cache while a handler is set, so
+// that the events are delivered on every load.
+func WithEventHandler(handler EventHandler) Option {
+	return func(o *options) {
+		if handler == nil {
+			o.parse.events = nil
+			return
+		}
+		o.parse.events = &eventOptions{handler: handler}
+	}
+}
+
+// eventOptions holds the handler set with WithEventHandler behind a
+// pointer, so that parseOptions stays comparable.
+type eventOptions struct {
+	handler EventHandler
+}
+
+// warn logs the warning message and delivers it as an event of kind
+// about key, returning the error of the handler.
+func (po parseOptions) warn(kind EventKind, source string, line int, key, message string) error {
+	po.logf("Warning: %s", message)
+	if po.events == nil {
+		return nil
+	}
+	err := po.events.handler(Event{Kind: kind, Source: source, Line: line, Key: key, Message: message})
+	if err == nil {
+		return nil
+	}
+	if line > 0 {
+		return fmt.Errorf("error: '%s' at line %d: %w", source, line, err)
+	}
+	return fmt.Errorf("error: '%s': %w", source, err)
+}
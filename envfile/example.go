@@ -0,0 +1,144 @@
+package envfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ExampleEntry describes a key listed in an example file such as
+// .env.example.
+type ExampleEntry struct {
+	Key string
+	// Default is the value given in the example, without its quotes, or
+	// empty.
+	Default string
+	// Type is the type declared with KEY:type=value or "# @type", or
+	// empty.
+	Type string
+	// Description is the text of the comment lines directly above the
+	// key, or of a comment after the value.
+	Description string
+	// Required reports whether the key must be set. A key is required if
+	// it has no default, unless annotated with "# @optional KEY"; a
+	// "# @required KEY" annotation makes a key with a default required.
+	Required bool
+}
+
+// ReadExample reads the example file at filePath. See ParseExample.
+func ReadExample(filePath string) ([]ExampleEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to open file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	defer file.Close()
+	return ParseExample(file)
+}
+
+// ParseExample reads an example file documenting the keys an application
+// expects, in the order they are listed:
+//
+//	# Port the HTTP server listens on.
+//	PORT:int=8080
+//
+//	# @type TIMEOUT duration
+//	TIMEOUT=30s # request timeout
+//
+//	# @optional SENTRY_DSN
+//	SENTRY_DSN=
+//
+// Comment lines directly above a key describe it, and its value is the
+// default. Unlike parsing, ParseExample keeps the raw text: directives,
+// conditional sections and template variables are not evaluated, and
+// every branch of an #if block is listed.
+func ParseExample(r io.Reader) ([]ExampleEntry, error) {
+	var entries []ExampleEntry
+	index := make(map[string]int)
+	types := make(map[string]string)
+	required := make(map[string]bool)
+	var comments []string
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if name, args, ok := parseAnnotation(line); ok {
+			switch {
+			case name == "type" && len(args) == 2:
+				types[args[0]] = args[1]
+			case name == "required" && len(args) == 1:
+				required[args[0]] = true
+			case name == "optional" && len(args) == 1:
+				required[args[0]] = false
+			}
+			continue
+		}
+		if _, ok := parseInclude(line); ok {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if _, _, ok := conditionalDirective(line); ok {
+				continue
+			}
+			comments = append(comments, strings.TrimSpace(line[1:]))
+			continue
+		}
+		if line == "" {
+			comments = nil
+			continue
+		}
+
+		content := clearAfterHash(line)
+		comment := strings.TrimPrefix(line[len(content):], "#")
+		if _, rest, ok := splitProfilePrefix(strings.TrimSpace(content)); ok {
+			content = rest
+		}
+		key, value := splitLine(strings.TrimSpace(content))
+		key, typ := splitKeyType(key)
+		key, _ = splitListKey(key)
+		if key == "" || key[0] == '$' {
+			comments = nil
+			continue
+		}
+
+		description := strings.Join(comments, "\n")
+		if description == "" {
+			description = strings.TrimSpace(comment)
+		}
+		comments = nil
+
+		entry := ExampleEntry{Key: key, Default: unquote(strings.TrimSpace(value)), Type: typ, Description: description}
+		if i, exists := index[key]; exists {
+			// Keep the first position, but fill in what it lacks.
+			if entries[i].Type == "" {
+				entries[i].Type = entry.Type
+			}
+			if entries[i].Description == "" {
+				entries[i].Description = entry.Description
+			}
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error: failed to read example: %w: %w", err, ErrIO)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if typ, declared := types[entry.Key]; declared && entry.Type == "" {
+			entry.Type = typ
+		}
+		entry.Required = entry.Default == ""
+		if r, annotated := required[entry.Key]; annotated {
+			entry.Required = r
+		}
+	}
+	return entries, nil
+}
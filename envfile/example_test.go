@@ -0,0 +1,40 @@
+package envfile_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestGenerateExampleRoundTrip(t *testing.T) {
+	type config struct {
+		Port    int           `env:"PORT" default:"8080" doc:"Port the HTTP server listens on."`
+		Workers int           `env:"WORKERS"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Debug   bool          `env:"DEBUG" default:"false"`
+		Name    string        `env:"NAME" default:"my app"`
+		DSN     string        `env:"SENTRY_DSN,required"`
+	}
+	want, err := envfile.DescribeStruct(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	example, err := envfile.GenerateExample(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := envfile.ParseBytes(example); err != nil {
+		t.Fatalf("parsing %q: %v", example, err)
+	}
+	got, err := envfile.ParseExample(bytes.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("read back %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package envfile
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Environ returns the current process environment merged with the
+// variables of e, in the "key=value" form used by os.Environ and
+// exec.Cmd.Env. Variables in e override process variables with the same
+// key. The process environment itself is left untouched.
+func (e *Environment) Environ() []string {
+	return e.mergeEnviron(os.Environ())
+}
+
+// ApplyTo merges the variables of e into the environment of cmd without
+// setting them on the parent process. If cmd.Env is nil, the merge starts
+// from the current process environment, which is what the child would
+// otherwise inherit.
+func (e *Environment) ApplyTo(cmd *exec.Cmd) {
+	base := cmd.Env
+	if base == nil {
+		base = os.Environ()
+	}
+	cmd.Env = e.mergeEnviron(base)
+}
+
+// DefaultCleanAllowlist lists the process variables a child typically
+// needs to run at all, for use with CleanEnviron and ApplyCleanTo. It
+// includes the variables Windows programs require, such as SYSTEMROOT.
+var DefaultCleanAllowlist = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TMPDIR", "TZ", "LANG", "LC_*",
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP",
+	"USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// CleanEnviron returns the variables of e, in the "key=value" form used
+// by exec.Cmd.Env, together with only those process variables whose keys
+// match one of the allow patterns, such as DefaultCleanAllowlist. It lets
+// a child run with exactly the configuration of the env files, as in CI,
+// instead of inheriting the whole parent environment. Patterns use
+// path.Match syntax and are matched case-insensitively if e was built
+// with case-insensitive keys. Variables in e override allowed process
+// variables with the same key.
+func (e *Environment) CleanEnviron(allow ...string) []string {
+	var base []string
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if matchesAny(allow, key, e.foldCase) {
+			base = append(base, kv)
+		}
+	}
+	return e.mergeEnviron(base)
+}
+
+// ApplyCleanTo sets the environment of cmd to CleanEnviron(allow...),
+// replacing any environment already set on cmd.
+func (e *Environment) ApplyCleanTo(cmd *exec.Cmd, allow ...string) {
+	cmd.Env = e.CleanEnviron(allow...)
+}
+
+// mergeEnviron returns a copy of base in which entries whose key is
+// defined in e are replaced, followed by the remaining variables of e in
+// definition order. Keys are matched case-insensitively if e was built
+// with case-insensitive keys.
+func (e *Environment) mergeEnviron(base []string) []string {
+	result := make([]string, 0, len(base)+len(e.keys))
+	seen := make(map[string]struct{}, len(e.keys))
+
+	var folded map[string]string
+	if e.foldCase {
+		folded = make(map[string]string, len(e.keys))
+		for _, key := range e.keys {
+			folded[strings.ToUpper(key)] = key
+		}
+	}
+
+	for _, kv := range base {
+		key := kv
+		if index := strings.Index(kv, "="); index != -1 {
+			key = kv[:index]
+		}
+		if folded != nil {
+			if k, exists := folded[strings.ToUpper(key)]; exists {
+				key = k
+			}
+		}
+		if value, exists := e.values[key]; exists {
+			if _, done := seen[key]; done {
+				continue
+			}
+			seen[key] = struct{}{}
+			result = append(result, key+"="+value)
+			continue
+		}
+		result = append(result, kv)
+	}
+
+	for _, key := range e.keys {
+		if _, done := seen[key]; !done {
+			result = append(result, key+"="+e.values[key])
+		}
+	}
+
+	return result
+}
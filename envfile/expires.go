@@ -0,0 +1,61 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExpired is wrapped by the error returned in strict mode when a
+// variable is loaded after the expiry declared with "# @expires".
+var ErrExpired = errors.New("variable expired")
+
+// parseExpiry parses the date of an "# @expires" annotation: either a
+// date, meaning the variable expires at the end of that day in local
+// time, or an RFC 3339 time.
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry '%s', expected YYYY-MM-DD or an RFC 3339 time", s)
+}
+
+// annotateExpires handles "# @expires DATE", which applies to the next
+// variable, and "# @expires KEY DATE".
+func (p *parser) annotateExpires(args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("error: '%s' at line %d: expected '# @expires [KEY] DATE': %w", p.source, p.lineNumber, ErrSyntax)
+	}
+	expires, err := parseExpiry(args[len(args)-1])
+	if err != nil {
+		return fmt.Errorf("error: '%s' at line %d: %v: %w", p.source, p.lineNumber, err, ErrSyntax)
+	}
+	if len(args) == 2 {
+		p.expiries[args[0]] = expires
+		return nil
+	}
+	p.pendingExpiry = expires
+	return nil
+}
+
+// checkExpired reports the variables whose expiry has passed: as an
+// error wrapping ErrExpired in strict mode, and as a warning otherwise.
+// It runs on every load, including loads served from the cache.
+func checkExpired(source string, variables []variable, po parseOptions) error {
+	now := time.Now()
+	for _, v := range variables {
+		if v.expires.IsZero() || now.Before(v.expires) {
+			continue
+		}
+		if po.strict {
+			return fmt.Errorf("error: '%s': '%s' expired at %s: %w", source, v.key, v.expires.Format(time.RFC3339), ErrExpired)
+		}
+		if err := po.warn(EventExpired, source, 0, v.key, fmt.Sprintf("'%s' in '%s' expired at %s.", v.key, source, v.expires.Format(time.RFC3339))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
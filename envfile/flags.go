@@ -0,0 +1,75 @@
+package envfile
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// BindFlags sets the flags of fs that were not given on the command line
+// from the process environment or, failing that, from the file selected
+// by LoadEnvironment with opts. A missing file is not an error; flags are
+// then bound to the process environment only. See Environment.BindFlags.
+func BindFlags(fs *flag.FlagSet, prefix string, opts ...Option) error {
+	l := New(opts...)
+	env, err := l.Environment()
+	if errors.Is(err, ErrNoFileLoaded) {
+		env, err = l.newEnvironment(nil), nil
+	}
+	if err != nil {
+		return err
+	}
+	return env.BindFlags(fs, prefix)
+}
+
+// BindFlags sets the flags of fs that were not given on the command line
+// from the variable named by FlagKey(prefix, name), which makes a command
+// configurable the twelve-factor way without a configuration library:
+//
+//	port := flag.Int("port", 8080, "port to listen on")
+//	flag.Parse()
+//	env := envfile.MustRead(".env")
+//	if err := env.BindFlags(flag.CommandLine, "MYAPP_"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The precedence, from highest to lowest, is the command line, the
+// process environment, the variables of e and the flag default, so that
+// MYAPP_PORT=9090 in the environment overrides PORT in the .env file but
+// not -port on the command line. BindFlags must be called after fs.Parse,
+// since only then is it known which flags were given. Values are set with
+// fs.Set; all invalid values are reported together in the returned error.
+func (e *Environment) BindFlags(fs *flag.FlagSet, prefix string) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if given[f.Name] {
+			return
+		}
+		key := FlagKey(prefix, f.Name)
+		value, exists := lookupEnv(key, e.foldCase)
+		if !exists {
+			value, exists = e.Lookup(key)
+		}
+		if !exists {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("error: invalid value of '%s' for flag -%s: %v", key, f.Name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// FlagKey returns the key bound to the flag name by BindFlags: prefix
+// followed by name in upper case, with dashes and dots replaced by
+// underscores, so that the flag "db-host" with the prefix "MYAPP_" is
+// bound to MYAPP_DB_HOST.
+func FlagKey(prefix, name string) string {
+	return prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
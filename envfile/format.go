@@ -0,0 +1,73 @@
+package envfile
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileFormat parses a configuration file of another format into entries,
+// in file order, so that files such as Java .properties or TOML can go
+// through the same pipeline as .env files while a project migrates. The
+// Source of the entries is set by the Loader.
+type FileFormat func(r io.Reader) ([]Entry, error)
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]FileFormat)
+)
+
+// RegisterFormat makes the Loader parse files whose name ends in ext,
+// such as ".properties", with format instead of as env files. This
+// applies to the files read by Load, Read, Environment and Reloaders;
+// included files are always parsed as env files. The extension is
+// matched case-insensitively, and registering a nil format removes it.
+// The envfile/convert package provides formats for .properties and TOML
+// files:
+//
+//	envfile.RegisterFormat(".properties", convert.PropertiesEntries)
+//	env, err := envfile.Read("application.properties", ".env")
+//
+// Entries read through a format are subject to Middleware, WithKeys and
+// the other options applied to parsed variables, but not to directives
+// of the env file syntax such as annotations and {$name} references.
+func RegisterFormat(ext string, format FileFormat) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	ext = strings.ToLower(ext)
+	if format == nil {
+		delete(formats, ext)
+		return
+	}
+	formats[ext] = format
+}
+
+// lookupFormat returns the format registered for the extension of
+// filePath.
+func lookupFormat(filePath string) (FileFormat, bool) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == "" {
+		return nil, false
+	}
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	format, found := formats[ext]
+	return format, found
+}
+
+// parseFormat reads the file at filePath with format.
+// formatVariables reads the content of the file at filePath from r with
+// format.
+func formatVariables(filePath string, r io.Reader, format FileFormat) ([]variable, error) {
+	entries, err := format(r)
+	if err != nil {
+		return nil, fmt.Errorf("error: '%s': %v: %w", filePath, err, ErrSyntax)
+	}
+	variables := make([]variable, len(entries))
+	for i, entry := range entries {
+		variables[i] = variable{key: entry.Key, value: entry.Value}
+	}
+	return variables, nil
+}
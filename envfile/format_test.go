@@ -0,0 +1,50 @@
+package envfile_test
+
+import (
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestFormatQuotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "unneeded double", content: `KEY="value"`, want: `KEY=value`},
+		{name: "unneeded single", content: `KEY='value'`, want: `KEY=value`},
+		{name: "spaces", content: `KEY=a b`, want: `KEY="a b"`},
+		{name: "spaces and quotes", content: `KEY=a "b" \c`, want: `KEY="a \"b\" \\c"`},
+		{name: "needed", content: `KEY="a b"`, want: `KEY="a b"`},
+		{name: "hash", content: `KEY='a#b'`, want: `KEY='a#b'`},
+		{name: "escaped quote", content: `KEY="a\"b"`, want: `KEY=a"b`},
+		{name: "escaped tab", content: `KEY="a\tb"`, want: `KEY="a\tb"`},
+		{name: "single is literal", content: `KEY='a\nb'`, want: `KEY=a\nb`},
+		{name: "empty", content: `KEY=""`, want: `KEY=""`},
+		{name: "comment", content: `KEY=a b # note`, want: `KEY="a b" # note`},
+		{name: "unclosed", content: `KEY="a b`, want: `KEY="a b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := envfile.Format([]byte(tt.content + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want+"\n" {
+				t.Errorf("got %q, want %q", got, tt.want+"\n")
+			}
+			before, err := envfile.ParseBytes([]byte(tt.content + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, err := envfile.ParseBytes(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if before.Get("KEY") != after.Get("KEY") {
+				t.Errorf("value changed from %q to %q", before.Get("KEY"), after.Get("KEY"))
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package envfile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Formatter rewrites .env content in a canonical form, like gofmt does
+// for Go source.
+type Formatter struct {
+	// SortKeys sorts the definitions of each block of lines separated by
+	// blank lines by key, moving the comments and annotations directly
+	// above a definition with it. Blocks containing #include or
+	// conditional directives are left in order, and definitions of the
+	// same key keep their relative order, so the same value wins.
+	SortKeys bool
+	// AlignComments aligns the inline comments of consecutive
+	// single-line definitions in one column. Otherwise a single space
+	// separates a value from its comment.
+	AlignComments bool
+}
+
+// Format formats src with the default Formatter. See Formatter.Format.
+func Format(src []byte) ([]byte, error) {
+	return (&Formatter{}).Format(src)
+}
+
+// Format returns src in canonical form: definitions are written as
+// KEY=value, without indentation or spaces around the '=', and a bare KEY
+// as KEY=; trailing whitespace and runs of blank lines are removed; and
+// the file ends in a single line ending. Line endings and a byte-order
+// mark are kept.
+//
+// Quoting is normalized without changing what a value reads as: quotes
+// around a value that does not need them are removed, and an unquoted
+// value containing spaces or tabs is double-quoted. Continued values and
+// values that look truncated are left as written. Format fails
+// with an error wrapping ErrSyntax if a line is not a valid definition,
+// comment or directive, and formatting its output again changes nothing.
+func (f *Formatter) Format(src []byte) ([]byte, error) {
+	nodes, err := ParseWithPositions(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	newline := "\n"
+	if bytes.Contains(src, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+
+	// Split the nodes into blocks separated by blank lines.
+	var blocks [][]Node
+	var block []Node
+	for _, node := range nodes {
+		if node.Kind == NodeBlank {
+			if len(block) > 0 {
+				blocks = append(blocks, block)
+				block = nil
+			}
+			continue
+		}
+		if node.Kind == NodeVariable {
+			if node.Key == "" || strings.ContainsAny(node.Key, " \t") {
+				return nil, &ParseError{
+					Line:   node.Span.Start.Line,
+					Column: node.KeySpan.Start.Column,
+					Source: node.Text,
+					Hint:   "write KEY=value, with a key without spaces",
+					Err:    fmt.Errorf("error: line %d: '%s' is not a valid definition: %w", node.Span.Start.Line, node.Text, ErrSyntax),
+				}
+			}
+		}
+		block = append(block, node)
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+
+	var b strings.Builder
+	if bytes.HasPrefix(src, []byte("\ufeff")) {
+		b.WriteString("\ufeff")
+	}
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString(newline)
+		}
+		if f.SortKeys {
+			block = sortBlock(block)
+		}
+		f.writeBlock(&b, block, newline)
+	}
+	return []byte(b.String()), nil
+}
+
+// sortBlock returns the nodes of block with its definitions sorted by
+// key, each preceded by the comments and annotations directly above it.
+func sortBlock(block []Node) []Node {
+	type group struct {
+		key   string
+		nodes []Node
+	}
+	var groups []group
+	var pending []Node
+	for _, node := range block {
+		switch node.Kind {
+		case NodeDirective:
+			return block
+		case NodeVariable:
+			groups = append(groups, group{key: node.Key, nodes: append(pending, node)})
+			pending = nil
+		default:
+			pending = append(pending, node)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].key < groups[j].key })
+
+	sorted := make([]Node, 0, len(block))
+	for _, g := range groups {
+		sorted = append(sorted, g.nodes...)
+	}
+	// Comments after the last definition stay at the end.
+	return append(sorted, pending...)
+}
+
+// writeBlock writes the nodes of block in canonical form.
+func (f *Formatter) writeBlock(b *strings.Builder, block []Node, newline string) {
+	codes := make([]string, len(block))
+	for i, node := range block {
+		if node.Kind != NodeVariable {
+			codes[i] = strings.TrimSpace(node.Text)
+			continue
+		}
+		var code strings.Builder
+		if node.Profile != "" {
+			code.WriteString("@" + node.Profile + " ")
+		}
+		code.WriteString(node.Key)
+		if node.Type != "" {
+			code.WriteString(":" + node.Type)
+		}
+		code.WriteString("=" + normalizeQuotes(node.Value))
+		codes[i] = code.String()
+	}
+
+	// column[i] is the width the code of block[i] is padded to before its
+	// comment.
+	column := make([]int, len(block))
+	for start := 0; start < len(block); {
+		end := start
+		width := 0
+		for end < len(block) && f.AlignComments && alignable(block[end]) {
+			width = max(width, len(codes[end]))
+			end++
+		}
+		for i := start; i < end; i++ {
+			column[i] = width
+		}
+		start = max(end, start+1)
+	}
+
+	for i, node := range block {
+		line := codes[i]
+		if node.Kind == NodeVariable && node.Comment != "" {
+			line += strings.Repeat(" ", max(column[i]-len(line), 0)) + " " + strings.TrimRight(node.Comment, " \t")
+		}
+		b.WriteString(strings.ReplaceAll(line, "\r\n", newline) + newline)
+	}
+}
+
+// normalizeQuotes returns value, as written, with canonical quoting.
+func normalizeQuotes(value string) string {
+	if strings.Contains(value, "\n") || truncation(value, false) != "" {
+		return value
+	}
+	content := unquote(value)
+	if content == value {
+		if strings.ContainsAny(value, " \t") {
+			return quoteValue(value)
+		}
+		return value
+	}
+	if content == "" || needsQuotes(content) {
+		return value
+	}
+	return content
+}
+
+// needsQuotes reports whether value must be quoted to read back
+// unchanged.
+func needsQuotes(value string) bool {
+	return value == "" ||
+		strings.ContainsAny(value, " \t\r\n#") ||
+		value[0] == '"' || value[0] == '\'' ||
+		strings.HasSuffix(value, `\`)
+}
+
+// quoteValue double-quotes value, escaping backslashes, quotes and line
+// breaks so that it reads back unchanged.
+func quoteValue(value string) string {
+	return `"` + valueEscaper.Replace(value) + `"`
+}
+
+var valueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// alignable reports whether the comment of node takes part in alignment.
+func alignable(node Node) bool {
+	return node.Kind == NodeVariable && node.Comment != "" && !strings.Contains(node.Value, "\n")
+}
@@ -0,0 +1,156 @@
+package envfile_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// fuzzSeeds are env file contents covering the syntax the parser
+// accepts, used as the seed corpus of the fuzz targets.
+var fuzzSeeds = []string{
+	"",
+	"KEY=value\n",
+	"KEY=value # comment\r\n",
+	"\ufeffKEY=value",
+	"$HOST=localhost\nURL=http://{$HOST}:8080\n",
+	"KEY = value\n",
+	"=value\n",
+	"KEY\n",
+	"KEY:int=5\nLIST[]=a\nLIST[]=b\n",
+	"LONG=first \\\n  second\n",
+	"#if GO_ENV=production\nA=1\n#elif GO_ENV\nA=2\n#else\nA=3\n#endif\n",
+	"#if X\n",
+	"[section]\nkey=value\n",
+	"# @expires 2000-01-01\nOLD=1\n",
+	"# @merge append\nPATH=/bin\n",
+	"@production REPLICAS=3\n",
+	"KEY.linux=1\nKEY.windows=2\n",
+	"Q='x y'\nD=\"a#b\"\n",
+	"U=a b\nQ=\"plain\"\nE=\"a\\\"b\"\n",
+	"A={$UNSET}\n",
+}
+
+// categories are the sentinels every parse error must wrap.
+var categories = []error{
+	envfile.ErrSyntax,
+	envfile.ErrUnresolvedVar,
+	envfile.ErrDuplicateKey,
+	envfile.ErrIO,
+	envfile.ErrLimitExceeded,
+	envfile.ErrExpired,
+}
+
+func checkCategory(t *testing.T, err error) {
+	t.Helper()
+	for _, category := range categories {
+		if errors.Is(err, category) {
+			return
+		}
+	}
+	t.Fatalf("error wraps no category: %v", err)
+}
+
+// FuzzParse checks that parsing never panics, that ParseBytes and Parse
+// agree, and that every error is categorized.
+func FuzzParse(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed), false)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, strict bool) {
+		var opts []envfile.Option
+		if strict {
+			opts = append(opts, envfile.WithStrict(), envfile.WithUniqueKeys())
+		}
+		// Keep the parse away from the files of the working directory.
+		opts = append(opts, envfile.WithIncludeDepth(0))
+
+		fromBytes, bytesErr := envfile.ParseBytes(data, opts...)
+		fromReader, readerErr := envfile.Parse(bytes.NewReader(data), opts...)
+		if (bytesErr == nil) != (readerErr == nil) {
+			t.Fatalf("ParseBytes error %v, Parse error %v", bytesErr, readerErr)
+		}
+		if bytesErr != nil {
+			checkCategory(t, bytesErr)
+			return
+		}
+		if !equalMaps(fromBytes.Map(), fromReader.Map()) {
+			t.Fatalf("ParseBytes %v, Parse %v", fromBytes.Map(), fromReader.Map())
+		}
+		for _, key := range fromBytes.Keys() {
+			if key == "" {
+				t.Fatalf("empty key parsed from %q", data)
+			}
+		}
+	})
+}
+
+// FuzzMarshal checks that values Marshal accepts parse back unchanged.
+func FuzzMarshal(f *testing.F) {
+	f.Add("KEY", "value")
+	f.Add("URL", "http://{$HOST}")
+	f.Add("QUOTED", `"a b"`)
+	f.Add("EMPTY", "")
+	f.Add("NOTE", "issue #42\nsecond line")
+	f.Fuzz(func(t *testing.T, key, value string) {
+		data, err := envfile.Marshal(map[string]string{key: value})
+		if err != nil {
+			return
+		}
+		env, err := envfile.ParseBytes(data)
+		if err != nil {
+			t.Fatalf("parsing %q: %v", data, err)
+		}
+		if got, ok := env.Lookup(key); !ok || got != value {
+			t.Fatalf("%q=%q marshaled as %q parses as %q", key, value, data, got)
+		}
+	})
+}
+
+// FuzzLint checks that linting never panics.
+func FuzzLint(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := envfile.Lint(bytes.NewReader(data)); err != nil {
+			checkCategory(t, err)
+		}
+	})
+}
+
+// FuzzFormat checks that formatting is idempotent and keeps the
+// variables of the file.
+func FuzzFormat(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		formatted, err := envfile.Format(data)
+		if err != nil {
+			checkCategory(t, err)
+			return
+		}
+		again, err := envfile.Format(formatted)
+		if err != nil {
+			t.Fatalf("formatting %q again: %v", formatted, err)
+		}
+		if !bytes.Equal(formatted, again) {
+			t.Fatalf("formatting is not idempotent: %q, then %q", formatted, again)
+		}
+	})
+}
+
+func equalMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package envfile
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// generateRegex matches {generate:KIND} and {generate:KIND:N} directives.
+var generateRegex = regexp.MustCompile(`\{generate:([a-z0-9]+)(?::([0-9]+))?\}`)
+
+// maxGenerateLength bounds N in {generate:KIND:N}.
+const maxGenerateLength = 4096
+
+// WithGeneratePersist stores the values produced by {generate:...}
+// directives in the env file at path, so that they are generated only
+// once:
+//
+//	# .env
+//	SESSION_SECRET={generate:hex:32}
+//
+//	envfile.Load(envfile.WithGeneratePersist(".env.generated"))
+//
+// The first load generates SESSION_SECRET and appends it to
+// .env.generated; later loads reuse the stored value. A relative path is
+// resolved against the directory of the file containing the directive.
+// The file is read and written only when a directive is found.
+func WithGeneratePersist(path string) Option {
+	return func(o *options) {
+		o.parse.generatePersist = path
+	}
+}
+
+// generate replaces the {generate:KIND:N} directives in the value of key
+// with random values:
+//
+//	hex:N      N random bytes, hex-encoded (default 32)
+//	base64:N   N random bytes, URL-safe base64 without padding (default 32)
+//	alnum:N    N random letters and digits (default 32)
+//	uuid       a random UUID
+//
+// Unless WithGeneratePersist is set, a new value is generated every time
+// the file is parsed.
+func (p *parser) generate(key, value string) (string, error) {
+	if !strings.Contains(value, "{generate:") {
+		return value, nil
+	}
+	// The value depends on more than the file, so it is never cached.
+	p.volatile = true
+
+	if p.options.generatePersist != "" {
+		stored, err := p.persisted()
+		if err != nil {
+			return "", err
+		}
+		if v, exists := stored[key]; exists {
+			return v, nil
+		}
+	}
+
+	var err error
+	value = generateRegex.ReplaceAllStringFunc(value, func(s string) string {
+		if err != nil {
+			return ""
+		}
+		m := generateRegex.FindStringSubmatch(s)
+		var generated string
+		generated, err = generateValue(m[1], m[2])
+		if err != nil {
+			err = fmt.Errorf("error: '%s' at line %d: %s: %v: %w", p.source, p.lineNumber, s, err, ErrSyntax)
+		}
+		return generated
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if p.options.generatePersist != "" {
+		if err := p.persist(key, value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+func generateValue(kind, length string) (string, error) {
+	n := 32
+	if length != "" {
+		var err error
+		if n, err = strconv.Atoi(length); err != nil || n < 1 || n > maxGenerateLength {
+			return "", fmt.Errorf("length must be between 1 and %d", maxGenerateLength)
+		}
+	}
+
+	switch kind {
+	case "hex", "base64":
+		b := make([]byte, n)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		if kind == "hex" {
+			return hex.EncodeToString(b), nil
+		}
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	case "alnum":
+		const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+		b := make([]byte, n)
+		for i := range b {
+			index, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+			if err != nil {
+				return "", err
+			}
+			b[i] = chars[index.Int64()]
+		}
+		return string(b), nil
+	case "uuid":
+		if length != "" {
+			return "", fmt.Errorf("uuid takes no length")
+		}
+		return randomUUID()
+	}
+	return "", fmt.Errorf("unknown kind '%s'", kind)
+}
+
+// persistPath returns the WithGeneratePersist path, resolved against the
+// directory of the file being parsed.
+func (p *parser) persistPath() string {
+	path := p.options.generatePersist
+	if !filepath.IsAbs(path) && len(p.stack) > 0 {
+		path = filepath.Join(filepath.Dir(p.stack[len(p.stack)-1]), path)
+	}
+	return path
+}
+
+// persisted returns the values stored in the WithGeneratePersist file,
+// reading it on first use.
+func (p *parser) persisted() (map[string]string, error) {
+	path := p.persistPath()
+	if stored, exists := p.generated[path]; exists {
+		return stored, nil
+	}
+
+	stored := make(map[string]string)
+	if _, err := os.Stat(path); err == nil {
+		variables, err := parseFile(path, parseOptions{logger: p.options.logger, expansion: ExpandNone})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range variables {
+			stored[v.key] = v.value
+		}
+	}
+	if p.generated == nil {
+		p.generated = make(map[string]map[string]string)
+	}
+	p.generated[path] = stored
+	return stored, nil
+}
+
+// persist appends a generated value to the WithGeneratePersist file,
+// which is locked with LockFile and replaced atomically.
+func (p *parser) persist(key, value string) error {
+	path := p.persistPath()
+	if strings.ContainsAny(value, "\r\n#") {
+		return fmt.Errorf("error: generated value of '%s' cannot be stored in '%s'", key, path)
+	}
+
+	lock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error: unable to read file '%s': %w: %w", path, err, ErrIO)
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	content = append(content, key+"="+value+"\n"...)
+	if err := writeFileAtomic(path, content, 0o600, false); err != nil {
+		return err
+	}
+
+	p.generated[path][key] = value
+	p.options.logf("Generated '%s' and stored it in '%s'", key, path)
+	return nil
+}
@@ -0,0 +1,174 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Glob returns the regular files matching pattern, sorted lexically so
+// that the order does not depend on the file system. Patterns use
+// filepath.Match syntax, extended with "**", which matches any number of
+// directories, as in "config/**/*.env".
+func Glob(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("error: invalid pattern '%s': %v", pattern, err)
+		}
+		return regularFiles(matches), nil
+	}
+
+	slashed := filepath.ToSlash(pattern)
+	if _, err := path.Match(strings.ReplaceAll(slashed, "**", "*"), ""); err != nil {
+		return nil, fmt.Errorf("error: invalid pattern '%s': %v", pattern, err)
+	}
+
+	// Walk from the longest leading part of the pattern without
+	// metacharacters.
+	segments := strings.Split(slashed, "/")
+	root := ""
+	for len(segments) > 1 && !strings.ContainsAny(segments[0], `*?[\`) {
+		root = path.Join(root, segments[0])
+		if segments[0] == "" {
+			root = "/"
+		}
+		segments = segments[1:]
+	}
+	walkRoot := filepath.FromSlash(root)
+	if walkRoot == "" {
+		walkRoot = "."
+	}
+
+	var matches []string
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == walkRoot && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(walkRoot, p)
+		if err != nil {
+			return nil
+		}
+		if matchSegments(segments, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to search '%s': %v", walkRoot, err)
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments.
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], parts[0]); err != nil || !matched {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func regularFiles(paths []string) []string {
+	files := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// LoadGlob loads every file matching pattern, in the order returned by
+// Glob, so that values in later files override values in earlier ones.
+// It suits conf.d-style layouts where services drop in fragment files.
+func LoadGlob(pattern string, opts ...Option) (*Result, error) {
+	return New(opts...).LoadGlob(pattern)
+}
+
+// ReadGlob is like LoadGlob but returns the merged variables as an
+// Environment instead of setting them on the process environment.
+func ReadGlob(pattern string, opts ...Option) (*Environment, error) {
+	return New(opts...).ReadGlob(pattern)
+}
+
+// LoadGlob loads every file matching pattern. See the package-level
+// LoadGlob.
+func (l *Loader) LoadGlob(pattern string) (*Result, error) {
+	files, err := Glob(pattern)
+	if err != nil {
+		return &Result{}, err
+	}
+	return l.LoadFiles(files...)
+}
+
+// ReadGlob reads every file matching pattern into an Environment. See the
+// package-level ReadGlob.
+func (l *Loader) ReadGlob(pattern string) (*Environment, error) {
+	files, err := Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return l.Read(files...)
+}
+
+// LoadFiles loads each of the given files in order, so that values in
+// later files override values in earlier ones, and stops at the first file
+// that fails to load.
+func (l *Loader) LoadFiles(filenames ...string) (*Result, error) {
+	result := &Result{}
+	if l.o.concurrency <= 1 {
+		for _, filePath := range filenames {
+			if err := l.loadFile(filePath, result); err != nil {
+				l.o.hooks.error(filePath, err)
+				return result, fmt.Errorf("error: failed to load environment variables from '%s': %w", filePath, err)
+			}
+			result.Files = append(result.Files, filePath)
+			result.File = filePath
+		}
+		return result, nil
+	}
+
+	for i, parsed := range l.parseFiles(filenames) {
+		filePath := filenames[i]
+		err := parsed.err
+		if err == nil {
+			err = l.apply(filePath, parsed.variables, result)
+		}
+		if err != nil {
+			l.o.hooks.error(filePath, err)
+			return result, fmt.Errorf("error: failed to load environment variables from '%s': %w", filePath, err)
+		}
+		result.Files = append(result.Files, filePath)
+		result.File = filePath
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+// Package godotenv mirrors the API of github.com/joho/godotenv on top of
+// envfile, so that code using godotenv can switch by changing only the
+// import path:
+//
+//	import "github.com/lucap9056/go-envfile/envfile/godotenv"
+//
+//	err := godotenv.Load()
+//
+// Files are parsed with the envfile syntax, so features such as template
+// variables, includes and conditional sections are available, while
+// godotenv-specific syntax, such as quoted multi-line values or the
+// "export" prefix, is not. Like godotenv, the functions do not log.
+package godotenv
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// defaultFilenames returns filenames, or .env if none are given.
+func defaultFilenames(filenames []string) []string {
+	if len(filenames) == 0 {
+		return []string{".env"}
+	}
+	return filenames
+}
+
+// Load sets the variables of the given files, or of .env if none are
+// given, on the process environment. It never overrides a variable that
+// is already set, so when several files define a key, the first one wins.
+func Load(filenames ...string) error {
+	_, err := envfile.New(envfile.WithLogger(nil), envfile.WithOverride(false)).LoadFiles(defaultFilenames(filenames)...)
+	return err
+}
+
+// Overload is like Load but overrides variables that are already set, so
+// when several files define a key, the last one wins.
+func Overload(filenames ...string) error {
+	_, err := envfile.New(envfile.WithLogger(nil)).LoadFiles(defaultFilenames(filenames)...)
+	return err
+}
+
+// Read returns the merged variables of the given files, or of .env if
+// none are given, without setting them. Later files override earlier
+// ones.
+func Read(filenames ...string) (map[string]string, error) {
+	env, err := envfile.New(envfile.WithLogger(nil)).Read(defaultFilenames(filenames)...)
+	if err != nil {
+		return nil, err
+	}
+	return env.Map(), nil
+}
+
+// Parse reads env file content from r and returns its variables.
+func Parse(r io.Reader) (map[string]string, error) {
+	env, err := envfile.Parse(r, envfile.WithLogger(nil))
+	if err != nil {
+		return nil, err
+	}
+	return env.Map(), nil
+}
+
+// Unmarshal parses the env file content in str and returns its
+// variables.
+func Unmarshal(str string) (map[string]string, error) {
+	return Parse(strings.NewReader(str))
+}
+
+// UnmarshalBytes parses the env file content in src and returns its
+// variables.
+func UnmarshalBytes(src []byte) (map[string]string, error) {
+	return Parse(bytes.NewReader(src))
+}
+
+// Marshal renders envMap as env file content sorted by key, without a
+// trailing newline, quoting values that need it. It fails for values the
+// envfile syntax cannot express; see envfile.Marshal.
+func Marshal(envMap map[string]string) (string, error) {
+	content, err := envfile.Marshal(envMap)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(content), "\n"), nil
+}
+
+// Write writes envMap to filename in the format produced by Marshal,
+// followed by a newline, replacing the file atomically once the content
+// is synced to disk.
+func Write(envMap map[string]string, filename string) error {
+	content, err := Marshal(envMap)
+	if err != nil {
+		return err
+	}
+	return envfile.WriteFileAtomic(filename, []byte(content+"\n"), 0o644)
+}
+
+// Exec loads the given files, overriding existing variables if overload
+// is set, and runs cmd with cmdArgs, connected to the standard streams of
+// the current process.
+func Exec(filenames []string, cmd string, cmdArgs []string, overload bool) error {
+	load := Load
+	if overload {
+		load = Overload
+	}
+	if err := load(filenames...); err != nil {
+		return err
+	}
+
+	command := exec.Command(cmd, cmdArgs...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
@@ -0,0 +1,74 @@
+package godotenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile/godotenv"
+)
+
+func TestLoadFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		load func(filenames ...string) error
+		want string
+	}{
+		{name: "load keeps the first", load: godotenv.Load, want: "1"},
+		{name: "overload keeps the last", load: godotenv.Overload, want: "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			a := filepath.Join(dir, "a.env")
+			b := filepath.Join(dir, "b.env")
+			if err := os.WriteFile(a, []byte("GODOTENV_TEST_X=1\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(b, []byte("GODOTENV_TEST_X=2\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("GODOTENV_TEST_X", "")
+			os.Unsetenv("GODOTENV_TEST_X")
+
+			if err := tt.load(a, b); err != nil {
+				t.Fatal(err)
+			}
+			if got := os.Getenv("GODOTENV_TEST_X"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKeepsProcessEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("GODOTENV_TEST_X=file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GODOTENV_TEST_X", "process")
+
+	if err := godotenv.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("GODOTENV_TEST_X"); got != "process" {
+		t.Errorf("got %q, want %q", got, "process")
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	values := map[string]string{"MULTILINE": "a\nb", "NOTE": "issue #42"}
+	content, err := godotenv.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := godotenv.Unmarshal(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range values {
+		if got[key] != want {
+			t.Errorf("%s: got %q, want %q", key, got[key], want)
+		}
+	}
+}
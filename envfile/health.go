@@ -0,0 +1,108 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrMissingKey is wrapped by the errors of HealthCheck for a required
+// key that is not set.
+var ErrMissingKey = errors.New("required key missing")
+
+var (
+	healthMu sync.RWMutex
+	// required lists the keys registered with Require, in order.
+	required []string
+	// loadedExpiries holds the "# @expires" expiry of every variable set
+	// by a load.
+	loadedExpiries = make(map[string]time.Time)
+)
+
+// Require registers keys that the process needs to serve traffic, to be
+// checked by HealthCheck. Registering a key again has no effect.
+func Require(keys ...string) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	for _, key := range keys {
+		if !containsString(required, key) {
+			required = append(required, key)
+		}
+	}
+}
+
+// HealthCheck reports whether every key registered with Require is set
+// to a non-empty value in the process environment and, if it was loaded
+// with an "# @expires" annotation, has not expired. It returns nil when
+// all are usable, and otherwise an error listing each problem, wrapping
+// ErrMissingKey or ErrExpired.
+//
+// Its signature suits readiness probes, so that a pod reports unready
+// instead of crashing in a loop while mandatory configuration is absent:
+//
+//	envfile.Require("DATABASE_URL", "API_TOKEN")
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//		if err := envfile.HealthCheck(); err != nil {
+//			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//		}
+//	})
+//
+// Errors name the keys but never include their values.
+func HealthCheck() error {
+	healthMu.RLock()
+	keys := append([]string{}, required...)
+	healthMu.RUnlock()
+	return checkKeys(keys)
+}
+
+// HealthCheckFor returns a function that checks keys like HealthCheck,
+// without registering them, for services that expose several probes.
+func HealthCheckFor(keys ...string) func() error {
+	keys = append([]string{}, keys...)
+	return func() error {
+		return checkKeys(keys)
+	}
+}
+
+// checkKeys implements HealthCheck for keys.
+func checkKeys(keys []string) error {
+	now := time.Now()
+
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+
+	var errs []error
+	for _, key := range keys {
+		if os.Getenv(key) == "" {
+			errs = append(errs, fmt.Errorf("error: '%s' is not set: %w", key, ErrMissingKey))
+			continue
+		}
+		if expires, exists := loadedExpiries[key]; exists && !now.Before(expires) {
+			errs = append(errs, fmt.Errorf("error: '%s' expired at %s: %w", key, expires.Format(time.RFC3339), ErrExpired))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordExpiry remembers the expiry of a variable set on the process
+// environment, or forgets it if the variable has none.
+func recordExpiry(key string, expires time.Time) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	if expires.IsZero() {
+		delete(loadedExpiries, key)
+		return
+	}
+	loadedExpiries[key] = expires
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
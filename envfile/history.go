@@ -0,0 +1,173 @@
+package envfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSnapshot records the state of an env file at one point in time: the
+// hash of its content and the variables it defines.
+type FileSnapshot struct {
+	Path string
+	// Hash is the hex-encoded SHA-256 hash of the content of the file.
+	Hash string
+	Time time.Time
+	// Env holds the variables the file defines, parsed with the options
+	// of the Loader that took the snapshot.
+	Env *Environment
+}
+
+// SnapshotFile reads the env file at filePath with opts and returns a
+// snapshot of it.
+func SnapshotFile(filePath string, opts ...Option) (*FileSnapshot, error) {
+	return New(opts...).SnapshotFile(filePath)
+}
+
+// SnapshotFile reads the env file at filePath with l and returns a
+// snapshot of it.
+func (l *Loader) SnapshotFile(filePath string) (*FileSnapshot, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	env, err := l.Read(filePath)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	return &FileSnapshot{
+		Path: filePath,
+		Hash: hex.EncodeToString(sum[:]),
+		Time: time.Now(),
+		Env:  env,
+	}, nil
+}
+
+// ChangeLog lists the changes between two snapshots of a file, with
+// secret values masked, for audit trails.
+type ChangeLog struct {
+	Path string
+	// From and To are the hashes of the previous and the current content.
+	// From is empty if there is no previous snapshot.
+	From, To string
+	Time     time.Time
+	// Changes are the added, modified and removed keys, as returned by
+	// Diff, with secret values replaced by Mask.
+	Changes []Change
+}
+
+// ChangeLog returns the changes from previous to s, with the values that
+// DefaultDetector classifies as secrets replaced by Mask. A nil previous
+// reports every key as added. The content of the file may change without
+// changing any key, such as when a comment is edited; the hashes of the
+// ChangeLog then differ while Changes is empty.
+func (s *FileSnapshot) ChangeLog(previous *FileSnapshot) *ChangeLog {
+	return s.ChangeLogWith(previous, DefaultDetector)
+}
+
+// ChangeLogWith is like ChangeLog but classifies secrets with d.
+func (s *FileSnapshot) ChangeLogWith(previous *FileSnapshot, d *Detector) *ChangeLog {
+	changeLog := &ChangeLog{Path: s.Path, To: s.Hash, Time: s.Time}
+	var old *Environment
+	if previous != nil {
+		changeLog.From = previous.Hash
+		old = previous.Env
+	}
+	changeLog.Changes = Diff(old, s.Env)
+	for i, c := range changeLog.Changes {
+		if c.Old != "" && d.IsSecret(c.Key, c.Old) {
+			changeLog.Changes[i].Old = Mask
+		}
+		if c.New != "" && d.IsSecret(c.Key, c.New) {
+			changeLog.Changes[i].New = Mask
+		}
+	}
+	return changeLog
+}
+
+// String formats the change log with one line per change: "+ KEY=value"
+// for added keys, "- KEY" for removed keys, and "~ KEY: old -> new" for
+// modified keys.
+func (c *ChangeLog) String() string {
+	var b strings.Builder
+	from := c.From
+	if from == "" {
+		from = "(none)"
+	}
+	fmt.Fprintf(&b, "%s %s: %s -> %s\n", c.Time.Format(time.RFC3339), c.Path, from, c.To)
+	for _, change := range c.Changes {
+		switch {
+		case change.Added:
+			fmt.Fprintf(&b, "+ %s=%s\n", change.Key, change.New)
+		case change.Removed:
+			fmt.Fprintf(&b, "- %s\n", change.Key)
+		default:
+			fmt.Fprintf(&b, "~ %s: %s -> %s\n", change.Key, change.Old, change.New)
+		}
+	}
+	return b.String()
+}
+
+// History keeps the snapshots of an env file taken over time, so that
+// each change to the file can be recorded:
+//
+//	h := envfile.NewHistory(".env.production")
+//	if log, err := h.Record(); err == nil && log != nil {
+//		audit.Println(log)
+//	}
+//
+// History is safe for concurrent use.
+type History struct {
+	loader   *Loader
+	filePath string
+
+	mu        sync.Mutex
+	snapshots []*FileSnapshot
+}
+
+// NewHistory returns an empty History of the env file at filePath, read
+// with opts.
+func NewHistory(filePath string, opts ...Option) *History {
+	return New(opts...).NewHistory(filePath)
+}
+
+// NewHistory returns an empty History of the env file at filePath, read
+// with l.
+func (l *Loader) NewHistory(filePath string) *History {
+	return &History{loader: l, filePath: filePath}
+}
+
+// Record takes a snapshot of the file and, if its content changed since
+// the last snapshot, keeps it and returns the ChangeLog from the last
+// snapshot. It returns a nil ChangeLog if the content is unchanged; the
+// first call reports every key as added.
+func (h *History) Record() (*ChangeLog, error) {
+	snapshot, err := h.loader.SnapshotFile(h.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var previous *FileSnapshot
+	if len(h.snapshots) > 0 {
+		previous = h.snapshots[len(h.snapshots)-1]
+		if previous.Hash == snapshot.Hash {
+			return nil, nil
+		}
+	}
+	h.snapshots = append(h.snapshots, snapshot)
+	return snapshot.ChangeLog(previous), nil
+}
+
+// Snapshots returns the snapshots kept by Record, oldest first.
+func (h *History) Snapshots() []*FileSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*FileSnapshot(nil), h.snapshots...)
+}
@@ -0,0 +1,46 @@
+package envfile
+
+// Hooks are callbacks invoked while Load modifies the process environment.
+// Any of them may be nil.
+type Hooks struct {
+	// OnSet is called before a variable read from source is set. Returning
+	// a non-nil error vetoes the variable: it is not set, OnSkip is called
+	// with the error message as the reason, and loading continues.
+	OnSet func(key, value, source string) error
+	// OnSkip is called for each variable read from source that is not set.
+	OnSkip func(key, source, reason string)
+	// OnError is called when source cannot be loaded.
+	OnError func(source string, err error)
+}
+
+// WithHooks registers callbacks that observe, and may veto, every change
+// Load makes to the process environment. Calling WithHooks more than once
+// replaces the earlier hooks.
+func WithHooks(hooks Hooks) Option {
+	return func(o *options) {
+		o.hooks = hooks
+	}
+}
+
+func (h *Hooks) set(key, value, source string) bool {
+	if h.OnSet == nil {
+		return true
+	}
+	if err := h.OnSet(key, value, source); err != nil {
+		h.skip(key, source, err.Error())
+		return false
+	}
+	return true
+}
+
+func (h *Hooks) skip(key, source, reason string) {
+	if h.OnSkip != nil {
+		h.OnSkip(key, source, reason)
+	}
+}
+
+func (h *Hooks) error(source string, err error) {
+	if h.OnError != nil {
+		h.OnError(source, err)
+	}
+}
@@ -0,0 +1,11 @@
+package envfile
+
+// WithIncludeDepth sets the maximum nesting of "#include" and "source"
+// directives, DefaultIncludeDepth by default. A depth of zero rejects
+// every include directive.
+func WithIncludeDepth(depth int) Option {
+	return func(o *options) {
+		o.parse.includeDepth = depth
+		o.parse.includeDepthSet = true
+	}
+}
@@ -0,0 +1,102 @@
+package envfile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithINICompat reads files written for INI parsers as well:
+//
+//   - lines whose first non-blank character is ';' are comments, like
+//     lines starting with '#'. A ';' elsewhere on a line is part of the
+//     value, since values such as connection strings commonly contain it;
+//   - a "[section]" line starts a section whose keys are flattened into
+//     prefixed, upper-cased keys, so that host=db.internal below
+//     [database] defines DATABASE_HOST. Dots, dashes and spaces in section
+//     names become underscores, so [database.primary] prefixes keys with
+//     DATABASE_PRIMARY_. An empty "[]" section returns to unprefixed keys,
+//     and every file starts, and includes return, outside a section.
+//
+// MarshalINI writes variables back in this form.
+func WithINICompat() Option {
+	return func(o *options) {
+		o.parse.iniCompat = true
+	}
+}
+
+// parseSection recognizes a "[section]" line and returns the key prefix
+// for the section.
+func parseSection(line string) (string, bool) {
+	if len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+	name := strings.TrimSpace(line[1 : len(line)-1])
+	if name == "" {
+		return "", true
+	}
+	name = strings.NewReplacer(".", "_", "-", "_", " ", "_").Replace(name)
+	return strings.ToUpper(name) + "_", true
+}
+
+// MarshalINI renders values as INI-style content that WithINICompat
+// reads back unchanged, with keys sorted. Upper-case keys containing an
+// underscore are grouped into sections named after their first segment,
+// so DATABASE_HOST is written as host below [database]; other keys are
+// written before the first section.
+func MarshalINI(values map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return marshalINI(keys, func(key string) string { return values[key] })
+}
+
+// MarshalINI renders e as INI-style content, with sections in the order
+// their first key was defined. See the package-level MarshalINI.
+func (e *Environment) MarshalINI() ([]byte, error) {
+	return marshalINI(e.keys, e.Get)
+}
+
+func marshalINI(keys []string, get func(string) string) ([]byte, error) {
+	var top []string
+	var sections []string
+	grouped := make(map[string][]string)
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, err := marshalValue(key, get(key))
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+		if key[0] == ';' || key[0] == '[' {
+			return nil, fmt.Errorf("error: key '%s' cannot be written to an INI file", key)
+		}
+		section, name, found := strings.Cut(key, "_")
+		if !found || section == "" || name == "" || key != strings.ToUpper(key) {
+			top = append(top, key)
+			continue
+		}
+		if _, exists := grouped[section]; !exists {
+			sections = append(sections, section)
+		}
+		grouped[section] = append(grouped[section], key)
+	}
+
+	var buf bytes.Buffer
+	for _, key := range top {
+		fmt.Fprintf(&buf, "%s=%s\n", key, values[key])
+	}
+	for i, section := range sections {
+		if i > 0 || len(top) > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "[%s]\n", strings.ToLower(section))
+		for _, key := range grouped[section] {
+			fmt.Fprintf(&buf, "%s=%s\n", strings.ToLower(key[len(section)+1:]), values[key])
+		}
+	}
+	return buf.Bytes(), nil
+}
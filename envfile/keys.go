@@ -0,0 +1,169 @@
+package envfile
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// keyMatcher matches keys either against a glob pattern or a regular
+// expression.
+type keyMatcher struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+// match reports whether key matches. If fold is set, as it is where keys
+// are case-insensitive, a glob is matched case-insensitively and a
+// regular expression also matches the upper-cased key.
+func (m keyMatcher) match(key string, fold bool) bool {
+	if m.regexp != nil {
+		return m.regexp.MatchString(key) || fold && m.regexp.MatchString(strings.ToUpper(key))
+	}
+	return matchKey(m.glob, key, fold)
+}
+
+// MatchKey reports whether key matches one of patterns, which use the
+// path.Match syntax of WithAllowedKeys, such as "*_TOKEN". Patterns match
+// case-insensitively on Windows, where variable names are.
+func MatchKey(key string, patterns ...string) bool {
+	return matchesAny(patterns, key, runtime.GOOS == "windows")
+}
+
+// matchesAny reports whether key matches one of patterns,
+// case-insensitively if fold is set.
+func matchesAny(patterns []string, key string, fold bool) bool {
+	for _, pattern := range patterns {
+		if matchKey(pattern, key, fold) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKey reports whether key matches the path.Match pattern,
+// case-insensitively if fold is set.
+func matchKey(pattern, key string, fold bool) bool {
+	if fold {
+		pattern, key = strings.ToUpper(pattern), strings.ToUpper(key)
+	}
+	matched, err := path.Match(pattern, key)
+	return err == nil && matched
+}
+
+func (m keyMatcher) String() string {
+	if m.regexp != nil {
+		return m.regexp.String()
+	}
+	return m.glob
+}
+
+// keyFilter decides which keys may be loaded.
+type keyFilter struct {
+	allow []keyMatcher
+	deny  []keyMatcher
+}
+
+// denied reports whether key may not be loaded, and why. A key matching a
+// deny pattern is always denied; otherwise, if any allow patterns are
+// configured, the key must match one of them. fold matches keys
+// case-insensitively, as they are on Windows.
+func (f *keyFilter) denied(key string, fold bool) (string, bool) {
+	for _, m := range f.deny {
+		if m.match(key, fold) {
+			return fmt.Sprintf("key matches denied pattern '%s'", m), true
+		}
+	}
+	if len(f.allow) == 0 {
+		return "", false
+	}
+	for _, m := range f.allow {
+		if m.match(key, fold) {
+			return "", false
+		}
+	}
+	return "key is not in the allowed list", true
+}
+
+func globMatchers(patterns []string) []keyMatcher {
+	matchers := make([]keyMatcher, len(patterns))
+	for i, pattern := range patterns {
+		matchers[i] = keyMatcher{glob: pattern}
+	}
+	return matchers
+}
+
+func regexpMatchers(patterns []*regexp.Regexp) []keyMatcher {
+	matchers := make([]keyMatcher, len(patterns))
+	for i, pattern := range patterns {
+		matchers[i] = keyMatcher{regexp: pattern}
+	}
+	return matchers
+}
+
+// WithAllowedKeys restricts Load to keys matching at least one of the
+// given patterns. Patterns use path.Match syntax, so an exact name such as
+// "PORT" matches only itself while "APP_*" matches every key with that
+// prefix. Keys that are not allowed are skipped and reported in
+// Result.Denied. Patterns match case-insensitively where keys are, as on
+// Windows or with WithCaseSensitivity(CaseInsensitive).
+func WithAllowedKeys(patterns ...string) Option {
+	return func(o *options) {
+		o.keys.allow = append(o.keys.allow, globMatchers(patterns)...)
+	}
+}
+
+// WithAllowedKeysRegexp is like WithAllowedKeys but matches keys against
+// regular expressions.
+func WithAllowedKeysRegexp(patterns ...*regexp.Regexp) Option {
+	return func(o *options) {
+		o.keys.allow = append(o.keys.allow, regexpMatchers(patterns)...)
+	}
+}
+
+// WithDeniedKeys prevents Load from setting keys matching any of the given
+// patterns, such as "LD_PRELOAD", "GODEBUG" or "*_PROXY". Patterns use
+// path.Match syntax and match case-insensitively where keys are, like
+// WithAllowedKeys. Denied keys take precedence over allowed keys, are
+// skipped and are reported in Result.Denied.
+func WithDeniedKeys(patterns ...string) Option {
+	return func(o *options) {
+		o.keys.deny = append(o.keys.deny, globMatchers(patterns)...)
+	}
+}
+
+// WithDeniedKeysRegexp is like WithDeniedKeys but matches keys against
+// regular expressions.
+func WithDeniedKeysRegexp(patterns ...*regexp.Regexp) Option {
+	return func(o *options) {
+		o.keys.deny = append(o.keys.deny, regexpMatchers(patterns)...)
+	}
+}
+
+// WithKeys selects the keys to read, for loading a subset of a shared
+// file, such as only its SENTRY_* settings. Patterns use path.Match
+// syntax. The other keys are dropped after parsing, by Load as well as by
+// Environment, Read and Parse, as if the file did not define them: unlike
+// keys refused by WithAllowedKeys, they are not reported in Result.Denied
+// or to Hooks.OnSkip. Template variables used by the selected keys still
+// resolve. Calling WithKeys more than once adds to the selection.
+func WithKeys(patterns ...string) Option {
+	return func(o *options) {
+		o.selected = append(o.selected, globMatchers(patterns)...)
+	}
+}
+
+// selects reports whether key is selected by WithKeys.
+func (o *options) selects(key string) bool {
+	if len(o.selected) == 0 {
+		return true
+	}
+	for _, m := range o.selected {
+		if m.match(key, o.foldCase()) {
+			return true
+		}
+	}
+	return false
+}
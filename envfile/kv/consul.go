@@ -0,0 +1,139 @@
+package kv
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consul reads variables from a key prefix in the Consul KV store.
+type Consul struct {
+	// Address is the base URL of the Consul agent. It defaults to
+	// http://127.0.0.1:8500.
+	Address string
+	// Prefix is the key prefix to read, such as "app/prod/".
+	Prefix string
+	// Token is sent as the ACL token when set.
+	Token string
+	// Datacenter selects a datacenter other than the agent's own.
+	Datacenter string
+	// Key maps a Consul key to a variable name. It defaults to
+	// DefaultKey.
+	Key func(prefix, key string) string
+	// Client is the HTTP client used for requests. It defaults to
+	// http.DefaultClient.
+	Client *http.Client
+	// WaitTime is the longest a blocking query in Watch is held open by
+	// Consul. It defaults to five minutes.
+	WaitTime time.Duration
+}
+
+type consulPair struct {
+	Key   string
+	Value string
+}
+
+// Name returns "consul:" followed by the prefix.
+func (c *Consul) Name() string {
+	return "consul:" + c.Prefix
+}
+
+// Fetch returns the variables currently stored below the prefix.
+func (c *Consul) Fetch(ctx context.Context) (map[string]string, error) {
+	values, _, err := c.fetch(ctx, 0)
+	return values, err
+}
+
+// Watch blocks until ctx is done, calling onChange every time a key below
+// the prefix changes. It uses Consul blocking queries, so changes are
+// reported as soon as they happen without polling. Failed requests are
+// retried after a delay. Watch returns ctx.Err() once ctx is done.
+func (c *Consul) Watch(ctx context.Context, onChange func()) error {
+	var index uint64
+	for {
+		_, next, err := c.fetch(ctx, index)
+		if err == nil && next == 0 {
+			err = fmt.Errorf("error: consul response for prefix '%s' has no index", c.Prefix)
+		}
+		if err != nil {
+			if !sleep(ctx, retryDelay) {
+				return ctx.Err()
+			}
+			continue
+		}
+		// A changed index, including one that Consul reset, means the
+		// keys below the prefix may have changed.
+		if index != 0 && next != index {
+			onChange()
+		}
+		index = next
+	}
+}
+
+// fetch reads the prefix, blocking until the index changes when index is
+// not zero, and returns the variables and the new index.
+func (c *Consul) fetch(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	address := c.Address
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	}
+
+	query := url.Values{"recurse": {"true"}}
+	if c.Datacenter != "" {
+		query.Set("dc", c.Datacenter)
+	}
+	if index > 0 {
+		wait := c.WaitTime
+		if wait <= 0 {
+			wait = 5 * time.Minute
+		}
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%ds", int(wait.Seconds())))
+	}
+	endpoint := strings.TrimRight(address, "/") + "/v1/kv/" + strings.TrimLeft(c.Prefix, "/") + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+
+	resp, err := httpClient(c.Client).Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	// Consul answers 404 when no key exists below the prefix.
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string]string{}, next, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("error: consul returned '%s' for prefix '%s'", resp.Status, c.Prefix)
+	}
+
+	var pairs []consulPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("error: unable to decode consul response for prefix '%s': %v", c.Prefix, err)
+	}
+
+	entries := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		value, err := base64.StdEncoding.DecodeString(pair.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error: unable to decode consul value of '%s': %v", pair.Key, err)
+		}
+		entries[pair.Key] = string(value)
+	}
+	return mapKeys(c.Prefix, entries, c.Key), next, nil
+}
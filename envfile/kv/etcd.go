@@ -0,0 +1,273 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Etcd reads variables from a key prefix in etcd, through the JSON
+// gateway of the etcd v3 API.
+type Etcd struct {
+	// Endpoint is the base URL of an etcd member. It defaults to
+	// http://127.0.0.1:2379.
+	Endpoint string
+	// Prefix is the key prefix to read, such as "/app/prod/".
+	Prefix string
+	// Username and Password authenticate against etcd when auth is
+	// enabled.
+	Username string
+	Password string
+	// Key maps an etcd key to a variable name. It defaults to
+	// DefaultKey.
+	Key func(prefix, key string) string
+	// Client is the HTTP client used for requests. It defaults to
+	// http.DefaultClient. It must not set a timeout shorter than the
+	// expected time between changes, since Watch keeps a request open.
+	Client *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+type etcdKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type etcdRangeResponse struct {
+	Header etcdHeader     `json:"header"`
+	Kvs    []etcdKeyValue `json:"kvs"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Header   etcdHeader `json:"header"`
+		Canceled bool       `json:"canceled"`
+		Events   []struct {
+			Kv etcdKeyValue `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Name returns "etcd:" followed by the prefix.
+func (e *Etcd) Name() string {
+	return "etcd:" + e.Prefix
+}
+
+// Fetch returns the variables currently stored below the prefix.
+func (e *Etcd) Fetch(ctx context.Context) (map[string]string, error) {
+	values, _, err := e.fetch(ctx)
+	return values, err
+}
+
+// Watch blocks until ctx is done, calling onChange every time a key below
+// the prefix changes. It uses an etcd watch stream, so changes are
+// reported as soon as they happen without polling. Broken streams are
+// resumed after a delay from the last revision seen, so no change is
+// missed. Watch returns ctx.Err() once ctx is done.
+func (e *Etcd) Watch(ctx context.Context, onChange func()) error {
+	var revision int64
+	for {
+		var err error
+		if revision == 0 {
+			_, revision, err = e.fetch(ctx)
+		} else {
+			revision, err = e.watch(ctx, revision, onChange)
+		}
+		if err != nil && !sleep(ctx, retryDelay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// watch streams changes after revision until the stream breaks, and
+// returns the last revision seen.
+func (e *Etcd) watch(ctx context.Context, revision int64, onChange func()) (int64, error) {
+	request := map[string]any{
+		"create_request": map[string]string{
+			"key":            encode(e.Prefix),
+			"range_end":      encode(prefixEnd(e.Prefix)),
+			"start_revision": strconv.FormatInt(revision+1, 10),
+		},
+	}
+	body, err := e.post(ctx, "/v3/watch", request)
+	if err != nil {
+		return revision, err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	for {
+		var resp etcdWatchResponse
+		if err := decoder.Decode(&resp); err != nil {
+			return revision, err
+		}
+		if resp.Error != nil {
+			return revision, fmt.Errorf("error: etcd watch of prefix '%s' failed: %s", e.Prefix, resp.Error.Message)
+		}
+		if resp.Result.Canceled {
+			// The requested revision was compacted, so changes may have
+			// been missed; start over from the current state.
+			onChange()
+			return 0, fmt.Errorf("error: etcd watch of prefix '%s' was canceled", e.Prefix)
+		}
+		if len(resp.Result.Events) > 0 {
+			if next, err := strconv.ParseInt(resp.Result.Header.Revision, 10, 64); err == nil {
+				revision = next
+			}
+			onChange()
+		}
+	}
+}
+
+// fetch reads the prefix and returns the variables and the current
+// revision.
+func (e *Etcd) fetch(ctx context.Context) (map[string]string, int64, error) {
+	request := map[string]string{
+		"key":       encode(e.Prefix),
+		"range_end": encode(prefixEnd(e.Prefix)),
+	}
+	body, err := e.post(ctx, "/v3/kv/range", request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer body.Close()
+
+	var resp etcdRangeResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, 0, fmt.Errorf("error: unable to decode etcd response for prefix '%s': %v", e.Prefix, err)
+	}
+	revision, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+
+	entries := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error: unable to decode etcd key: %v", err)
+		}
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error: unable to decode etcd value of '%s': %v", key, err)
+		}
+		entries[string(key)] = string(value)
+	}
+	return mapKeys(e.Prefix, entries, e.Key), revision, nil
+}
+
+// post sends a JSON request to the gateway and returns the response body.
+// It authenticates first when credentials are set, and again if the token
+// has expired.
+func (e *Etcd) post(ctx context.Context, path string, request any) (io.ReadCloser, error) {
+	token, err := e.authenticate(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.send(ctx, path, request, token)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && e.Username != "" {
+		resp.Body.Close()
+		if token, err = e.authenticate(ctx, true); err != nil {
+			return nil, err
+		}
+		resp, err = e.send(ctx, path, request, token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("error: etcd returned '%s' for prefix '%s'", resp.Status, e.Prefix)
+	}
+	return resp.Body, nil
+}
+
+func (e *Etcd) send(ctx context.Context, path string, request any, token string) (*http.Response, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := e.Endpoint
+	if endpoint == "" {
+		endpoint = "http://127.0.0.1:2379"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return httpClient(e.Client).Do(req)
+}
+
+// authenticate returns the auth token, requesting a new one when none is
+// cached or refresh is set. It returns an empty token when no credentials
+// are configured.
+func (e *Etcd) authenticate(ctx context.Context, refresh bool) (string, error) {
+	if e.Username == "" {
+		return "", nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token != "" && !refresh {
+		return e.token, nil
+	}
+
+	resp, err := e.send(ctx, "/v3/auth/authenticate", map[string]string{
+		"name":     e.Username,
+		"password": e.Password,
+	}, "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error: etcd authentication as '%s' failed: %s", e.Username, resp.Status)
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("error: unable to decode etcd authentication response: %v", err)
+	}
+	e.token = auth.Token
+	return e.token, nil
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// prefixEnd returns the smallest key greater than every key starting
+// with prefix, which etcd uses as the end of a prefix range.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// Every byte is 0xff, or the prefix is empty: read to the end of the
+	// keyspace.
+	return "\x00"
+}
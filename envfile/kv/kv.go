@@ -0,0 +1,137 @@
+// Package kv provides envfile Sources that read environment variables
+// from a key prefix in Consul or etcd, so that clustered services can
+// share configuration centrally while keeping local .env files for
+// development.
+//
+// Each key below the prefix becomes one variable. By default the prefix
+// is removed, the remaining slashes become underscores and the result is
+// upper-cased, so the key "app/prod/db/host" under the prefix "app/prod/"
+// becomes DB_HOST:
+//
+//	consul := &kv.Consul{Prefix: "app/prod/"}
+//	result, err := envfile.Load(envfile.WithSource(consul))
+//
+// Both Sources can also watch the prefix and report changes:
+//
+//	go consul.Watch(ctx, func() { envfile.Load(envfile.WithSource(consul)) })
+//
+// The clients talk to the HTTP APIs directly and have no dependencies
+// beyond the standard library.
+package kv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// retryDelay is how long Watch waits after a failed request before
+// trying again.
+const retryDelay = 5 * time.Second
+
+// DefaultKey maps a store key to a variable name by removing prefix,
+// turning slashes into underscores and upper-casing the result.
+func DefaultKey(prefix, key string) string {
+	key = strings.TrimPrefix(key, prefix)
+	key = strings.Trim(key, "/")
+	return strings.ToUpper(strings.ReplaceAll(key, "/", "_"))
+}
+
+// mapKeys turns raw store entries into variables, skipping folder keys
+// that have no name after mapping.
+func mapKeys(prefix string, entries map[string]string, keyFunc func(prefix, key string) string) map[string]string {
+	if keyFunc == nil {
+		keyFunc = DefaultKey
+	}
+	values := make(map[string]string, len(entries))
+	for key, value := range entries {
+		name := keyFunc(prefix, key)
+		if name == "" {
+			continue
+		}
+		values[name] = value
+	}
+	return values
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
+
+// sleep waits for d or until ctx is done, and reports whether ctx is
+// still active.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// RegisterSources registers the "consul" and "etcd" schemes with
+// envfile.RegisterSource, so that Consul and etcd prefixes can be
+// addressed by URI:
+//
+//	kv.RegisterSources()
+//	src, err := envfile.OpenSource("consul://127.0.0.1:8500/app/prod/?token=secret")
+//
+// The host of the URI selects the agent or member, reached over HTTP, or
+// HTTPS with the query parameter tls=true; its path is the prefix,
+// without the leading slash for Consul. The query parameters token and
+// dc set the Token and Datacenter of a Consul source, and the user
+// information of the URI the Username and Password of an etcd source.
+func RegisterSources() {
+	envfile.RegisterSource("consul", func(uri string) (envfile.Source, error) {
+		u, address, err := parseSourceURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		return &Consul{
+			Address:    address,
+			Prefix:     strings.TrimPrefix(u.Path, "/"),
+			Token:      u.Query().Get("token"),
+			Datacenter: u.Query().Get("dc"),
+		}, nil
+	})
+	envfile.RegisterSource("etcd", func(uri string) (envfile.Source, error) {
+		u, address, err := parseSourceURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		password, _ := u.User.Password()
+		return &Etcd{
+			Endpoint: address,
+			Prefix:   u.Path,
+			Username: u.User.Username(),
+			Password: password,
+		}, nil
+	})
+}
+
+// parseSourceURI parses the URI of a source and returns the base URL of
+// its host, or "" to use the default.
+func parseSourceURI(uri string) (*url.URL, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, "", fmt.Errorf("error: invalid source '%s': %w", uri, err)
+	}
+	if u.Host == "" {
+		return u, "", nil
+	}
+	scheme := "http"
+	if u.Query().Get("tls") == "true" {
+		scheme = "https"
+	}
+	return u, scheme + "://" + u.Host, nil
+}
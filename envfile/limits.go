@@ -0,0 +1,76 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLimitExceeded is wrapped by the error returned when input exceeds one
+// of the Limits configured with WithLimits.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits bounds the resources used to parse a single env file. A zero
+// field means no limit.
+type Limits struct {
+	// MaxFileSize is the maximum size of a file in bytes.
+	MaxFileSize int64
+	// MaxLineLength is the maximum length of a single line in bytes.
+	MaxLineLength int
+	// MaxVariables is the maximum number of variables in a file, not
+	// counting $-prefixed template variables.
+	MaxVariables int
+	// MaxValueLength is the maximum length of a value in bytes, checked
+	// both before and after template variables are substituted.
+	MaxValueLength int
+	// MaxTotalSize is the maximum total size in bytes of the keys and
+	// values of the variables in a file, bounding the memory a parse
+	// retains.
+	MaxTotalSize int64
+}
+
+// WithLimits guards against hostile or runaway input, such as env files
+// from user uploads or remote URLs. Parsing stops with an error wrapping
+// ErrLimitExceeded as soon as a limit is exceeded, instead of reading the
+// whole input into memory.
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.parse.limits = limits
+	}
+}
+
+func limitError(source string, line int, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	if line > 0 {
+		return fmt.Errorf("error: '%s' at line %d: %s: %w", source, line, message, ErrLimitExceeded)
+	}
+	return fmt.Errorf("error: '%s': %s: %w", source, message, ErrLimitExceeded)
+}
+
+// limitedReader fails once more than remaining bytes have been read,
+// so that content growing past MaxFileSize is rejected rather than
+// silently truncated.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	source    string
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err()
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, l.err()
+	}
+	return n, err
+}
+
+func (l *limitedReader) err() error {
+	return limitError(l.source, 0, "size exceeds the limit")
+}
@@ -0,0 +1,251 @@
+package envfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rule identifies a check performed by Lint.
+type Rule string
+
+const (
+	// RuleDuplicateKey flags a key that is defined more than once.
+	RuleDuplicateKey Rule = "duplicate-key"
+	// RuleLowercaseKey flags a key containing lowercase letters.
+	RuleLowercaseKey Rule = "lowercase-key"
+	// RuleUnquotedSpace flags an unquoted value that contains spaces.
+	RuleUnquotedSpace Rule = "unquoted-space"
+	// RuleTrailingWhitespace flags a line ending in spaces or tabs.
+	RuleTrailingWhitespace Rule = "trailing-whitespace"
+	// RuleSpacedAssignment flags whitespace around the '=' of an
+	// assignment, which WithStrict rejects.
+	RuleSpacedAssignment Rule = "spaced-assignment"
+	// RuleTruncatedValue flags a value that looks truncated by the format:
+	// cut at a '#' that directly follows it, opened with a quote that is
+	// not closed, or ending in a backslash.
+	RuleTruncatedValue Rule = "truncated-value"
+	// RuleSecret flags a value that looks like a secret. It only runs when
+	// Linter.Tracked is set, since secrets are expected in untracked files.
+	RuleSecret Rule = "secret"
+	// RuleMissingKey flags a key from Linter.Example that the file does
+	// not define, or a required key of a Schema that is not set.
+	RuleMissingKey Rule = "missing-key"
+	// RuleInvalidType flags a value that is invalid for the type a Schema
+	// declares for its key.
+	RuleInvalidType Rule = "invalid-type"
+	// RuleUnknownKey flags a key that a Schema does not list.
+	RuleUnknownKey Rule = "unknown-key"
+)
+
+// Rules lists every rule known to Lint and Schema.Validate.
+var Rules = []Rule{
+	RuleDuplicateKey,
+	RuleLowercaseKey,
+	RuleUnquotedSpace,
+	RuleTrailingWhitespace,
+	RuleSpacedAssignment,
+	RuleTruncatedValue,
+	RuleSecret,
+	RuleMissingKey,
+	RuleInvalidType,
+	RuleUnknownKey,
+}
+
+// Severity describes how serious an Issue is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+var ruleSeverity = map[Rule]Severity{
+	RuleDuplicateKey:       SeverityError,
+	RuleLowercaseKey:       SeverityWarning,
+	RuleUnquotedSpace:      SeverityWarning,
+	RuleTrailingWhitespace: SeverityNote,
+	RuleSpacedAssignment:   SeverityWarning,
+	RuleTruncatedValue:     SeverityWarning,
+	RuleSecret:             SeverityError,
+	RuleMissingKey:         SeverityError,
+	RuleInvalidType:        SeverityError,
+	RuleUnknownKey:         SeverityError,
+}
+
+// Issue is a single finding reported by Lint. Line and Column are
+// 1-based; an Issue that concerns the file as a whole has a Line of 0.
+type Issue struct {
+	Rule     Rule     `json:"rule"`
+	Severity Severity `json:"severity"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// Linter checks .env content against a set of rules.
+type Linter struct {
+	// Rules lists the rules to run. If empty, every rule in Rules runs.
+	Rules []Rule
+	// Example lists the keys the file is expected to define, typically
+	// read from a .env.example file. RuleMissingKey is skipped when empty.
+	Example []string
+	// Tracked reports whether the file is tracked by version control,
+	// which enables RuleSecret.
+	Tracked bool
+}
+
+// Lint checks r against every rule except RuleSecret and RuleMissingKey,
+// which need the context provided by a Linter.
+func Lint(r io.Reader) ([]Issue, error) {
+	return (&Linter{}).Lint(r)
+}
+
+// Lint checks r and returns the issues found in the order they appear in
+// the file, followed by issues concerning the file as a whole. If r
+// cannot be read to the end, for example because a line is longer than
+// bufio.MaxScanTokenSize, Lint returns the issues found so far and an
+// error wrapping ErrIO.
+func (l *Linter) Lint(r io.Reader) ([]Issue, error) {
+	enabled := make(map[Rule]bool)
+	if len(l.Rules) == 0 {
+		for _, rule := range Rules {
+			enabled[rule] = true
+		}
+	} else {
+		for _, rule := range l.Rules {
+			enabled[rule] = true
+		}
+	}
+
+	var issues []Issue
+	report := func(rule Rule, line, column int, format string, args ...any) {
+		if !enabled[rule] {
+			return
+		}
+		issues = append(issues, Issue{
+			Rule:     rule,
+			Severity: ruleSeverity[rule],
+			Line:     line,
+			Column:   column,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// defined maps each key to the line of its first unconditional
+	// definition, or to the negated line of a conditional one.
+	defined := make(map[string]int)
+	conditional := 0
+
+	lineNumber := 0
+	continued := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+		raw := scanner.Text()
+
+		if trimmed := strings.TrimRight(raw, " \t"); len(trimmed) != len(raw) {
+			report(RuleTrailingWhitespace, lineNumber, len(trimmed)+1, "trailing whitespace")
+		}
+
+		// Lines continued from the previous line are part of its value.
+		continuation := continued
+		continued = continuesLine(raw)
+		if continuation {
+			continue
+		}
+
+		if _, ok := parseInclude(raw); ok {
+			continue
+		}
+
+		switch directive, _, _ := conditionalDirective(raw); directive {
+		case "#if":
+			conditional++
+		case "#endif":
+			conditional--
+		}
+
+		content := clearAfterHash(raw)
+		cut := len(content) < len(raw) && strings.TrimRight(content, " \t") == content
+		line := strings.TrimSpace(content)
+		if len(line) == 0 {
+			continue
+		}
+		keyColumn := strings.Index(content, line) + 1
+
+		unconditional := conditional <= 0
+		if _, rest, ok := splitProfilePrefix(line); ok {
+			keyColumn += len(line) - len(rest)
+			line = rest
+			unconditional = false
+		}
+
+		key, value := splitLine(line)
+		if key == "" {
+			continue
+		}
+		valueColumn := keyColumn + len(line) - len(value)
+		if spacedAssignment(line) {
+			report(RuleSpacedAssignment, lineNumber, keyColumn+len(key), "whitespace around '=' of '%s'", key)
+		}
+		if reason := truncation(value, cut); reason != "" {
+			report(RuleTruncatedValue, lineNumber, valueColumn, "value of '%s' %s", key, reason)
+		}
+		key, _ = splitKeyType(key)
+		key, isList := splitListKey(key)
+		if key == "" {
+			// A line such as ":int=5", which the parser skips.
+			continue
+		}
+
+		// Definitions in conditional sections are alternatives rather than
+		// duplicates, so only unconditional definitions are compared. Items
+		// of KEY[]= lists are meant to repeat.
+		if first, exists := defined[key]; exists && unconditional && first > 0 && !isList {
+			report(RuleDuplicateKey, lineNumber, keyColumn, "key '%s' is already defined at line %d", key, first)
+		} else if !exists || defined[key] < 0 {
+			if unconditional {
+				defined[key] = lineNumber
+			} else if !exists {
+				defined[key] = -lineNumber
+			}
+		}
+
+		if key[0] != '$' && key != strings.ToUpper(key) {
+			report(RuleLowercaseKey, lineNumber, keyColumn, "key '%s' contains lowercase letters", key)
+		}
+
+		if strings.ContainsAny(value, " \t") && !isQuoted(value) {
+			report(RuleUnquotedSpace, lineNumber, valueColumn, "value of '%s' contains spaces but is not quoted", key)
+		}
+
+		if l.Tracked && key[0] != '$' && DefaultDetector.IsSecret(key, value) {
+			report(RuleSecret, lineNumber, valueColumn, "value of '%s' looks like a secret in a tracked file", key)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return issues, fmt.Errorf("error: failed to read file at line %d: %w: %w", lineNumber+1, err, ErrIO)
+	}
+
+	for _, key := range l.Example {
+		if _, exists := defined[key]; !exists {
+			report(RuleMissingKey, 0, 0, "key '%s' from the example file is not defined", key)
+		}
+	}
+
+	return issues, nil
+}
+
+// isQuoted reports whether s is wrapped in matching single or double
+// quotes.
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
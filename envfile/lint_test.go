@@ -0,0 +1,41 @@
+package envfile_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestLintReadError(t *testing.T) {
+	content := "lower=1\nKEY=" + strings.Repeat("x", 1<<20) + "\nlate=1\n"
+	issues, err := envfile.Lint(strings.NewReader(content))
+	if !errors.Is(err, envfile.ErrIO) {
+		t.Fatalf("got %v, want an error wrapping ErrIO", err)
+	}
+	if len(issues) != 1 || issues[0].Rule != envfile.RuleLowercaseKey {
+		t.Errorf("got %+v, want the issue found before the long line", issues)
+	}
+}
+
+func TestRulesListsEveryRule(t *testing.T) {
+	schema := &envfile.Schema{Entries: []envfile.ExampleEntry{{Key: "PORT", Type: "int"}}}
+	env, err := envfile.ParseBytes([]byte("PORT=x\nOTHER=1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := make(map[envfile.Rule]bool)
+	for _, rule := range envfile.Rules {
+		known[rule] = true
+	}
+	issues := schema.Validate(env)
+	if len(issues) != 2 {
+		t.Fatalf("got %+v, want an invalid-type and an unknown-key issue", issues)
+	}
+	for _, issue := range issues {
+		if !known[issue.Rule] {
+			t.Errorf("rule %q is missing from Rules", issue.Rule)
+		}
+	}
+}
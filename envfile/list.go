@@ -0,0 +1,117 @@
+package envfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultListSeparator joins the items of KEY[]= lists unless changed with
+// WithListSeparator, and splits values in GetStringSlice.
+const DefaultListSeparator = ","
+
+// WithListSeparator joins the items of KEY[]= lists with sep instead of
+// DefaultListSeparator.
+func WithListSeparator(sep string) Option {
+	return func(o *options) {
+		o.parse.listSeparator = sep
+	}
+}
+
+func (po parseOptions) separator() string {
+	if po.listSeparator == "" {
+		return DefaultListSeparator
+	}
+	return po.listSeparator
+}
+
+// splitListKey reports whether key uses the KEY[]= list syntax and
+// returns it without the brackets.
+func splitListKey(key string) (string, bool) {
+	if name, found := strings.CutSuffix(key, "[]"); found && name != "" {
+		return name, true
+	}
+	return key, false
+}
+
+// addListItem adds value to the list variable key, starting the list if
+// key has not been defined with the list syntax yet. An item with an
+// empty value only starts the list, so that "KEY[]=" declares an empty
+// list.
+func (p *parser) addListItem(v variable) error {
+	index, exists := p.lists[v.key]
+	if !exists {
+		p.lists[v.key] = len(p.result)
+		p.result = append(p.result, v)
+		return nil
+	}
+
+	list := &p.result[index]
+	switch {
+	case v.value == "":
+		return nil
+	case list.value == "":
+		list.value = v.value
+	default:
+		list.value += p.options.separator() + v.value
+	}
+	if limit := p.options.limits.MaxValueLength; limit > 0 && len(list.value) > limit {
+		return limitError(p.source, p.lineNumber, "value length %d of '%s' exceeds the limit of %d bytes", len(list.value), v.key, limit)
+	}
+	return nil
+}
+
+// GetStringSlice returns the value of key split into items. A value
+// written as a JSON array, such as ["a","b"], is decoded as one;
+// otherwise it is split at sep, or at DefaultListSeparator if sep is
+// empty, and each item is trimmed of spaces. An empty value is an empty
+// slice.
+func (e *Environment) GetStringSlice(key, sep string) ([]string, error) {
+	value, err := e.lookupRequired(key)
+	if err != nil {
+		return nil, err
+	}
+	list, err := splitList(value, sep)
+	if err != nil {
+		return nil, fmt.Errorf("error: value of '%s': %v", key, err)
+	}
+	return list, nil
+}
+
+// splitList splits value as described for GetStringSlice.
+func splitList(value, sep string) ([]string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return []string{}, nil
+	}
+
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		var items []any
+		decoder := json.NewDecoder(strings.NewReader(trimmed))
+		decoder.UseNumber()
+		if err := decoder.Decode(&items); err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid JSON array: %v", value, err)
+		}
+		list := make([]string, len(items))
+		for i, item := range items {
+			switch item := item.(type) {
+			case string:
+				list[i] = item
+			case nil:
+				list[i] = ""
+			default:
+				list[i] = fmt.Sprint(item)
+			}
+		}
+		return list, nil
+	}
+
+	if sep == "" {
+		sep = DefaultListSeparator
+	}
+	list := strings.Split(value, sep)
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list, nil
+}
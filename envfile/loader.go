@@ -0,0 +1,467 @@
+package envfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Loader loads .env files according to the Options it was created with.
+// A Loader is safe for concurrent use.
+type Loader struct {
+	o *options
+
+	// once holds the outcome of LoadOnce.
+	once loadOnce
+}
+
+// New returns a Loader configured with opts. Without options, it behaves
+// exactly like the package-level Load.
+func New(opts ...Option) *Loader {
+	return &Loader{o: newOptions(opts)}
+}
+
+// ErrNoFileLoaded is returned when none of the candidate .env files exists
+// or could be loaded.
+var ErrNoFileLoaded = errors.New("error: no .env file was successfully loaded")
+
+// ErrUnknownProfile is returned in strict mode when GO_ENV names a profile
+// that is not configured.
+var ErrUnknownProfile = errors.New("error: unknown profile")
+
+// Load selects the first candidate file that exists and loads
+// successfully, and sets its variables on the process environment.
+func (l *Loader) Load() (*Result, error) {
+	if l.o.report == nil {
+		return l.load(nil)
+	}
+	report, result, err := l.LoadReport()
+	if werr := report.write(l.o.report); werr != nil {
+		l.o.parse.logf("Warning: Failed to write the load report: %v", werr)
+	}
+	return result, err
+}
+
+// load implements Load, recording the files it tries in report if it is
+// not nil.
+func (l *Loader) load(report *Report) (*Result, error) {
+	l.trackLoad()
+	result := &Result{}
+	filePath, err := l.loadFirst(func(filePath string) error {
+		if report == nil {
+			return l.loadFile(filePath, result)
+		}
+		start := time.Now()
+		err := l.loadFile(filePath, result)
+		report.attempt(filePath, time.Since(start), err)
+		return err
+	})
+	result.File = filePath
+	if !errors.Is(err, ErrUnknownProfile) {
+		result.Profile = l.o.resultProfile()
+	}
+	if filePath != "" {
+		result.Files = []string{filePath}
+	}
+	if err == ErrNoFileLoaded {
+		// Defaults apply even without a file.
+		if err := l.applyDefaults(result); err != nil {
+			return result, err
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+	if err := l.loadSources(result); err != nil {
+		return result, err
+	}
+	return result, l.applyDefaults(result)
+}
+
+// Environment selects a file the same way Load does, but returns its
+// variables as an Environment instead of setting them on the process
+// environment.
+func (l *Loader) Environment() (*Environment, error) {
+	var variables []variable
+	_, err := l.loadFirst(func(filePath string) error {
+		if err := l.checkFile(filePath); err != nil {
+			return err
+		}
+		vars, err := l.parseFile(filePath)
+		if err != nil {
+			return err
+		}
+		variables = vars
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sourced, err := l.readSources()
+	if err != nil {
+		return nil, err
+	}
+	// Limit the capacity so that appending never writes into a slice
+	// shared with the cache.
+	variables = append(variables[:len(variables):len(variables)], sourced...)
+	return l.newEnvironment(variables), nil
+}
+
+// Read parses the given files and returns their merged variables as an
+// Environment without modifying the process environment. Files are merged
+// in the order given, so values in later files override values in earlier
+// ones.
+func (l *Loader) Read(filenames ...string) (*Environment, error) {
+	var variables []variable
+	for _, parsed := range l.parseFiles(filenames) {
+		if parsed.err != nil {
+			return nil, parsed.err
+		}
+		variables = append(variables, parsed.variables...)
+	}
+	return l.newEnvironment(variables), nil
+}
+
+// Parse reads env file content from r and returns its variables as an
+// Environment without modifying the process environment.
+func (l *Loader) Parse(r io.Reader) (*Environment, error) {
+	po, err := l.parseOpts()
+	if err != nil {
+		return nil, err
+	}
+	variables, err := parseReader(r, "<input>", po)
+	if err == nil {
+		variables, err = l.process("<input>", variables)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.newEnvironment(variables), nil
+}
+
+// ParseBytes is like Parse for content already in memory.
+func (l *Loader) ParseBytes(data []byte) (*Environment, error) {
+	po, err := l.parseOpts()
+	if err != nil {
+		return nil, err
+	}
+	variables, err := parseData(data, "<input>", po)
+	if err == nil {
+		variables, err = l.process("<input>", variables)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.newEnvironment(variables), nil
+}
+
+// newEnvironment builds an Environment from parsed variables, applying the
+// configured case sensitivity and merge strategies.
+func (l *Loader) newEnvironment(variables []variable) *Environment {
+	if !l.o.foldCase() {
+		return newEnvironment(l.o.mergeVariables(variables))
+	}
+	env := newEnvironment(l.o.mergeVariables(foldKeys(variables)))
+	env.foldCase = true
+	env.folded = foldIndex(env.keys)
+	return env
+}
+
+// candidates returns the directory to search and the candidate file names
+// in order of precedence.
+func (l *Loader) candidates() (string, []string, error) {
+	names, err := l.names("")
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := l.dir("")
+	if err != nil {
+		return "", nil, err
+	}
+	return dir, names, nil
+}
+
+// names returns the candidate file names for the profile env, or for the
+// profile selected by WithProfile or GO_ENV if env is empty.
+func (l *Loader) names(env string) ([]string, error) {
+	o := l.o
+
+	if o.filenames != nil {
+		if o.patterns {
+			return o.expandPatterns(env), nil
+		}
+		return o.filenames, nil
+	}
+
+	profiles := o.profiles
+	if profiles == nil {
+		profiles = envFileMap
+	}
+
+	env, known := o.selectedProfile(env)
+	names := profiles[env]
+	if !known {
+		if o.parse.strict || o.unknownProfileError {
+			o.parse.logf("Error: Environment '%s' is not recognized.", env)
+			return nil, fmt.Errorf("%w '%s'", ErrUnknownProfile, env)
+		}
+		o.parse.logf("Warning: Environment '%s' is not recognized. Defaulting to 'development' environment files.", env)
+		names = profiles["development"]
+	}
+	return names, nil
+}
+
+// dir returns dir, or the directory set with WithDir, or the current
+// working directory, falling back to the directory of the executable if
+// WithExecutableDirFallback is set. Symlinks are resolved if
+// WithResolveSymlinks is set.
+func (l *Loader) dir(dir string) (string, error) {
+	if dir == "" {
+		dir = l.o.dir
+	}
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil && l.o.executableFallback {
+			if exe, exeErr := executableDir(); exeErr == nil {
+				l.o.parse.logf("Warning: Could not get the current working directory: %v. Searching the executable's directory '%s' instead.", err, exe)
+				cwd, err = exe, nil
+			}
+		}
+		if err != nil {
+			l.o.parse.logf("Error: Could not get the current working directory: %v", err)
+			return "", &DirError{Op: "getwd", Err: err}
+		}
+		dir = cwd
+	}
+	if l.o.resolveSymlinks {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			l.o.parse.logf("Error: Could not resolve symlinks in the directory '%s': %v", dir, err)
+			return "", &DirError{Op: "resolve", Dir: dir, Err: err}
+		}
+		dir = resolved
+	}
+	return dir, nil
+}
+
+// existing returns the paths of the files in dir named by names, in the
+// order of names. A name that does not exist is replaced by its encrypted
+// form, named with the extension of a registered Decryptor, if that
+// exists.
+func (l *Loader) existing(dir string, names []string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		l.o.parse.logf("Error: Could not read the directory '%s': %v", dir, err)
+		return nil, &DirError{Op: "read", Dir: dir, Err: err}
+	}
+
+	fileMap := make(map[string]struct{})
+	for _, file := range files {
+		if !file.IsDir() {
+			fileMap[file.Name()] = struct{}{}
+		}
+	}
+
+	exts := decryptorExtensions()
+	var paths []string
+	for _, name := range names {
+		if _, exists := fileMap[name]; exists {
+			paths = append(paths, filepath.Join(dir, name))
+			continue
+		}
+		for _, ext := range exts {
+			if _, exists := fileMap[name+ext]; exists {
+				paths = append(paths, filepath.Join(dir, name+ext))
+				break
+			}
+		}
+	}
+	return paths, nil
+}
+
+// loadFirst walks the candidate .env files in order of precedence and
+// calls load for each one that exists, stopping at the first file that
+// loads successfully. It returns the path of the loaded file, or an error
+// if no file was loaded.
+func (l *Loader) loadFirst(load func(filePath string) error) (string, error) {
+	o := l.o
+
+	dir, names, err := l.candidates()
+	if err != nil {
+		return "", err
+	}
+
+	var paths []string
+	var required map[string]bool
+	if vault := l.vaultPath(dir); vault != "" {
+		paths = []string{vault}
+	} else if paths, err = l.search(dir, names); err != nil {
+		return "", err
+	} else if required, err = l.requiredPaths(dir, names, paths); err != nil {
+		return "", err
+	}
+
+	var errs []error
+	for _, filePath := range paths {
+		if err := load(filePath); err != nil {
+			o.hooks.error(filePath, err)
+			o.parse.logf("Error: Failed to load environment variables from '%s': %v", filePath, err)
+			if required[filePath] {
+				return "", fmt.Errorf("error: required file '%s' failed to load: %w: %w", filePath, ErrRequiredFile, err)
+			}
+			errs = append(errs, err)
+		} else {
+			o.parse.logf("Successfully loaded environment variables from '%s'", filePath)
+			return filePath, nil
+		}
+	}
+
+	o.parse.logf("Warning: No .env file was successfully loaded. Ensure at least one of the expected .env files exists in the current directory.")
+	if o.parse.strict && len(errs) > 0 {
+		return "", errors.Join(append([]error{ErrNoFileLoaded}, errs...)...)
+	}
+	return "", ErrNoFileLoaded
+}
+
+// checkFile performs the checks configured for a file before it is
+// parsed. Files are verified with WithChecksum and WithPublicKey as they
+// are read, by readFile and the parser.
+func (l *Loader) checkFile(filePath string) error {
+	if l.o.checkPermissions && !permissionCheckOverridden() {
+		if err := CheckPermissions(filePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile returns the content of the file at filePath, verified if
+// WithChecksum or WithPublicKey is in use.
+func (l *Loader) readFile(filePath string) ([]byte, error) {
+	if l.o.parse.verify != nil {
+		return l.o.parse.verify.verify(filePath)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	return content, nil
+}
+
+// parseFile parses a file the same way parseFileCached does, or decrypts
+// it if it is a vault or has a registered Decryptor, or reads it with the
+// FileFormat registered for its extension, runs the result through the
+// middleware and reports it to the configured Metrics.
+func (l *Loader) parseFile(filePath string) ([]variable, error) {
+	parse := func(filePath string) ([]variable, error) {
+		var variables []variable
+		var err error
+		if l.isVault(filePath) {
+			variables, err = l.parseVault(filePath)
+		} else if d, found := lookupDecryptor(filePath); found {
+			variables, err = l.parseEncrypted(filePath, d)
+		} else if format, found := lookupFormat(filePath); found {
+			var content []byte
+			if content, err = l.readFile(filePath); err == nil {
+				variables, err = formatVariables(filePath, bytes.NewReader(content), format)
+			}
+		} else {
+			var po parseOptions
+			if po, err = l.parseOpts(); err == nil {
+				variables, err = parseFileCached(filePath, po)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return l.process(filePath, variables)
+	}
+	if l.o.metrics == nil {
+		return parse(filePath)
+	}
+	start := time.Now()
+	variables, err := parse(filePath)
+	l.o.metrics.FileParsed(filePath, time.Since(start), err)
+	return variables, err
+}
+
+// setenvMu serializes writes to the process environment so that
+// concurrent loads never interleave their variables.
+var setenvMu sync.Mutex
+
+func (l *Loader) loadFile(filePath string, result *Result) error {
+	if err := l.checkFile(filePath); err != nil {
+		return err
+	}
+
+	variables, err := l.parseFile(filePath)
+	if err != nil {
+		return err
+	}
+	return l.apply(filePath, variables, result)
+}
+
+// apply sets variables read from source on the process environment,
+// subject to the configured key filters, override policy and hooks.
+func (l *Loader) apply(source string, variables []variable, result *Result) error {
+	o := l.o
+
+	if o.foldCase() {
+		variables = foldKeys(variables)
+	}
+
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+
+	set := 0
+	for _, v := range variables {
+		if reason, denied := o.keys.denied(v.key, o.foldCase()); denied {
+			result.Denied = append(result.Denied, v.key)
+			o.hooks.skip(v.key, source, reason)
+			continue
+		}
+		value := v.value
+		if m := o.mergeFor(v); m.strategy != MergeReplace {
+			old, exists := lookupEnv(v.key, o.foldCase())
+			var ok bool
+			if value, ok = m.combine(old, exists, value); !ok {
+				o.hooks.skip(v.key, source, "key is already set in the process environment")
+				continue
+			}
+		} else if o.keepsOS(source, result) {
+			// Keys set by an earlier layer are kept only if the process
+			// environment ranks above every layer.
+			if _, exists := lookupEnv(v.key, o.foldCase()); exists && (o.noOverride || !result.hasKey(v.key)) {
+				if !result.hasKey(v.key) {
+					result.setOrigin(v.key, OSOrigin)
+				}
+				o.hooks.skip(v.key, source, "key is already set in the process environment")
+				continue
+			}
+		}
+		if !o.hooks.set(v.key, value, source) {
+			continue
+		}
+		loadedRestorePoint.record(v.key)
+		if err := os.Setenv(v.key, value); err != nil {
+			return fmt.Errorf("error: unable to set environment variable '%s': %v", v.key, err)
+		}
+		recordExpiry(v.key, v.expires)
+		result.addKey(v.key)
+		result.setOrigin(v.key, source)
+		if v.translatedFrom != "" {
+			result.Translated = append(result.Translated, Translation{From: v.translatedFrom, To: v.key, Source: source})
+		}
+		set++
+	}
+
+	if o.metrics != nil {
+		o.metrics.KeysLoaded(source, set)
+	}
+	return nil
+}
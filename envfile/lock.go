@@ -0,0 +1,67 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileLock is an advisory lock on an env file, taken with LockFile.
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile takes an exclusive advisory lock on the env file at filePath,
+// waiting until no other process or goroutine holds it, so that tools
+// editing the same file do not lose each other's updates. Set,
+// ApplyPatch, WriteChecksum and the commands that edit files hold the
+// lock around their read-modify-write cycle; programs that edit a file
+// themselves should do the same:
+//
+//	lock, err := envfile.LockFile(".env")
+//	if err != nil {
+//		return err
+//	}
+//	defer lock.Unlock()
+//
+// The lock is held on a FILE.lock file next to the env file, since an
+// atomic write replaces the env file itself. The lock file is created
+// with mode 0600 and left in place when the lock is released. Loading
+// does not take the lock: atomic writes already ensure that readers never
+// see a partly written file.
+//
+// The lock uses flock on Unix and LockFileEx on Windows. It is advisory:
+// it does not stop programs that do not take it from writing the file.
+// On platforms without file locking, LockFile only creates the lock file.
+func LockFile(filePath string) (*FileLock, error) {
+	if target, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = target
+	}
+	lockPath := filePath + ".lock"
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to open lock file '%s': %w: %w", lockPath, err, ErrIO)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error: unable to lock '%s': %w: %w", lockPath, err, ErrIO)
+	}
+	return &FileLock{file: file}, nil
+}
+
+// Unlock releases the lock. It is safe to call more than once.
+func (l *FileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	file := l.file
+	l.file = nil
+	err := unlockFile(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error: unable to unlock '%s': %w: %w", file.Name(), err, ErrIO)
+	}
+	return nil
+}
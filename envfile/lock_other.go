@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package envfile
+
+import "os"
+
+// lockFile does nothing on platforms without file locking, such as
+// Solaris, Plan 9 and WebAssembly.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
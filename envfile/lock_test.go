@@ -0,0 +1,56 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows
+
+package envfile_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestLockFileExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	first, err := envfile.LockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan *envfile.FileLock)
+	go func() {
+		second, err := envfile.LockFile(path)
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("a second opener took the lock while it was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case second := <-acquired:
+		if second == nil {
+			return
+		}
+		if err := second.Unlock(); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lock was not handed over after Unlock")
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Errorf("second Unlock: %v", err)
+	}
+}
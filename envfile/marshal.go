@@ -0,0 +1,121 @@
+package envfile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Marshal renders values as env file content, one KEY=value line per
+// variable, sorted by key. Values containing spaces, or that would not
+// read back unchanged as written, such as values containing a '#' or a
+// line break, are double-quoted as Format writes them. It fails if a key
+// or value cannot be written so that parsing the result gives back the
+// same values, for example because the value contains a variable
+// reference, which is expanded even in quotes.
+func Marshal(values map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return marshal(keys, func(key string) string { return values[key] })
+}
+
+// Marshal renders e as env file content in the order its keys were
+// defined. See the package-level Marshal.
+func (e *Environment) Marshal() ([]byte, error) {
+	return marshal(e.keys, e.Get)
+}
+
+// MarshalSorted is like Marshal, but writes the keys in sorted order, so
+// that generated files diff cleanly however their variables were
+// defined.
+func (e *Environment) MarshalSorted() ([]byte, error) {
+	keys := e.Keys()
+	sort.Strings(keys)
+	return marshal(keys, e.Get)
+}
+
+// MarshalEntries renders entries as env file content in the order given.
+// Only Key and Value are used. A key that appears more than once is
+// written once, at its first position, with its last value, which is how
+// it would be read back.
+func MarshalEntries(entries []Entry) ([]byte, error) {
+	values := make(map[string]string, len(entries))
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, exists := values[entry.Key]; !exists {
+			keys = append(keys, entry.Key)
+		}
+		values[entry.Key] = entry.Value
+	}
+	return marshal(keys, func(key string) string { return values[key] })
+}
+
+func marshal(keys []string, get func(string) string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, key := range keys {
+		value, err := marshalValue(key, get(key))
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalValue returns value as it is written to a KEY=value line:
+// as is, or double-quoted if it contains spaces or would not read back
+// unchanged otherwise. It fails if key or value cannot be written at all.
+func marshalValue(key, value string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	if err := checkQuotable(key, value); err != nil {
+		return "", err
+	}
+	if value != "" && (needsQuotes(value) || value != strings.TrimSpace(value)) {
+		return quoteValue(value), nil
+	}
+	return value, nil
+}
+
+// checkKey reports an error if key would not read back unchanged from a
+// KEY=value line.
+func checkKey(key string) error {
+	if key == "" || !utf8.ValidString(key) || strings.ContainsAny(key, "=#\ufeff") || strings.IndexFunc(key, isSpaceOrControl) >= 0 || key[0] == '$' || key[0] == '@' {
+		return fmt.Errorf("error: key '%s' cannot be written to an env file", key)
+	}
+	if _, typ := splitKeyType(key); typ != "" {
+		return fmt.Errorf("error: key '%s' cannot be written to an env file: it ends in a type suffix", key)
+	}
+	return nil
+}
+
+// checkQuotable reports an error if value would not read back unchanged
+// from a KEY=value line even when quoted: values are expanded and
+// transformed after their quotes are removed.
+func checkQuotable(key, value string) error {
+	switch {
+	case strings.ContainsRune(value, 0):
+		// NUL bytes make the content read as UTF-16.
+		return fmt.Errorf("error: value of '%s' cannot be written to an env file: it contains a NUL byte", key)
+	case strings.Contains(value, "{$"):
+		return fmt.Errorf("error: value of '%s' cannot be written to an env file: it contains a variable reference", key)
+	case hasTransform(value):
+		return fmt.Errorf("error: value of '%s' cannot be written to an env file: it contains a transform expression", key)
+	}
+	return nil
+}
+
+// isSpaceOrControl reports whether r is trimmed from a key or splits it.
+func isSpaceOrControl(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r)
+}
@@ -0,0 +1,83 @@
+package envfile_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: `value`, want: "KEY=value\n"},
+		{name: "empty", value: ``, want: "KEY=\n"},
+		{name: "spaces", value: `a b`, want: "KEY=\"a b\"\n"},
+		{name: "hash", value: `issue #42`, want: "KEY=\"issue #42\"\n"},
+		{name: "line break", value: "a\nb", want: "KEY=\"a\\nb\"\n"},
+		{name: "quotes", value: `"a"`, want: "KEY=\"\\\"a\\\"\"\n"},
+		{name: "surrounding whitespace", value: " a ", want: "KEY=\" a \"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]string{"KEY": tt.value}
+			data, err := envfile.Marshal(values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %q, want %q", data, tt.want)
+			}
+			env, err := envfile.ParseBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Get("KEY"); got != tt.value {
+				t.Errorf("read back %q, want %q", got, tt.value)
+			}
+
+			data, err = envfile.MarshalINI(values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			env, err = envfile.ParseBytes(data, envfile.WithINICompat())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Get("KEY"); got != tt.value {
+				t.Errorf("INI read back %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestMarshalRejects(t *testing.T) {
+	for _, value := range []string{"a\x00b", "http://{$HOST}"} {
+		if _, err := envfile.Marshal(map[string]string{"KEY": value}); err == nil {
+			t.Errorf("Marshal(%q) succeeded", value)
+		}
+	}
+}
+
+func TestWriteEffective(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("NOTE=\"issue #42\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := envfile.New(envfile.WithDir(dir), envfile.WithFilenames(".env")).WriteEffective(&buf); err != nil {
+		t.Fatal(err)
+	}
+	env, err := envfile.ParseBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := env.Get("NOTE"); got != "issue #42" {
+		t.Errorf("got %q, want %q", got, "issue #42")
+	}
+}
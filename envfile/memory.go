@@ -0,0 +1,76 @@
+package envfile
+
+import "strings"
+
+// WithInterning shares the storage of repeated keys and values, such as
+// "true" or a common host name, within each parsed file, and copies them
+// out of the lines they were read from, so that a parse retains only the
+// bytes of its distinct strings. It lowers the memory held by files with
+// tens of thousands of variables at a small cost in parsing time.
+// Environment.MemoryStats reports the effect.
+func WithInterning() Option {
+	return func(o *options) {
+		o.parse.intern = true
+	}
+}
+
+// intern returns the shared copy of s with WithInterning, or s otherwise.
+func (p *parser) intern(s string) string {
+	if !p.options.intern {
+		return s
+	}
+	if shared, exists := p.interned[s]; exists {
+		return shared
+	}
+	if p.interned == nil {
+		p.interned = make(map[string]string)
+	}
+	s = strings.Clone(s)
+	p.interned[s] = s
+	return s
+}
+
+// MemoryStats describes the memory held by the variables of an
+// Environment.
+type MemoryStats struct {
+	// Keys is the number of variables.
+	Keys int
+	// KeyBytes is the total size of the keys in bytes.
+	KeyBytes int64
+	// ValueBytes is the total size of the values in bytes.
+	ValueBytes int64
+	// UniqueValueBytes is the total size in bytes of the distinct
+	// values, which is what the values occupy when shared with
+	// WithInterning.
+	UniqueValueBytes int64
+}
+
+// MemoryStats returns statistics about the memory held by e, to size memory
+// budgets and to judge whether WithInterning pays off.
+func (e *Environment) MemoryStats() MemoryStats {
+	stats := MemoryStats{Keys: len(e.keys)}
+	unique := make(map[string]struct{}, len(e.values))
+	for _, key := range e.keys {
+		value := e.values[key]
+		stats.KeyBytes += int64(len(key))
+		stats.ValueBytes += int64(len(value))
+		if _, seen := unique[value]; !seen {
+			unique[value] = struct{}{}
+			stats.UniqueValueBytes += int64(len(value))
+		}
+	}
+	return stats
+}
+
+// Range calls fn for each variable of e in the order its key was first
+// defined, until fn returns false. Unlike Entries, Keys and Map, it does
+// not copy the variables, so large environments can be streamed, for
+// example to the environment of a child process, without building
+// another copy of them.
+func (e *Environment) Range(fn func(key, value string) bool) {
+	for _, key := range e.keys {
+		if !fn(key, e.values[key]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MergeStrategy decides how a loaded value is combined with a value the
+// key already has, either in the process environment or from an earlier
+// file.
+type MergeStrategy string
+
+const (
+	// MergeReplace replaces the existing value. It is the default.
+	MergeReplace MergeStrategy = "replace"
+	// MergeAppend adds the loaded value after the existing one, joined by
+	// the separator.
+	MergeAppend MergeStrategy = "append"
+	// MergePrepend adds the loaded value before the existing one, joined
+	// by the separator.
+	MergePrepend MergeStrategy = "prepend"
+	// MergeIfUnset only sets the key if it has no value yet.
+	MergeIfUnset MergeStrategy = "if-unset"
+)
+
+// merge is the strategy and separator configured for a key.
+type merge struct {
+	strategy  MergeStrategy
+	separator string
+}
+
+// WithMergeStrategy sets how values of key are combined with the value
+// it already has. For MergeAppend and MergePrepend, the values are joined
+// by separator, or by os.PathListSeparator if separator is empty, which
+// suits PATH and LD_LIBRARY_PATH:
+//
+//	envfile.Load(envfile.WithMergeStrategy("PATH", envfile.MergePrepend, ""))
+//
+// Strategies can also be declared in a file with an annotation, which
+// WithMergeStrategy overrides:
+//
+//	# @merge PATH prepend
+//	# @merge JAVA_OPTS append " "
+//
+// A key with a strategy other than MergeReplace is merged even when
+// WithOverride(false) is set. Strategies apply to Load, to files merged
+// by Read and LoadFiles, and to repeated definitions within a file.
+func WithMergeStrategy(key string, strategy MergeStrategy, separator string) Option {
+	return func(o *options) {
+		if o.merges == nil {
+			o.merges = make(map[string]merge)
+		}
+		o.merges[key] = newMerge(strategy, separator)
+	}
+}
+
+func newMerge(strategy MergeStrategy, separator string) merge {
+	if separator == "" {
+		separator = string(os.PathListSeparator)
+	}
+	return merge{strategy: strategy, separator: separator}
+}
+
+// annotateMerge handles "# @merge KEY STRATEGY [SEPARATOR]". The
+// separator may be quoted so that it can contain spaces.
+func (p *parser) annotateMerge(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("error: '%s' at line %d: expected '# @merge KEY STRATEGY [SEPARATOR]': %w", p.source, p.lineNumber, ErrSyntax)
+	}
+	strategy := MergeStrategy(args[1])
+	switch strategy {
+	case MergeReplace, MergeAppend, MergePrepend, MergeIfUnset:
+	default:
+		return fmt.Errorf("error: '%s' at line %d: unknown merge strategy '%s': %w", p.source, p.lineNumber, args[1], ErrSyntax)
+	}
+
+	separator := strings.Join(args[2:], " ")
+	if isQuoted(separator) {
+		separator = separator[1 : len(separator)-1]
+	}
+	p.merges[args[0]] = newMerge(strategy, separator)
+	return nil
+}
+
+// mergeFor returns the strategy for v, preferring the one configured with
+// WithMergeStrategy over one declared in the file.
+func (o *options) mergeFor(v variable) merge {
+	if m, exists := o.merges[v.key]; exists {
+		return m
+	}
+	if o.foldCase() {
+		for key, m := range o.merges {
+			if strings.EqualFold(key, v.key) {
+				return m
+			}
+		}
+	}
+	if v.merge.strategy != "" {
+		return v.merge
+	}
+	return merge{strategy: MergeReplace}
+}
+
+// combine returns the value to set for a key that currently has value
+// old, if exists, and whether to set it at all.
+func (m merge) combine(old string, exists bool, value string) (string, bool) {
+	switch m.strategy {
+	case MergeAppend:
+		if exists && old != "" {
+			return old + m.separator + value, true
+		}
+	case MergePrepend:
+		if exists && old != "" {
+			return value + m.separator + old, true
+		}
+	case MergeIfUnset:
+		return value, !exists
+	}
+	return value, true
+}
+
+// mergeVariables resolves repeated keys in variables according to their
+// strategies, so that the last occurrence of each key holds the merged
+// value.
+func (o *options) mergeVariables(variables []variable) []variable {
+	merged := make([]variable, 0, len(variables))
+	values := make(map[string]string, len(variables))
+	for _, v := range variables {
+		old, exists := values[v.key]
+		value, ok := o.mergeFor(v).combine(old, exists, v.value)
+		if !ok {
+			continue
+		}
+		v.value = value
+		values[v.key] = value
+		merged = append(merged, v)
+	}
+	return merged
+}
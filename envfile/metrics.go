@@ -0,0 +1,110 @@
+package envfile
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives counters and timings from a Loader and the Reloaders
+// created from it, so that they can be exported to a monitoring system
+// such as Prometheus, OpenTelemetry or expvar. Methods may be called
+// concurrently.
+type Metrics interface {
+	// FileParsed is called after a file is read, whether from disk or
+	// from the cache, with the time it took and the error, if any.
+	FileParsed(path string, d time.Duration, err error)
+	// KeysLoaded is called after Load sets the variables read from
+	// source, with the number of keys it set.
+	KeysLoaded(source string, n int)
+	// Reloaded is called after each Reload with the time it took, the
+	// number of changes and the error, if any.
+	Reloaded(d time.Duration, changes int, err error)
+}
+
+// WithMetrics reports the files parsed, keys loaded and reloads of the
+// Loader to m.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// Stats is a Metrics implementation that keeps running totals. Its
+// String method returns them as JSON, so a *Stats can be published with
+// expvar.Publish. The zero value is ready to use.
+type Stats struct {
+	filesParsed    atomic.Int64
+	parseErrors    atomic.Int64
+	parseDuration  atomic.Int64
+	keysLoaded     atomic.Int64
+	reloads        atomic.Int64
+	reloadErrors   atomic.Int64
+	reloadDuration atomic.Int64
+	lastReload     atomic.Int64
+}
+
+// StatsSnapshot holds the totals of a Stats at one point in time.
+type StatsSnapshot struct {
+	FilesParsed    int64         `json:"files_parsed"`
+	ParseErrors    int64         `json:"parse_errors"`
+	ParseDuration  time.Duration `json:"parse_duration_ns"`
+	KeysLoaded     int64         `json:"keys_loaded"`
+	Reloads        int64         `json:"reloads"`
+	ReloadErrors   int64         `json:"reload_errors"`
+	ReloadDuration time.Duration `json:"reload_duration_ns"`
+	// LastReload is the time of the last successful reload, or the zero
+	// time if there was none.
+	LastReload time.Time `json:"last_reload"`
+}
+
+// FileParsed implements Metrics.
+func (s *Stats) FileParsed(path string, d time.Duration, err error) {
+	s.filesParsed.Add(1)
+	s.parseDuration.Add(int64(d))
+	if err != nil {
+		s.parseErrors.Add(1)
+	}
+}
+
+// KeysLoaded implements Metrics.
+func (s *Stats) KeysLoaded(source string, n int) {
+	s.keysLoaded.Add(int64(n))
+}
+
+// Reloaded implements Metrics.
+func (s *Stats) Reloaded(d time.Duration, changes int, err error) {
+	s.reloads.Add(1)
+	s.reloadDuration.Add(int64(d))
+	if err != nil {
+		s.reloadErrors.Add(1)
+	} else {
+		s.lastReload.Store(time.Now().UnixNano())
+	}
+}
+
+// Snapshot returns the current totals.
+func (s *Stats) Snapshot() StatsSnapshot {
+	snapshot := StatsSnapshot{
+		FilesParsed:    s.filesParsed.Load(),
+		ParseErrors:    s.parseErrors.Load(),
+		ParseDuration:  time.Duration(s.parseDuration.Load()),
+		KeysLoaded:     s.keysLoaded.Load(),
+		Reloads:        s.reloads.Load(),
+		ReloadErrors:   s.reloadErrors.Load(),
+		ReloadDuration: time.Duration(s.reloadDuration.Load()),
+	}
+	if last := s.lastReload.Load(); last != 0 {
+		snapshot.LastReload = time.Unix(0, last)
+	}
+	return snapshot
+}
+
+// String returns the current totals as JSON.
+func (s *Stats) String() string {
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
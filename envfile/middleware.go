@@ -0,0 +1,88 @@
+package envfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entry is a single variable, as passed through the Middleware chain and
+// returned by Environment.Entries.
+type Entry struct {
+	Key   string
+	Value string
+	// Source is the file or Source the variable was read from, or
+	// "<input>" for Parse. It is empty when unknown.
+	Source string
+}
+
+// Middleware transforms a parsed entry before it is set or returned. It
+// may change the key and value, drop the entry by returning an empty
+// Key, or fail the load by returning an error.
+type Middleware func(Entry) (Entry, error)
+
+// WithMiddleware runs every parsed variable through mw, in order, after
+// parsing and before the variable is set by Load or returned by
+// Environment, Read and Parse. Calling WithMiddleware more than once
+// appends to the chain.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware[:len(o.middleware):len(o.middleware)], mw...)
+	}
+}
+
+// TrimValues is a Middleware that removes leading and trailing white
+// space from values.
+func TrimValues(e Entry) (Entry, error) {
+	e.Value = strings.TrimSpace(e.Value)
+	return e, nil
+}
+
+// UppercaseKeys is a Middleware that converts keys to upper case.
+func UppercaseKeys(e Entry) (Entry, error) {
+	e.Key = strings.ToUpper(e.Key)
+	return e, nil
+}
+
+// RewritePrefix returns a Middleware that replaces the prefix old of keys
+// with new. Keys without the prefix are left unchanged.
+func RewritePrefix(old, new string) Middleware {
+	return func(e Entry) (Entry, error) {
+		if strings.HasPrefix(e.Key, old) {
+			e.Key = new + e.Key[len(old):]
+		}
+		return e, nil
+	}
+}
+
+// process runs variables read from source through the configured
+// middleware and drops the keys not selected by WithKeys. The input
+// slice, which may be shared with the cache, is never modified.
+func (l *Loader) process(source string, variables []variable) ([]variable, error) {
+	if len(l.o.middleware) == 0 && len(l.o.selected) == 0 && len(l.o.translators) == 0 {
+		return variables, nil
+	}
+	processed := make([]variable, 0, len(variables))
+	for _, v := range variables {
+		if key := l.o.translate(v.key); key != v.key {
+			l.o.parse.logf("Warning: '%s' from '%s' is loaded as '%s'. Rename it in the source.", v.key, source, key)
+			v.translatedFrom = v.key
+			v.key = key
+		}
+		entry := Entry{Key: v.key, Value: v.value, Source: source}
+		for _, mw := range l.o.middleware {
+			var err error
+			if entry, err = mw(entry); err != nil {
+				return nil, fmt.Errorf("error: '%s' from '%s': %w", v.key, source, err)
+			}
+			if entry.Key == "" {
+				break
+			}
+		}
+		if entry.Key == "" || !l.o.selects(entry.Key) {
+			continue
+		}
+		v.key, v.value = entry.Key, entry.Value
+		processed = append(processed, v)
+	}
+	return processed, nil
+}
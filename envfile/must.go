@@ -0,0 +1,33 @@
+package envfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MustLoad is like Load but panics if no file could be loaded. It is meant
+// for main functions where a missing env file should abort immediately.
+func MustLoad(opts ...Option) *Result {
+	result, err := Load(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("envfile: MustLoad: %v", err))
+	}
+	return result
+}
+
+// MustRead is like Read but panics if any of the files cannot be read.
+func MustRead(filenames ...string) *Environment {
+	env, err := Read(filenames...)
+	if err != nil {
+		panic(fmt.Sprintf("envfile: MustRead(%s): %v", strings.Join(filenames, ", "), err))
+	}
+	return env
+}
+
+// MustUnmarshal is like Unmarshal but panics if the process environment
+// cannot be decoded into v.
+func MustUnmarshal(v any) {
+	if err := Unmarshal(v); err != nil {
+		panic(fmt.Sprintf("envfile: MustUnmarshal(%T): %v", v, err))
+	}
+}
@@ -0,0 +1,49 @@
+package envfile
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WithNormalizedLiterals rewrites the values of keys declared as bool,
+// int or uint, with the KEY:type=value syntax or a "# @type KEY type"
+// annotation, to a canonical spelling before they are type-checked and
+// loaded, so that files maintained by different teams produce identical
+// environments:
+//
+//   - bool values yes, y, on, enabled, 1, t and true become "true", and
+//     no, n, off, disabled, 0, f and false become "false", in any case;
+//   - int and uint values lose a leading plus sign, leading zeros and
+//     "_" digit separators, so "+0_080" becomes "80".
+//
+// Values that do not match are left as written and fail the type check
+// as usual.
+func WithNormalizedLiterals() Option {
+	return func(o *options) {
+		o.parse.normalize = true
+	}
+}
+
+// normalizeLiteral returns the canonical spelling of value for typ, or
+// value unchanged if it has none.
+func normalizeLiteral(typ, value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch typ {
+	case "bool":
+		switch strings.ToLower(trimmed) {
+		case "true", "t", "1", "yes", "y", "on", "enabled":
+			return "true"
+		case "false", "f", "0", "no", "n", "off", "disabled":
+			return "false"
+		}
+	case "int":
+		if n, err := strconv.ParseInt(strings.ReplaceAll(trimmed, "_", ""), 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case "uint":
+		if n, err := strconv.ParseUint(strings.TrimPrefix(strings.ReplaceAll(trimmed, "_", ""), "+"), 10, 64); err == nil {
+			return strconv.FormatUint(n, 10)
+		}
+	}
+	return value
+}
@@ -0,0 +1,91 @@
+package envfile
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// loadOnce holds the outcome of the first load of a LoadOnce call.
+type loadOnce struct {
+	mu     sync.Mutex
+	done   bool
+	result *Result
+	err    error
+}
+
+func (o *loadOnce) load(load func() (*Result, error)) (*Result, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.done {
+		o.result, o.err = load()
+		o.done = true
+	}
+	return o.result, o.err
+}
+
+var packageOnce loadOnce
+
+// LoadOnce loads like Load the first time it is called in the process and
+// returns the same Result and error, without loading again, on every
+// later call, whatever the options. Libraries that need the environment
+// loaded should call LoadOnce rather than Load, so that several of them
+// in one program do not load the files repeatedly, log the same lines
+// and override each other's variables. Calling Load still loads again
+// explicitly.
+func LoadOnce(opts ...Option) (*Result, error) {
+	return packageOnce.load(New(opts...).Load)
+}
+
+// LoadOnce loads like Load the first time it is called on l and returns
+// the same Result and error on every later call. Load still loads again
+// explicitly.
+func (l *Loader) LoadOnce() (*Result, error) {
+	return l.once.load(l.Load)
+}
+
+// WithDuplicateLoadWarning logs a warning when Load is called after an
+// earlier Load anywhere in the process, naming the callers of both, to
+// find libraries that load the environment on their own. LoadOnce calls
+// that do not load are not reported.
+func WithDuplicateLoadWarning() Option {
+	return func(o *options) {
+		o.warnDuplicateLoad = true
+	}
+}
+
+var (
+	lastLoadMu     sync.Mutex
+	lastLoadCaller string
+)
+
+// trackLoad records the caller of a Load and warns about an earlier one
+// if WithDuplicateLoadWarning is set.
+func (l *Loader) trackLoad() {
+	caller := loadCaller()
+	lastLoadMu.Lock()
+	previous := lastLoadCaller
+	lastLoadCaller = caller
+	lastLoadMu.Unlock()
+
+	if l.o.warnDuplicateLoad && previous != "" {
+		l.o.parse.logf("Warning: Load called from %s after an earlier Load from %s. Use LoadOnce to load the environment once.", caller, previous)
+	}
+}
+
+// loadCaller returns the location of the first caller outside this
+// package.
+func loadCaller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/lucap9056/go-envfile/envfile.") {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package envfile
+
+import (
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Option configures a Loader, or a single call to Load, Parse and similar
+// functions.
+type Option func(*options)
+
+// options holds the configuration assembled from a list of Options.
+type options struct {
+	hooks Hooks
+	keys  keyFilter
+	parse parseOptions
+
+	// selected lists the patterns set with WithKeys.
+	selected []keyMatcher
+
+	dir        string
+	filenames  []string
+	profiles   map[string][]string
+	noOverride bool
+	sources    []Source
+	merges     map[string]merge
+
+	// osBefore is the layer set with OSBefore.
+	osBefore string
+
+	// patterns reports whether filenames were set with WithPattern and
+	// contain placeholders.
+	patterns bool
+
+	caseSensitivity CaseSensitivity
+
+	// report receives the JSON report set with WithReport.
+	report io.Writer
+
+	metrics    Metrics
+	middleware []Middleware
+	// translators holds the functions set with WithKeyTranslator and
+	// WithKeyMap.
+	translators []func(string) string
+
+	// vault is set by WithVault.
+	vault bool
+
+	resolveSymlinks    bool
+	executableFallback bool
+
+	checkPermissions bool
+
+	// warnDuplicateLoad is set by WithDuplicateLoadWarning.
+	warnDuplicateLoad bool
+
+	// unsafeDump is set by WithUnsafeDump.
+	unsafeDump bool
+
+	// unknownProfileError is set by WithUnknownProfileError.
+	unknownProfileError bool
+
+	// app is the application name set with WithConfigDirs.
+	app string
+
+	// required lists the candidate names set with WithRequiredFiles.
+	required []string
+
+	// definitions is the file set with WithDefinitions.
+	definitions string
+
+	// backup is set by WithBackup.
+	backup bool
+
+	// concurrency is the number of files parsed at the same time, set by
+	// WithConcurrency.
+	concurrency int
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	o.parse.logger = log.Default()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Logger receives the messages written while loading. *log.Logger
+// implements it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// WithLogger sends log messages to logger instead of the standard logger.
+// A nil logger discards them.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		if logger == nil {
+			logger = log.New(io.Discard, "", 0)
+		}
+		o.parse.logger = logger
+	}
+}
+
+// WithDir searches for candidate files in dir instead of the current
+// working directory.
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithFilenames replaces the profile-based candidate list with names, in
+// order of precedence. GO_ENV is then ignored.
+func WithFilenames(names ...string) Option {
+	return func(o *options) {
+		o.filenames = append([]string{}, names...)
+		o.patterns = false
+	}
+}
+
+// WithProfiles replaces the built-in development, production and test
+// profiles. Each profile maps to its candidate files in order of
+// precedence. Unless strict mode is enabled, an unrecognized profile falls
+// back to "development", which should therefore be present.
+func WithProfiles(profiles map[string][]string) Option {
+	return func(o *options) {
+		o.profiles = make(map[string][]string, len(profiles))
+		names := make([]string, 0, len(profiles))
+		for name, files := range profiles {
+			o.profiles[name] = append([]string{}, files...)
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		o.parse.profiles = strings.Join(names, "\x00")
+		o.parse.profilesSet = true
+	}
+}
+
+// WithProfile selects the profile explicitly instead of reading GO_ENV.
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.parse.profile = profile
+	}
+}
+
+// WithOverride controls whether loaded variables replace variables that
+// are already set in the process environment. The default is true. When
+// false, existing variables are kept and the loaded ones are reported to
+// Hooks.OnSkip. WithOverride(false) is WithOSLayer(OSHighest), and
+// WithOverride(true) is WithOSLayer(OSLowest).
+func WithOverride(override bool) Option {
+	return func(o *options) {
+		o.noOverride = !override
+		o.osBefore = ""
+	}
+}
+
+// WithStrict turns warnings into errors: an unrecognized GO_ENV, an empty
+// key or an unresolved template variable fails the load instead of being
+// logged and ignored. It also enforces POSIX assignments, rejecting
+// whitespace around '=', which is otherwise trimmed, unless WithSpacing
+// says otherwise.
+func WithStrict() Option {
+	return func(o *options) {
+		o.parse.strict = true
+	}
+}
+
+// Spacing selects how whitespace around the '=' of a definition, as in
+// "KEY = value", is handled.
+type Spacing int
+
+const (
+	// SpacingTrim ignores the whitespace, so "KEY = value" defines KEY as
+	// "value". It is the default unless WithStrict is set.
+	SpacingTrim Spacing = iota
+	// SpacingReject fails the parse with an error wrapping ErrSyntax,
+	// since POSIX shells do not accept such assignments. It is the
+	// default with WithStrict.
+	SpacingReject
+)
+
+// WithSpacing selects how whitespace around '=' is handled, independently
+// of WithStrict.
+func WithSpacing(mode Spacing) Option {
+	return func(o *options) {
+		o.parse.spacing = mode
+		o.parse.spacingSet = true
+	}
+}
+
+// WithAllErrors reports every error found in a file at once, joined with
+// errors.Join, instead of stopping at the first, so that a broken file
+// can be fixed in one pass. Lines with errors are skipped and parsing
+// continues with the next one. It is most useful with WithStrict, which
+// turns warnings into errors. Exceeding a limit set with WithLimits still
+// stops parsing.
+func WithAllErrors() Option {
+	return func(o *options) {
+		o.parse.allErrors = true
+	}
+}
+
+// WithUniqueKeys fails parsing with an error wrapping ErrDuplicateKey
+// when a key is defined more than once in a file or the files it
+// includes. Items of KEY[]= lists, lines with a profile prefix and keys
+// with a "# @merge" strategy may still repeat a key.
+func WithUniqueKeys() Option {
+	return func(o *options) {
+		o.parse.uniqueKeys = true
+	}
+}
+
+// Expansion selects how {$name} references in values are resolved.
+type Expansion int
+
+const (
+	// ExpandVariables resolves references to $-prefixed template
+	// variables defined earlier in the file. It is the default.
+	ExpandVariables Expansion = iota
+	// ExpandEnv resolves references like ExpandVariables, falling back to
+	// the process environment, so {$HOME} resolves to $HOME if the file
+	// does not define $HOME.
+	ExpandEnv
+	// ExpandNone leaves values untouched.
+	ExpandNone
+)
+
+// WithExpansion selects how {$name} references in values are resolved.
+func WithExpansion(mode Expansion) Option {
+	return func(o *options) {
+		o.parse.expansion = mode
+	}
+}
@@ -0,0 +1,77 @@
+package envfile
+
+import "path/filepath"
+
+// OSOrigin is the origin recorded in Result.Origins for the keys that
+// kept the value they had in the process environment.
+const OSOrigin = "os"
+
+// OSLayer is the position of the process environment among the layers
+// Load applies, the loaded files and the Sources added with WithSource in
+// order, set with WithOSLayer.
+type OSLayer struct {
+	// highest is set for OSHighest.
+	highest bool
+	// before is the layer set with OSBefore.
+	before string
+}
+
+var (
+	// OSLowest ranks the process environment below every layer, so that
+	// loaded variables replace the variables already set. It is the
+	// default.
+	OSLowest = OSLayer{}
+	// OSHighest ranks the process environment above every layer, so that
+	// variables already set are kept, like WithOverride(false).
+	OSHighest = OSLayer{highest: true}
+)
+
+// OSBefore ranks the process environment between layers: above the
+// layers applied before the layer named name and below that layer and
+// the ones after it. name is matched against the path of a file, its
+// base name, or the name of a Source, such as ".env.local" or
+// "vault:secret/app". If no layer matches, the process environment
+// ranks above all of them.
+func OSBefore(name string) OSLayer {
+	return OSLayer{before: name}
+}
+
+// WithOSLayer sets the position of the process environment in the
+// precedence of the layers applied by Load, LoadFiles and the functions
+// built on them, which keeps the layering explicit when files override
+// deployment variables but local overrides should not:
+//
+//	// .env < process environment < .env.local
+//	envfile.LoadFiles(".env", ".env.local")
+//	envfile.New(envfile.WithOSLayer(envfile.OSBefore(".env.local"))).LoadFiles(".env", ".env.local")
+//
+// A layer ranked below the process environment leaves the keys it already
+// had, reports them to Hooks.OnSkip and records them with OSOrigin in
+// Result.Origins. Under OSHighest a key an earlier layer set is kept as
+// well, so the first layer to set a key wins; under OSBefore keys set by
+// an earlier layer are still replaced. WithOSLayer and WithOverride
+// replace each other, and the functions that do not apply layers, such as
+// LoadEnvironment, treat OSBefore like OSLowest.
+func WithOSLayer(layer OSLayer) Option {
+	return func(o *options) {
+		o.noOverride = layer.highest
+		o.osBefore = layer.before
+	}
+}
+
+// keepsOS reports whether the layer source ranks below the process
+// environment, recording in result when the layer set with OSBefore is
+// reached.
+func (o *options) keepsOS(source string, result *Result) bool {
+	if o.noOverride {
+		return true
+	}
+	if o.osBefore == "" || result.osOverridden {
+		return false
+	}
+	if source == o.osBefore || filepath.Base(source) == o.osBefore {
+		result.osOverridden = true
+		return false
+	}
+	return true
+}
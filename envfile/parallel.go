@@ -0,0 +1,75 @@
+package envfile
+
+import (
+	"runtime"
+	"sync"
+)
+
+// WithConcurrency parses up to n of the files given to Read, LoadFiles,
+// ReadGlob and LoadGlob at the same time, which shortens startup for
+// configuration spread over many fragments. A value of n below 1 uses
+// runtime.GOMAXPROCS(0). The variables are merged in the order of the
+// files afterwards, so the result is the same as when parsing them one
+// by one, except that LoadFiles parses every file before setting any of
+// them: {$name} references resolved with ExpandEnv and #if conditions
+// see the process environment as it was before the load. If a file
+// fails, the error is the one of the first failing file in order.
+//
+// Middleware, Formats and Metrics then run from several goroutines and
+// must be safe for concurrent use.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		if n < 1 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		o.concurrency = n
+	}
+}
+
+// parsedFile holds the outcome of parsing one of several files.
+type parsedFile struct {
+	variables []variable
+	err       error
+}
+
+// parseFiles checks and parses filenames with up to the configured
+// number of goroutines, returning the outcomes in the order of
+// filenames.
+func (l *Loader) parseFiles(filenames []string) []parsedFile {
+	parsed := make([]parsedFile, len(filenames))
+	parse := func(i int) {
+		if err := l.checkFile(filenames[i]); err != nil {
+			parsed[i].err = err
+			return
+		}
+		parsed[i].variables, parsed[i].err = l.parseFile(filenames[i])
+	}
+
+	workers := min(l.o.concurrency, len(filenames))
+	if workers <= 1 {
+		for i := range filenames {
+			if parse(i); parsed[i].err != nil {
+				break
+			}
+		}
+		return parsed
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				parse(i)
+			}
+		}()
+	}
+	for i := range filenames {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return parsed
+}
@@ -0,0 +1,996 @@
+package envfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// variable is a single resolved key/value pair parsed from an env file,
+// kept in file order.
+type variable struct {
+	key   string
+	value string
+	// typ is the declared type of the value, or empty if none was
+	// declared.
+	typ string
+	// expires is the expiry declared with "# @expires", or zero.
+	expires time.Time
+	// merge is the strategy declared with "# @merge", or zero.
+	merge merge
+	// translatedFrom is the key as written in the source if it was
+	// renamed by WithKeyTranslator.
+	translatedFrom string
+	// platform is the specificity of a platform-specific definition,
+	// such as KEY.linux, or 0.
+	platform int
+}
+
+// parseOptions controls how env files are parsed. It must remain
+// comparable, since it is part of the cache key.
+type parseOptions struct {
+	limits Limits
+
+	// template is set when files are rendered as templates, which
+	// bypasses the cache.
+	template *templateOptions
+
+	// decoders is set when values are decoded with WithDecoders, which
+	// also bypasses the cache.
+	decoders *decoderOptions
+
+	// verify is set when files must pass WithChecksum or WithPublicKey
+	// before they are parsed.
+	verify *verifyOptions
+
+	strict     bool
+	uniqueKeys bool
+	expansion  Expansion
+
+	// spacing is set by WithSpacing.
+	spacing    Spacing
+	spacingSet bool
+
+	// logger receives warnings. It is not part of the cache key.
+	logger Logger
+
+	includeDepth    int
+	includeDepthSet bool
+
+	listSeparator string
+
+	// normalize is set by WithNormalizedLiterals.
+	normalize bool
+
+	// iniCompat is set by WithINICompat.
+	iniCompat bool
+
+	// allErrors is set by WithAllErrors.
+	allErrors bool
+
+	// commentPrefixes and separators hold the prefixes and separators set
+	// with WithCommentPrefixes and WithSeparators, joined by NUL bytes so
+	// that the options stay comparable.
+	commentPrefixes    string
+	commentPrefixesSet bool
+	separators         string
+	noInlineComments   bool
+
+	// intern is set by WithInterning.
+	intern bool
+
+	// profile is the profile set with WithProfile, and profiles the names
+	// of the profiles set with WithProfiles, joined by NUL bytes; they
+	// select the lines with a profile prefix.
+	profile     string
+	profiles    string
+	profilesSet bool
+
+	// profileAliases holds the aliases set with WithProfileAliases, as
+	// encoded by joinAliases.
+	profileAliases    string
+	profileAliasesSet bool
+
+	// generatePersist is the file set with WithGeneratePersist.
+	generatePersist string
+
+	// events is set by WithEventHandler, which bypasses the cache.
+	events *eventOptions
+
+	// globals holds the template variables of the file set with
+	// WithDefinitions, as encoded by joinGlobals.
+	globals string
+}
+
+// DefaultIncludeDepth is the maximum nesting of include directives unless
+// changed with WithIncludeDepth.
+const DefaultIncludeDepth = 8
+
+// cacheKey returns the options with the fields that do not affect the
+// parse result cleared.
+func (po parseOptions) cacheKey() parseOptions {
+	po.logger = nil
+	return po
+}
+
+func (po parseOptions) logf(format string, args ...any) {
+	if po.logger == nil {
+		log.Printf(format, args...)
+		return
+	}
+	po.logger.Printf(format, args...)
+}
+
+func (po parseOptions) maxIncludeDepth() int {
+	if po.includeDepthSet {
+		return po.includeDepth
+	}
+	return DefaultIncludeDepth
+}
+
+func parseFile(filePath string, po parseOptions) ([]variable, error) {
+	variables, _, err := parseFileIncludes(filePath, po)
+	return variables, err
+}
+
+// parseFileIncludes parses the file at filePath and also returns the
+// parser, which records the files included and the process variables
+// read while parsing.
+func parseFileIncludes(filePath string, po parseOptions) ([]variable, *parser, error) {
+	p := newParser(filePath, po)
+	if err := p.parseFile(filePath); err != nil {
+		return nil, nil, err
+	}
+	variables, err := p.finish()
+	if err != nil {
+		return nil, nil, err
+	}
+	return variables, p, nil
+}
+
+// parseReader parses env file content from r. The source names the
+// content in log messages and errors.
+func parseReader(r io.Reader, source string, po parseOptions) ([]variable, error) {
+	p := newParser(source, po)
+	if err := p.parse(r); err != nil {
+		return nil, err
+	}
+	variables, err := p.finish()
+	if err != nil {
+		return nil, err
+	}
+	return variables, checkExpired(source, variables, po)
+}
+
+// parseData is like parseReader for content already in memory.
+func parseData(data []byte, source string, po parseOptions) ([]variable, error) {
+	p := newParser(source, po)
+	if err := p.parseBytes(data); err != nil {
+		return nil, err
+	}
+	variables, err := p.finish()
+	if err != nil {
+		return nil, err
+	}
+	return variables, checkExpired(source, variables, po)
+}
+
+// parser holds the state of a single parse, including the files it
+// includes.
+type parser struct {
+	options    parseOptions
+	source     string
+	lineNumber int
+
+	// stack holds the absolute paths of the files being parsed, outermost
+	// first, for cycle detection and relative include resolution.
+	stack []string
+	// includes lists the absolute paths of every included file.
+	includes []string
+	// depth is the current nesting of include directives.
+	depth int
+
+	// conditions holds the enclosing #if blocks, outermost first.
+	conditions []condition
+	// envReads records the process variables read while evaluating
+	// conditions, with the values they had.
+	envReads map[string]string
+
+	result []variable
+	// variables holds the $-prefixed template variables defined so far.
+	variables map[string]string
+	// types holds the types declared with "# @type KEY TYPE" directives.
+	types map[string]string
+	// expiries holds the expiries declared with "# @expires", and
+	// pendingExpiry one that applies to the next variable.
+	expiries      map[string]time.Time
+	pendingExpiry time.Time
+	// merges holds the strategies declared with "# @merge".
+	merges map[string]merge
+	// pendingOS and pendingArch hold the platforms of "# @os" and
+	// "# @arch" annotations that apply to the next variable, and
+	// platforms the highest specificity defined for each key.
+	pendingOS   []string
+	pendingArch []string
+	platforms   map[string]int
+	// lists maps each KEY[]= list to its index in result.
+	lists map[string]int
+	// defined records where each key was first defined, for
+	// WithUniqueKeys.
+	defined map[string]string
+	// generated holds the contents of WithGeneratePersist files by path.
+	generated map[string]map[string]string
+	// volatile reports whether the result depends on more than the
+	// files and envReads, so that it must not be cached.
+	volatile bool
+
+	// continued holds the lines joined so far by trailing backslashes,
+	// and continuedLine the number of the first of them.
+	continued     []string
+	continuedLine int
+
+	// section is the prefix of keys in the current INI section, such as
+	// "DATABASE_", with WithINICompat.
+	section string
+
+	// errs holds the errors recorded with WithAllErrors.
+	errs []error
+
+	// interned holds the strings shared with WithInterning, and size the
+	// bytes of the keys and values defined so far, for MaxTotalSize.
+	interned map[string]string
+	size     int64
+}
+
+// variableRegex matches {$name} references. It is compiled once, since
+// compiling it for every parse dominated the cost of small files.
+var variableRegex = regexp.MustCompile(`\{\$([a-zA-Z0-9_]+)\}`)
+
+func newParser(source string, po parseOptions) *parser {
+	p := &parser{
+		options:   po,
+		source:    source,
+		variables: make(map[string]string),
+		types:     make(map[string]string),
+		expiries:  make(map[string]time.Time),
+		merges:    make(map[string]merge),
+		lists:     make(map[string]int),
+		defined:   make(map[string]string),
+	}
+	splitGlobals(po.globals, p.variables)
+	return p
+}
+
+// parseFile parses the file at filePath into p. A file that must pass
+// WithChecksum or WithPublicKey is read whole, and the content that was
+// verified is parsed.
+func (p *parser) parseFile(filePath string) error {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		abs = filePath
+	}
+	p.stack = append(p.stack, abs)
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+
+	if p.options.verify != nil {
+		data, err := p.options.verify.verify(filePath)
+		if err != nil {
+			return err
+		}
+		return p.parseBytes(data)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("error: unable to open file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			p.options.logf("Error: Failed to close file '%s': %v", filePath, err)
+		}
+	}()
+
+	if p.options.limits.MaxFileSize > 0 {
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("error: unable to stat file '%s': %w: %w", filePath, err, ErrIO)
+		}
+		if info.Size() > p.options.limits.MaxFileSize {
+			return limitError(filePath, 0, "file size %d exceeds the limit of %d bytes", info.Size(), p.options.limits.MaxFileSize)
+		}
+	}
+
+	// Read the file line by line, so that MaxLineLength bounds the memory
+	// used even without MaxFileSize.
+	return p.parse(file)
+}
+
+// parseBytes parses env file content from data into p. Unless the content
+// is rendered as a template, it is converted to a string once and split
+// into lines in place, so that lines and values share its memory instead
+// of being allocated one by one.
+func (p *parser) parseBytes(data []byte) error {
+	if p.options.template != nil {
+		return p.parse(bytes.NewReader(data))
+	}
+
+	limits := p.options.limits
+	if limits.MaxFileSize > 0 && int64(len(data)) > limits.MaxFileSize {
+		return limitError(p.source, 0, "file size %d exceeds the limit of %d bytes", len(data), limits.MaxFileSize)
+	}
+
+	data, err := toUTF8(data, p.source)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+	for content != "" {
+		line, rest, _ := strings.Cut(content, "\n")
+		content = rest
+		p.lineNumber++
+		line = strings.TrimSuffix(line, "\r")
+		if p.lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if err := p.feedLine(line); err != nil {
+			if err := p.fail(err); err != nil {
+				return err
+			}
+		}
+	}
+	if err := p.flushContinued(""); err != nil {
+		return p.fail(err)
+	}
+	return nil
+}
+
+// parse parses env file content from r into p.
+func (p *parser) parse(r io.Reader) error {
+	limits := p.options.limits
+	if limits.MaxFileSize > 0 {
+		r = &limitedReader{r: r, remaining: limits.MaxFileSize, source: p.source}
+	}
+	r, err := readerToUTF8(r, p.source)
+	if err != nil {
+		return err
+	}
+
+	if p.options.template != nil {
+		rendered, err := p.options.template.render(r, p.source)
+		if err != nil {
+			return err
+		}
+		r = rendered
+	}
+
+	scanner := bufio.NewScanner(r)
+	if limits.MaxLineLength > 0 {
+		scanner.Buffer(make([]byte, 0, min(limits.MaxLineLength+1, 4096)), limits.MaxLineLength+1)
+	}
+	for scanner.Scan() {
+		p.lineNumber++
+		line := scanner.Text()
+		if p.lineNumber == 1 {
+			// Editors on Windows often prefix UTF-8 files with a byte order mark.
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if err := p.feedLine(line); err != nil {
+			if err := p.fail(err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) && limits.MaxLineLength > 0 {
+			return limitError(p.source, p.lineNumber+1, "line length exceeds the limit of %d bytes", limits.MaxLineLength)
+		}
+		if errors.Is(err, ErrLimitExceeded) {
+			return err
+		}
+		return fmt.Errorf("error: failed to read file '%s': %w: %w", p.source, err, ErrIO)
+	}
+
+	if err := p.flushContinued(""); err != nil {
+		return p.fail(err)
+	}
+	return nil
+}
+
+// fail handles an error found while parsing a line. It returns err,
+// which stops parsing, unless WithAllErrors is set: the error is then
+// recorded for finish to report and parsing continues with the next
+// line. Limit errors always stop parsing, together with the errors
+// recorded so far.
+func (p *parser) fail(err error) error {
+	if !p.options.allErrors {
+		return err
+	}
+	if errors.Is(err, ErrLimitExceeded) {
+		errs := append(p.errs, err)
+		p.errs = nil
+		return errors.Join(errs...)
+	}
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// feedLine parses a physical line, first joining lines that end in a
+// backslash with the line that follows, as in POSIX shells. The backslash
+// is removed, and so is the indentation of continuation lines, so that
+//
+//	HOSTS=a.example.com,\
+//	      b.example.com
+//
+// defines "a.example.com,b.example.com". The joined line is parsed as a
+// single line numbered after its first line.
+func (p *parser) feedLine(line string) error {
+	if len(p.continued) > 0 {
+		line = strings.TrimLeft(line, " \t")
+	}
+	if continuesLine(line) {
+		if len(p.continued) == 0 {
+			p.continuedLine = p.lineNumber
+		}
+		p.continued = append(p.continued, line[:len(line)-1])
+		return nil
+	}
+	if len(p.continued) == 0 {
+		return p.lineError(line, p.parseLine(line))
+	}
+	return p.flushContinued(line)
+}
+
+// flushContinued parses the lines joined so far followed by last, if any
+// lines were joined.
+func (p *parser) flushContinued(last string) error {
+	if len(p.continued) == 0 {
+		return nil
+	}
+	// The last line read is itself continued only at the end of input.
+	line := strings.Join(p.continued, "") + last
+	if last == "" && p.lineNumber == p.continuedLine+len(p.continued)-1 {
+		message := fmt.Sprintf("line %d of '%s' ends in a backslash, but no line follows. The value may be truncated.", p.lineNumber, p.source)
+		if err := p.options.warn(EventTruncated, p.source, p.lineNumber, "", message); err != nil {
+			p.continued = nil
+			return p.lineError(line, err)
+		}
+	}
+	p.continued = nil
+	lineNumber := p.lineNumber
+	p.lineNumber = p.continuedLine
+	err := p.lineError(line, p.parseLine(line))
+	p.lineNumber = lineNumber
+	return err
+}
+
+// warnTruncated logs a warning if the value of key looks truncated by the
+// format, since a silently truncated secret is hard to diagnose: cut at a
+// '#' that directly follows it, opened with a quote that is not closed,
+// or ending in a backslash.
+func (p *parser) warnTruncated(key, value string, cut bool) error {
+	if reason := truncation(value, cut); reason != "" {
+		return p.options.warn(EventTruncated, p.source, p.lineNumber, key, fmt.Sprintf("value of '%s' in '%s' at line %d %s. The value may be truncated.", key, p.source, p.lineNumber, reason))
+	}
+	return nil
+}
+
+// truncation describes why value looks truncated, or returns an empty
+// string. Cut reports whether a comment directly followed the value.
+func truncation(value string, cut bool) string {
+	switch {
+	case value == "":
+		return ""
+	case cut:
+		return "is cut at a '#' that starts a comment; quote the value if the '#' is part of it"
+	case (value[0] == '"' || value[0] == '\'') && closingQuote(value) == -1:
+		return "starts with a quote that is not closed"
+	case continuesLine(value):
+		return "ends in a backslash"
+	}
+	return ""
+}
+
+// continuesLine reports whether line is continued on the next line: it
+// ends in a single backslash and contains no comment, so that comments
+// ending in a backslash, and values ending in two backslashes, are left
+// alone.
+func continuesLine(line string) bool {
+	return strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") && clearAfterHash(line) == line
+}
+
+func (p *parser) parseLine(line string) error {
+	limits := p.options.limits
+
+	if limits.MaxLineLength > 0 && len(line) > limits.MaxLineLength {
+		return limitError(p.source, p.lineNumber, "line length %d exceeds the limit of %d bytes", len(line), limits.MaxLineLength)
+	}
+
+	if handled, err := p.conditional(line); handled {
+		return err
+	}
+
+	if !p.active() {
+		return nil
+	}
+
+	if path, ok := parseInclude(line); ok {
+		return p.include(path)
+	}
+
+	if name, args, ok := parseAnnotation(line); ok {
+		return p.annotate(name, args)
+	}
+
+	if p.options.iniCompat && strings.HasPrefix(strings.TrimSpace(line), ";") {
+		return nil
+	}
+
+	stripped := p.options.stripComment(line)
+	// A comment that follows the value without a blank in between is
+	// more likely part of the value.
+	cut := len(stripped) < len(line) && strings.TrimRight(stripped, " \t") == stripped
+	line = stripped
+
+	line = strings.TrimSpace(line)
+
+	if len(line) == 0 {
+		return nil
+	}
+
+	if p.options.iniCompat {
+		if section, ok := parseSection(line); ok {
+			p.section = section
+			return nil
+		}
+	}
+
+	profile, rest, prefixed := splitProfilePrefix(line)
+	if prefixed {
+		if profile != p.profile() {
+			return nil
+		}
+		line = rest
+	}
+
+	if p.options.rejectsSpacing() && p.options.spacedAssignment(line) {
+		return errorAt("=", "write KEY=value without spaces around '='", fmt.Errorf("error: whitespace around '=' in '%s' at line %d: '%s': %w", p.source, p.lineNumber, line, ErrSyntax))
+	}
+
+	key, value := p.options.splitLine(line)
+
+	if key == "" {
+		return p.emptyKey(line)
+	}
+
+	if err := p.warnTruncated(key, value, cut); err != nil {
+		return err
+	}
+	value = unquote(value)
+
+	if limits.MaxValueLength > 0 && len(value) > limits.MaxValueLength {
+		return limitError(p.source, p.lineNumber, "value length %d of '%s' exceeds the limit of %d bytes", len(value), key, limits.MaxValueLength)
+	}
+
+	if key[0] == '$' {
+
+		p.variables[key] = value
+
+	} else {
+
+		key, typ := splitKeyType(key)
+		if key == "" {
+			// A line such as ":int=5" names a type but no key.
+			return p.emptyKey(line)
+		}
+		key, isList := splitListKey(key)
+		specificity := 0
+		if !isList {
+			var matches bool
+			if key, matches, specificity = p.platform(key); !matches {
+				p.pendingExpiry = time.Time{}
+				return nil
+			}
+		}
+		if p.section != "" {
+			key = p.section + strings.ToUpper(key)
+		}
+
+		value, err := p.expand(value)
+		if err != nil {
+			return err
+		}
+
+		value, err = p.transform(value)
+		if err != nil {
+			return err
+		}
+
+		value, err = p.generate(key, value)
+		if err != nil {
+			return err
+		}
+
+		value, err = p.decode(key, value)
+		if err != nil {
+			return err
+		}
+
+		if limits.MaxValueLength > 0 && len(value) > limits.MaxValueLength {
+			return limitError(p.source, p.lineNumber, "value length %d of '%s' exceeds the limit of %d bytes", len(value), key, limits.MaxValueLength)
+		}
+
+		if typ != "" {
+			if p.options.normalize {
+				value = normalizeLiteral(typ, value)
+			}
+			if err := checkType(typ, value); err != nil {
+				return fmt.Errorf("error: '%s' at line %d: value of '%s': %v: %w", p.source, p.lineNumber, key, err, ErrSyntax)
+			}
+		}
+
+		if limits.MaxTotalSize > 0 {
+			if p.size += int64(len(key) + len(value)); p.size > limits.MaxTotalSize {
+				return limitError(p.source, p.lineNumber, "total size of the variables exceeds the limit of %d bytes", limits.MaxTotalSize)
+			}
+		}
+
+		if isList {
+			if err := p.addListItem(variable{key: p.intern(key), value: p.intern(value), typ: typ}); err != nil {
+				return err
+			}
+		} else {
+			if err := p.checkUnique(key, prefixed || specificity > 0 || p.platforms[key] > 0); err != nil {
+				return err
+			}
+			delete(p.lists, key)
+			p.result = append(p.result, variable{key: p.intern(key), value: p.intern(value), typ: typ, platform: specificity})
+			if specificity > p.platforms[key] {
+				if p.platforms == nil {
+					p.platforms = make(map[string]int)
+				}
+				p.platforms[key] = specificity
+			}
+		}
+		if !p.pendingExpiry.IsZero() {
+			p.expiries[key] = p.pendingExpiry
+			p.pendingExpiry = time.Time{}
+		}
+
+		if limits.MaxVariables > 0 && len(p.result) > limits.MaxVariables {
+			return limitError(p.source, p.lineNumber, "number of variables exceeds the limit of %d", limits.MaxVariables)
+		}
+
+	}
+
+	return nil
+}
+
+// checkUnique enforces WithUniqueKeys for a definition of key.
+func (p *parser) checkUnique(key string, prefixed bool) error {
+	if !p.options.uniqueKeys {
+		return nil
+	}
+	if first, exists := p.defined[key]; exists {
+		if _, merged := p.merges[key]; !prefixed && !merged {
+			return errorAt(key, "remove one of the definitions, or declare how they combine with '# @merge'", fmt.Errorf("error: '%s' at line %d: '%s' is already defined at %s: %w", p.source, p.lineNumber, key, first, ErrDuplicateKey))
+		}
+		return nil
+	}
+	p.defined[key] = fmt.Sprintf("%s:%d", p.source, p.lineNumber)
+	return nil
+}
+
+// emptyKey reports line, whose key is empty: it is an error in strict
+// mode, and otherwise skipped with a warning.
+func (p *parser) emptyKey(line string) error {
+	if p.options.strict {
+		return errorAt("=", "add a key before '='", fmt.Errorf("error: empty key found in '%s' at line %d: '%s': %w", p.source, p.lineNumber, line, ErrSyntax))
+	}
+	return p.options.warn(EventSkippedLine, p.source, p.lineNumber, "", fmt.Sprintf("Empty key found in '%s' at line %d: '%s'. Skipping.", p.source, p.lineNumber, line))
+}
+
+// expand resolves the {$name} references in value according to the
+// configured Expansion.
+func (p *parser) expand(value string) (string, error) {
+	if p.options.expansion == ExpandNone || !strings.Contains(value, "{$") {
+		return value, nil
+	}
+
+	var err error
+	value = variableRegex.ReplaceAllStringFunc(value, func(s string) string {
+		k := s[1 : len(s)-1]
+		if v, exists := p.variables[k]; exists {
+			return v
+		}
+		if v, exists := p.builtin(k); exists {
+			return v
+		}
+		if p.options.expansion == ExpandEnv {
+			if v := p.getenv(k[1:]); v != "" {
+				return v
+			}
+		}
+		if p.options.strict {
+			if err == nil {
+				err = errorAt(s, fmt.Sprintf("define %s=value on a line above", k), fmt.Errorf("error: variable '%s' not found in '%s' at line %d: %w", s, p.source, p.lineNumber, ErrUnresolvedVar))
+			}
+			return ""
+		}
+		if werr := p.options.warn(EventUnresolved, p.source, p.lineNumber, s, fmt.Sprintf("variable '%s' not found in '%s' at line %d.", s, p.source, p.lineNumber)); werr != nil && err == nil {
+			err = errorAt(s, "", werr)
+		}
+		return ""
+	})
+	return value, err
+}
+
+// annotate handles a "# @name args..." annotation comment.
+func (p *parser) annotate(name string, args []string) error {
+	switch name {
+	case "type":
+		if len(args) != 2 {
+			return fmt.Errorf("error: '%s' at line %d: expected '# @type KEY TYPE': %w", p.source, p.lineNumber, ErrSyntax)
+		}
+		if _, known := typeCheckers[args[1]]; !known {
+			return errorAt(args[1], "use string, int, uint, float, bool, duration or url", fmt.Errorf("error: '%s' at line %d: unknown type '%s': %w", p.source, p.lineNumber, args[1], ErrSyntax))
+		}
+		p.types[args[0]] = args[1]
+	case "expires":
+		return p.annotateExpires(args)
+	case "merge":
+		return p.annotateMerge(args)
+	case "os", "arch":
+		return p.annotatePlatform(name, args)
+	default:
+		// Unknown annotations are ordinary comments.
+	}
+	return nil
+}
+
+// finish applies the types, expiries and merge strategies declared by
+// annotations and returns the parsed variables.
+func (p *parser) finish() ([]variable, error) {
+	if len(p.conditions) > 0 {
+		if err := p.fail(p.unclosedError()); err != nil {
+			return nil, err
+		}
+	}
+
+	p.dropGeneric()
+	for i := range p.result {
+		v := &p.result[i]
+		if expires, declared := p.expiries[v.key]; declared {
+			v.expires = expires
+		}
+		if m, declared := p.merges[v.key]; declared {
+			v.merge = m
+		}
+		typ, declared := p.types[v.key]
+		if !declared {
+			continue
+		}
+		if v.typ != "" && v.typ != typ {
+			err := fmt.Errorf("error: '%s': '%s' is declared as both '%s' and '%s': %w", p.source, v.key, v.typ, typ, ErrSyntax)
+			if err := p.fail(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		items := []string{v.value}
+		if _, isList := p.lists[v.key]; isList && v.value != "" {
+			items = strings.Split(v.value, p.options.separator())
+		}
+		valid := true
+		for i, item := range items {
+			if p.options.normalize {
+				item = normalizeLiteral(typ, item)
+				items[i] = item
+			}
+			if err := checkType(typ, item); err != nil {
+				if err := p.fail(fmt.Errorf("error: '%s': value of '%s': %v: %w", p.source, v.key, err, ErrSyntax)); err != nil {
+					return nil, err
+				}
+				valid = false
+				break
+			}
+		}
+		if !valid {
+			continue
+		}
+		if p.options.normalize {
+			v.value = strings.Join(items, p.options.separator())
+		}
+		v.typ = typ
+	}
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
+
+// include parses the file at path, resolved relative to the directory of
+// the file containing the directive, as if its lines appeared in place of
+// the directive. Template variables defined by the included file remain
+// available afterwards.
+func (p *parser) include(path string) error {
+	if p.depth >= p.options.maxIncludeDepth() {
+		return fmt.Errorf("error: '%s' at line %d: includes are nested deeper than %d levels: %w", p.source, p.lineNumber, p.options.maxIncludeDepth(), ErrSyntax)
+	}
+
+	// Accept Windows-style separators in files shared across platforms.
+	path = filepath.FromSlash(strings.ReplaceAll(path, "\\", "/"))
+
+	if !filepath.IsAbs(path) && len(p.stack) > 0 {
+		path = filepath.Join(filepath.Dir(p.stack[len(p.stack)-1]), path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	for i, file := range p.stack {
+		if file == abs {
+			chain := append(append([]string{}, p.stack[i:]...), abs)
+			return fmt.Errorf("error: '%s' at line %d: include cycle: %s: %w", p.source, p.lineNumber, strings.Join(chain, " -> "), ErrSyntax)
+		}
+	}
+
+	p.includes = append(p.includes, abs)
+
+	source, lineNumber := p.source, p.lineNumber
+	conditions, section := p.conditions, p.section
+	p.source, p.lineNumber, p.conditions, p.section = path, 0, nil, ""
+	p.depth++
+	err = p.parseFile(path)
+	if err == nil && len(p.conditions) > 0 {
+		err = p.unclosedError()
+	}
+	p.depth--
+	p.source, p.lineNumber, p.conditions, p.section = source, lineNumber, conditions, section
+	return err
+}
+
+// parseInclude recognizes "#include path" and "source path" directives.
+func parseInclude(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	var rest string
+	switch {
+	case strings.HasPrefix(line, "#include "), strings.HasPrefix(line, "#include\t"):
+		rest = line[len("#include"):]
+	case strings.HasPrefix(line, "source ") && !strings.Contains(line, "="):
+		rest = line[len("source"):]
+	default:
+		return "", false
+	}
+	path := strings.TrimSpace(rest)
+	if isQuoted(path) {
+		path = path[1 : len(path)-1]
+	}
+	return path, path != ""
+}
+
+// parseAnnotation recognizes comment lines of the form "# @name args...".
+func parseAnnotation(line string) (name string, args []string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#") {
+		return "", nil, false
+	}
+	line = strings.TrimSpace(line[1:])
+	if !strings.HasPrefix(line, "@") {
+		return "", nil, false
+	}
+	fields := strings.Fields(line[1:])
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	return fields[0], fields[1:], true
+}
+
+// clearAfterHash returns s without its comment, which starts at the
+// first '#' outside a quoted value. A value is quoted when it starts with
+// a single or double quote that is closed later on the line, so that
+// NOTE="issue #42" # tracked keeps its '#' and drops "# tracked".
+func clearAfterHash(s string) string {
+	start := 0
+	if eq := strings.Index(s, "="); eq != -1 && !strings.Contains(s[:eq], "#") {
+		value := strings.TrimLeft(s[eq+1:], " \t")
+		if end := closingQuote(value); end != -1 {
+			start = len(s) - len(value) + end + 1
+		}
+	}
+	if index := strings.Index(s[start:], "#"); index != -1 {
+		return s[:start+index]
+	}
+	return s
+}
+
+// closingQuote returns the index of the quote closing the quote that
+// value starts with, or -1 if value does not start with a quote or the
+// quote is not closed. Within double quotes, a backslash escapes the
+// next character.
+func closingQuote(value string) int {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return -1
+	}
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case value[0]:
+			return i
+		case '\\':
+			if value[0] == '"' {
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+// unquote returns the content of a value wrapped in quotes, so that
+// NOTE="issue #42" defines NOTE as issue #42. A single-quoted value is
+// taken as written; in a double-quoted value, \n, \r and \t stand for a
+// line feed, a carriage return and a tab, \" and \\ for a quote and a
+// backslash, and other backslashes are kept, so that a Windows path
+// mostly reads as written; single quotes keep it exactly. Values that are
+// not wrapped in quotes are returned unchanged.
+func unquote(value string) string {
+	if len(value) < 2 || closingQuote(value) != len(value)-1 {
+		return value
+	}
+	inner := value[1 : len(value)-1]
+	if value[0] == '\'' || !strings.Contains(inner, `\`) {
+		return inner
+	}
+	var b strings.Builder
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c == '\\' && i+1 < len(inner) {
+			switch inner[i+1] {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case '"', '\\':
+				c = inner[i+1]
+			default:
+				b.WriteByte(c)
+				continue
+			}
+			i++
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// splitLine splits a KEY=value line at its first '='. Spaces and tabs
+// around the '=' are not part of the key or the value, so "KEY = value"
+// defines KEY as "value".
+func splitLine(s string) (key string, value string) {
+	index := strings.Index(s, "=")
+	if index == -1 {
+		return s, ""
+	}
+	return strings.TrimRight(s[:index], " \t"), strings.TrimLeft(s[index+1:], " \t")
+}
+
+// spacedAssignment reports whether the '=' of a KEY=value line is
+// surrounded by spaces or tabs, which POSIX shells do not accept.
+func spacedAssignment(s string) bool {
+	index := strings.Index(s, "=")
+	if index == -1 {
+		return false
+	}
+	before, after := s[:index], s[index+1:]
+	return strings.HasSuffix(before, " ") || strings.HasSuffix(before, "\t") || strings.HasPrefix(after, " ") || strings.HasPrefix(after, "\t")
+}
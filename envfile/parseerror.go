@@ -0,0 +1,143 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseError locates an error found while parsing env file content, so
+// that command-line tools and editor integrations can point at it. Every
+// error tied to a line of a file, such as a malformed definition, an
+// unresolved variable in strict mode or a limit exceeded by a line, is
+// returned as a *ParseError, possibly joined with others by
+// WithAllErrors; ParseErrors extracts them:
+//
+//	for _, pe := range envfile.ParseErrors(err) {
+//		fmt.Fprintf(os.Stderr, "%v\n%s\n", pe, pe.Excerpt())
+//	}
+//
+// Its message is the message of Err, which names the file and line.
+type ParseError struct {
+	// File is the path of the file, or the name of the reader, containing
+	// the error.
+	File string
+	// Line is the number of the line containing the error, and Column
+	// the byte offset in that line of the text at fault, both counting
+	// from 1. Column is 0 if the error concerns the whole line.
+	Line   int
+	Column int
+	// Source is the text of the line, with the lines continuing it joined
+	// and without its line ending. It is empty if the line is unknown.
+	Source string
+	// Hint suggests how to fix the error, or is empty.
+	Hint string
+	// Err is the underlying error, which wraps a sentinel such as
+	// ErrSyntax.
+	Err error
+
+	// token is the text at fault, located in Source to set Column.
+	token string
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Excerpt renders the offending line with a caret under the text at
+// fault when Column is known, followed by the hint, for caret-style
+// diagnostics printed after the message:
+//
+//	    3 | PORT = 8080
+//	      |      ^
+//	hint: write KEY=value without spaces around '='
+//
+// It returns an empty string if neither the line nor a hint is known.
+func (e *ParseError) Excerpt() string {
+	var lines []string
+	if e.Source != "" {
+		lines = append(lines, fmt.Sprintf("%5d | %s", e.Line, e.Source))
+		if e.Column > 0 && e.Column <= len(e.Source)+1 {
+			// Keep tabs so that the caret lines up with the text.
+			var pad strings.Builder
+			for _, r := range e.Source[:e.Column-1] {
+				if r == '\t' {
+					pad.WriteByte('\t')
+				} else {
+					pad.WriteByte(' ')
+				}
+			}
+			lines = append(lines, fmt.Sprintf("%5s | %s^", "", pad.String()))
+		}
+	}
+	if e.Hint != "" {
+		lines = append(lines, "hint: "+e.Hint)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseErrors returns every *ParseError in the tree of err, which
+// includes the errors joined by WithAllErrors, in order.
+func ParseErrors(err error) []*ParseError {
+	var found []*ParseError
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *ParseError:
+			found = append(found, e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+	return found
+}
+
+// errorAt returns err as a ParseError pointing at the first occurrence
+// of token in the line being parsed, with hint. The parser fills in the
+// location.
+func errorAt(token, hint string, err error) error {
+	return &ParseError{Hint: hint, Err: err, token: token}
+}
+
+// lineError returns err, found while parsing line, as a ParseError
+// located at the current line. An error already located, such as one
+// found in an included file, is returned unchanged.
+func (p *parser) lineError(line string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		return &ParseError{File: p.source, Line: p.lineNumber, Source: line, Err: err}
+	}
+	if pe.File == "" {
+		pe.File, pe.Line, pe.Source = p.source, p.lineNumber, line
+		if i := strings.Index(line, pe.token); pe.token != "" && i >= 0 {
+			pe.Column = i + 1
+		}
+	}
+	return err
+}
+
+// unclosedError returns the error for an #if block of the current file
+// not closed with #endif.
+func (p *parser) unclosedError() error {
+	c := p.conditions[len(p.conditions)-1]
+	return &ParseError{
+		File: p.source,
+		Line: c.line,
+		Hint: "close the block with #endif",
+		Err:  fmt.Errorf("error: '%s': #if at line %d is not closed with #endif: %w", p.source, c.line, ErrSyntax),
+	}
+}
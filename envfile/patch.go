@@ -0,0 +1,120 @@
+package envfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ApplyPatch applies the env file at patchPath onto the env file at
+// basePath, so that automated rollouts can change a few keys of a
+// hand-maintained file without rewriting it. Every KEY=value line of the
+// patch sets KEY in base as Set does, keeping the comments, order and
+// formatting of base; keys base does not define are appended in the order
+// of the patch.
+//
+// A patch deletes a key with a "-KEY" line or with an empty "KEY=" line,
+// a tombstone; every unconditional definition of the key is removed from
+// base, along with the lines continuing its value. Values are copied as
+// written, quotes included, and are not interpolated, and the comments,
+// blank lines and directives of the patch are ignored. Base is locked
+// with LockFile while it is updated, and replaced atomically, only if the
+// whole patch applies; opts may include WithBackup to keep its previous
+// content.
+func ApplyPatch(basePath, patchPath string, opts ...Option) error {
+	patch, err := os.ReadFile(patchPath)
+	if err != nil {
+		return fmt.Errorf("error: unable to read file '%s': %w: %w", patchPath, err, ErrIO)
+	}
+	lock, err := LockFile(basePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	content, err := os.ReadFile(basePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error: unable to read file '%s': %w: %w", basePath, err, ErrIO)
+	}
+
+	updated, err := applyPatch(content, patch, patchPath)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(basePath, updated, 0o600, opts...)
+}
+
+// applyPatch returns content with patch, read from source, applied to it
+// as described for ApplyPatch.
+func applyPatch(content, patch []byte, source string) ([]byte, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(patch))
+	scanner.Buffer(nil, len(patch)+1)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(clearAfterHash(strings.TrimSpace(scanner.Text())))
+		if line == "" || strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		if key, found := strings.CutPrefix(line, "-"); found {
+			content = deleteValue(content, strings.TrimSpace(key))
+			continue
+		}
+
+		k, value := splitLine(line)
+		key, _ := splitKeyType(strings.TrimSpace(k))
+		if err := checkKey(key); err != nil {
+			return nil, patchError(source, lineNumber, scanner.Text(), fmt.Errorf("error: '%s' at line %d: %v: %w", source, lineNumber, err, ErrSyntax))
+		}
+		if continuesLine(value) {
+			return nil, patchError(source, lineNumber, scanner.Text(), fmt.Errorf("error: '%s' at line %d: values of a patch cannot continue on the next line: %w", source, lineNumber, ErrSyntax))
+		}
+		if value == "" {
+			content = deleteValue(content, key)
+			continue
+		}
+
+		var err error
+		if content, err = setValue(content, key, value); err != nil {
+			return nil, patchError(source, lineNumber, scanner.Text(), fmt.Errorf("error: '%s' at line %d: %v: %w", source, lineNumber, err, ErrSyntax))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error: unable to read file '%s': %w: %w", source, err, ErrIO)
+	}
+	return content, nil
+}
+
+// patchError returns err, found at line number of a patch, as a
+// ParseError.
+func patchError(source string, number int, line string, err error) error {
+	return &ParseError{File: source, Line: number, Source: line, Err: err}
+}
+
+// deleteValue returns content without the unconditional definitions of
+// key and the lines continuing their values.
+func deleteValue(content []byte, key string) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	definitions, _ := definitionLines(lines, key)
+	if len(definitions) == 0 {
+		return content
+	}
+
+	kept := lines[:0]
+	next := 0
+	for i := 0; i < len(lines); i++ {
+		if next < len(definitions) && definitions[next] == i {
+			next++
+			for continuesLine(strings.TrimRight(lines[i], "\r\n")) && i+1 < len(lines) {
+				i++
+			}
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	return []byte(strings.Join(kept, ""))
+}
@@ -0,0 +1,86 @@
+package envfile
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// WithPattern replaces the candidate list with patterns, in order of
+// precedence, whose {placeholders} are filled in when files are looked
+// up:
+//
+//	{profile}   the profile from WithProfile or GO_ENV, or "development"
+//	{os}        runtime.GOOS
+//	{arch}      runtime.GOARCH
+//	{hostname}  the host name
+//	{name}      any other name is the process variable NAME, upper-cased,
+//	            so {region} is the value of $REGION
+//
+// A pattern with a placeholder that resolves to an empty value is
+// skipped, so
+//
+//	envfile.Load(envfile.WithPattern(".env.{profile}.{region}", ".env.{profile}", ".env"))
+//
+// tries .env.production.eu-west-1 only when REGION is set. Like
+// WithFilenames, WithPattern makes Load ignore the configured profiles.
+func WithPattern(patterns ...string) Option {
+	return func(o *options) {
+		o.filenames = append([]string{}, patterns...)
+		o.patterns = true
+	}
+}
+
+// expandPatterns returns the candidate names of the patterns set with
+// WithPattern for the profile env.
+func (o *options) expandPatterns(env string) []string {
+	env = o.patternProfile(env)
+
+	names := make([]string, 0, len(o.filenames))
+	for _, pattern := range o.filenames {
+		if name, ok := expandPattern(pattern, env); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// expandPattern fills in the placeholders of pattern. It reports false if
+// one of them resolves to an empty value.
+func expandPattern(pattern, profile string) (string, bool) {
+	var b strings.Builder
+	for {
+		start := strings.Index(pattern, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(pattern[start:], "}")
+		if end == -1 {
+			break
+		}
+		name := pattern[start+1 : start+end]
+
+		var value string
+		switch name {
+		case "profile":
+			value = profile
+		case "os":
+			value = runtime.GOOS
+		case "arch":
+			value = runtime.GOARCH
+		case "hostname":
+			value, _ = os.Hostname()
+		default:
+			value = os.Getenv(strings.ToUpper(name))
+		}
+		if value == "" {
+			return "", false
+		}
+
+		b.WriteString(pattern[:start])
+		b.WriteString(value)
+		pattern = pattern[start+end+1:]
+	}
+	b.WriteString(pattern)
+	return b.String(), true
+}
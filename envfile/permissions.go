@@ -0,0 +1,49 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ErrInsecurePermissions is wrapped by the error returned when a file is
+// rejected by the permission check enabled with WithPermissionCheck.
+var ErrInsecurePermissions = errors.New("insecure file permissions")
+
+// AllowInsecurePermissionsEnv names the environment variable that, when
+// set to a true value such as "1" or "true", disables the permission check
+// enabled with WithPermissionCheck. It lets an operator override the check
+// without rebuilding the application.
+const AllowInsecurePermissionsEnv = "ENVFILE_ALLOW_INSECURE_PERMISSIONS"
+
+// WithPermissionCheck makes Load refuse env files that are readable by
+// other users, writable by the group or other users, or owned by a user
+// other than the current user or root, similar to the checks ssh performs
+// on private key files. Rejected files are treated like files that failed
+// to load. The check is not performed on platforms without Unix
+// permissions, or when AllowInsecurePermissionsEnv is set.
+func WithPermissionCheck() Option {
+	return func(o *options) {
+		o.checkPermissions = true
+	}
+}
+
+// CheckPermissions performs the check enabled by WithPermissionCheck on a
+// single file, regardless of AllowInsecurePermissionsEnv. The returned
+// error wraps ErrInsecurePermissions if the file is rejected.
+func CheckPermissions(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("error: unable to stat file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	if reason := insecurePermissions(info); reason != "" {
+		return fmt.Errorf("error: refusing to load '%s': %s: %w", filePath, reason, ErrInsecurePermissions)
+	}
+	return nil
+}
+
+func permissionCheckOverridden() bool {
+	allowed, err := strconv.ParseBool(os.Getenv(AllowInsecurePermissionsEnv))
+	return err == nil && allowed
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package envfile
+
+import "os"
+
+// insecurePermissions never rejects a file on platforms without Unix
+// permission bits; access there is governed by ACLs instead.
+func insecurePermissions(info os.FileInfo) string {
+	return ""
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func insecurePermissions(info os.FileInfo) string {
+	mode := info.Mode().Perm()
+	if mode&0o022 != 0 {
+		return fmt.Sprintf("file mode %04o allows writing by other users", mode)
+	}
+	if mode&0o004 != 0 {
+		return fmt.Sprintf("file mode %04o allows reading by other users", mode)
+	}
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		uid := int(stat.Uid)
+		if uid != 0 && uid != os.Getuid() {
+			return fmt.Sprintf("file is owned by uid %d, not the current user", uid)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,71 @@
+package envfile
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// builtinVariables are the template variables available in every file
+// without being defined, resolved when the file is parsed:
+//
+//	{$HOSTNAME}     the host name
+//	{$PID}          the process ID
+//	{$USER}         the name of the current user
+//	{$CWD}          the current working directory
+//	{$RANDOM_UUID}  a new random (version 4) UUID for every reference
+//
+// A $-prefixed variable defined in the file takes precedence. The
+// volatile ones, whose value can differ between two loads in the same
+// process, keep the file from being cached.
+var builtinVariables = map[string]struct {
+	resolve  func() (string, error)
+	volatile bool
+}{
+	"$HOSTNAME":    {resolve: os.Hostname},
+	"$PID":         {resolve: func() (string, error) { return strconv.Itoa(os.Getpid()), nil }},
+	"$USER":        {resolve: currentUser},
+	"$CWD":         {resolve: os.Getwd, volatile: true},
+	"$RANDOM_UUID": {resolve: randomUUID, volatile: true},
+}
+
+// builtin resolves the built-in variable name, such as "$PID".
+func (p *parser) builtin(name string) (string, bool) {
+	b, exists := builtinVariables[name]
+	if !exists {
+		return "", false
+	}
+	value, err := b.resolve()
+	if err != nil {
+		p.options.logf("Warning: unable to resolve '{%s}' in '%s' at line %d: %v", name, p.source, p.lineNumber, err)
+		return "", false
+	}
+	if b.volatile {
+		p.volatile = true
+	}
+	return value, true
+}
+
+func currentUser() (string, error) {
+	if u, err := user.Current(); err == nil {
+		return u.Username, nil
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown user")
+}
+
+func randomUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,108 @@
+package envfile
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// knownOS and knownArch list the values of runtime.GOOS and
+// runtime.GOARCH that are recognized as key suffixes.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "illumos": true, "ios": true, "js": true,
+		"linux": true, "netbsd": true, "openbsd": true, "plan9": true,
+		"solaris": true, "wasip1": true, "windows": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "arm": true, "arm64": true,
+		"loong64": true, "mips": true, "mips64": true, "mips64le": true,
+		"mipsle": true, "ppc64": true, "ppc64le": true, "riscv64": true,
+		"s390x": true, "wasm": true,
+	}
+)
+
+// platformKey splits the platform suffixes off a key written as
+// KEY.linux, KEY.arm64 or KEY.linux.arm64, returning the key without them
+// and the operating system and architecture they name, or empty strings.
+// Segments that are not known platforms are part of the key, so
+// hierarchical keys such as DATABASE.HOST are unaffected.
+func platformKey(key string) (base, goos, goarch string) {
+	base = key
+	if i := strings.LastIndexByte(base, '.'); i > 0 && knownArch[base[i+1:]] {
+		base, goarch = base[:i], base[i+1:]
+	}
+	if i := strings.LastIndexByte(base, '.'); i > 0 && knownOS[base[i+1:]] {
+		base, goos = base[:i], base[i+1:]
+	}
+	return base, goos, goarch
+}
+
+// annotatePlatform handles "# @os NAME[,NAME...]" and
+// "# @arch NAME[,NAME...]", which restrict the next variable to the
+// listed operating systems or architectures.
+func (p *parser) annotatePlatform(name string, args []string) error {
+	known := knownOS
+	if name == "arch" {
+		known = knownArch
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("error: '%s' at line %d: expected '# @%s NAME[,NAME...]': %w", p.source, p.lineNumber, name, ErrSyntax)
+	}
+	names := strings.Split(args[0], ",")
+	for _, n := range names {
+		if !known[n] {
+			return fmt.Errorf("error: '%s' at line %d: unknown %s '%s': %w", p.source, p.lineNumber, name, n, ErrSyntax)
+		}
+	}
+	if name == "os" {
+		p.pendingOS = names
+	} else {
+		p.pendingArch = names
+	}
+	return nil
+}
+
+// platform resolves the platform of the definition of key on the
+// current line, from its suffixes and any pending "# @os" and "# @arch"
+// annotations, which it consumes. It returns the key without suffixes,
+// whether the definition applies to the running platform, and its
+// specificity: 0 for every platform, 1 for an operating system or an
+// architecture, and 2 for both.
+func (p *parser) platform(key string) (string, bool, int) {
+	key, goos, goarch := platformKey(key)
+	matches := (goos == "" || goos == runtime.GOOS) && (goarch == "" || goarch == runtime.GOARCH)
+	if p.pendingOS != nil {
+		matches = matches && containsString(p.pendingOS, runtime.GOOS)
+	}
+	if p.pendingArch != nil {
+		matches = matches && containsString(p.pendingArch, runtime.GOARCH)
+	}
+
+	specificity := 0
+	if goos != "" || p.pendingOS != nil {
+		specificity++
+	}
+	if goarch != "" || p.pendingArch != nil {
+		specificity++
+	}
+	p.pendingOS, p.pendingArch = nil, nil
+	return key, matches, specificity
+}
+
+// dropGeneric removes the definitions of keys that a more specific
+// platform definition overrides, so that KEY.linux wins over KEY on Linux
+// wherever each appears in the file.
+func (p *parser) dropGeneric() {
+	if len(p.platforms) == 0 {
+		return
+	}
+	kept := p.result[:0]
+	for _, v := range p.result {
+		if v.platform >= p.platforms[v.key] {
+			kept = append(kept, v)
+		}
+	}
+	p.result = kept
+}
@@ -0,0 +1,261 @@
+package envfile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sink receives variables, the counterpart of Source, so that the
+// variables of an Environment can be pushed to a secret manager or
+// another configuration system.
+type Sink interface {
+	// Name identifies the sink in errors.
+	Name() string
+	// Write stores values in the sink.
+	Write(ctx context.Context, values map[string]string) error
+}
+
+// SourceFactory returns the Source addressed by uri, such as
+// "consul://127.0.0.1:8500/app/prod/".
+type SourceFactory func(uri string) (Source, error)
+
+// SinkFactory returns the Sink addressed by uri.
+type SinkFactory func(uri string) (Sink, error)
+
+var (
+	pluginsMu sync.RWMutex
+	sources   = map[string]SourceFactory{
+		"exec": openExecSource,
+		"file": openFileSource,
+	}
+	sinks = map[string]SinkFactory{
+		"exec": openExecSink,
+		"file": openFileSink,
+	}
+)
+
+// RegisterSource makes OpenSource use factory for URIs starting with
+// "scheme:", so that third-party packages can add sources for other
+// configuration systems, typically from an init function or a Register
+// function of their own like kv.RegisterSources. It replaces any factory
+// already registered for scheme, and a nil factory removes it. The
+// built-in sources are:
+//
+//	file:PATH          the variables of the env file at PATH
+//	exec:COMMAND ARGS  the env file content printed by COMMAND, run with
+//	                   ARGS split at spaces, for plugins written in any
+//	                   language
+func RegisterSource(scheme string, factory SourceFactory) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if factory == nil {
+		delete(sources, scheme)
+		return
+	}
+	sources[scheme] = factory
+}
+
+// RegisterSink makes OpenSink use factory for URIs starting with
+// "scheme:", like RegisterSource. The built-in sinks are:
+//
+//	file:PATH          writes the variables to the env file at PATH,
+//	                   atomically
+//	exec:COMMAND ARGS  runs COMMAND with the variables as env file
+//	                   content on its standard input
+func RegisterSink(scheme string, factory SinkFactory) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if factory == nil {
+		delete(sinks, scheme)
+		return
+	}
+	sinks[scheme] = factory
+}
+
+// Sources returns the schemes registered with RegisterSource in sorted
+// order.
+func Sources() []string {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	schemes := make([]string, 0, len(sources))
+	for scheme := range sources {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Sinks returns the schemes registered with RegisterSink in sorted order.
+func Sinks() []string {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	schemes := make([]string, 0, len(sinks))
+	for scheme := range sinks {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// OpenSource returns the Source addressed by uri, using the factory
+// registered for its scheme, the part before the first ':'. The factory
+// receives the whole uri.
+//
+//	src, err := envfile.OpenSource("exec:vault-env --path secret/app")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	result, err := envfile.Load(envfile.WithSource(src))
+func OpenSource(uri string) (Source, error) {
+	scheme, _, _ := strings.Cut(uri, ":")
+	pluginsMu.RLock()
+	factory, found := sources[scheme]
+	pluginsMu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("error: no source registered for scheme '%s' of '%s'", scheme, uri)
+	}
+	return factory(uri)
+}
+
+// OpenSink returns the Sink addressed by uri, using the factory
+// registered for its scheme, like OpenSource.
+func OpenSink(uri string) (Sink, error) {
+	scheme, _, _ := strings.Cut(uri, ":")
+	pluginsMu.RLock()
+	factory, found := sinks[scheme]
+	pluginsMu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("error: no sink registered for scheme '%s' of '%s'", scheme, uri)
+	}
+	return factory(uri)
+}
+
+// WriteSink writes the variables of e to sink.
+func (e *Environment) WriteSink(ctx context.Context, sink Sink) error {
+	if err := sink.Write(ctx, e.Map()); err != nil {
+		return fmt.Errorf("error: failed to write environment variables to '%s': %w", sink.Name(), err)
+	}
+	return nil
+}
+
+// fileSource reads an env file, for the "file" scheme.
+type fileSource struct {
+	path string
+}
+
+func openFileSource(uri string) (Source, error) {
+	path := strings.TrimPrefix(uri, "file:")
+	if path == "" {
+		return nil, fmt.Errorf("error: source '%s' names no file", uri)
+	}
+	return &fileSource{path: path}, nil
+}
+
+func (s *fileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *fileSource) Fetch(ctx context.Context) (map[string]string, error) {
+	env, err := Read(s.path)
+	if err != nil {
+		return nil, err
+	}
+	return env.Map(), nil
+}
+
+// fileSink writes an env file, for the "file" scheme.
+type fileSink struct {
+	path string
+}
+
+func openFileSink(uri string) (Sink, error) {
+	path := strings.TrimPrefix(uri, "file:")
+	if path == "" {
+		return nil, fmt.Errorf("error: sink '%s' names no file", uri)
+	}
+	return &fileSink{path: path}, nil
+}
+
+func (s *fileSink) Name() string {
+	return "file:" + s.path
+}
+
+func (s *fileSink) Write(ctx context.Context, values map[string]string) error {
+	data, err := Marshal(values)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0o600, false)
+}
+
+// execPlugin runs an external plugin, for the "exec" scheme.
+type execPlugin struct {
+	uri  string
+	args []string
+}
+
+func openExecPlugin(uri string) (*execPlugin, error) {
+	args := strings.Fields(strings.TrimPrefix(uri, "exec:"))
+	if len(args) == 0 {
+		return nil, fmt.Errorf("error: plugin '%s' names no command", uri)
+	}
+	return &execPlugin{uri: uri, args: args}, nil
+}
+
+func openExecSource(uri string) (Source, error) {
+	return openExecPlugin(uri)
+}
+
+func openExecSink(uri string) (Sink, error) {
+	return openExecPlugin(uri)
+}
+
+func (p *execPlugin) Name() string {
+	return p.uri
+}
+
+// run runs the plugin with stdin as its standard input and returns its
+// standard output, or an error including its standard error.
+func (p *execPlugin) run(ctx context.Context, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, p.args[0], p.args[1:]...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Fetch runs the plugin and parses its standard output as an env file.
+func (p *execPlugin) Fetch(ctx context.Context) (map[string]string, error) {
+	out, err := p.run(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	env, err := ParseBytes(out)
+	if err != nil {
+		return nil, err
+	}
+	return env.Map(), nil
+}
+
+// Write runs the plugin with values written as an env file on its
+// standard input.
+func (p *execPlugin) Write(ctx context.Context, values map[string]string) error {
+	data, err := Marshal(values)
+	if err != nil {
+		return err
+	}
+	_, err = p.run(ctx, data)
+	return err
+}
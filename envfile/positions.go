@@ -0,0 +1,230 @@
+package envfile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NodeKind classifies the lines of a file returned by ParseWithPositions.
+type NodeKind int
+
+const (
+	// NodeBlank is an empty line or a line of spaces and tabs.
+	NodeBlank NodeKind = iota
+	// NodeComment is a comment line.
+	NodeComment
+	// NodeAnnotation is a "# @name args" annotation, such as "# @type".
+	NodeAnnotation
+	// NodeDirective is an #include, source, #if, #elif, #else or #endif
+	// line.
+	NodeDirective
+	// NodeVariable is a KEY=value definition, including the lines
+	// continuing its value.
+	NodeVariable
+)
+
+func (k NodeKind) String() string {
+	switch k {
+	case NodeBlank:
+		return "blank"
+	case NodeComment:
+		return "comment"
+	case NodeAnnotation:
+		return "annotation"
+	case NodeDirective:
+		return "directive"
+	case NodeVariable:
+		return "variable"
+	}
+	return fmt.Sprintf("NodeKind(%d)", int(k))
+}
+
+// Position is a location in a file. Offset counts bytes from the start of
+// the file, from 0; Line and Column count from 1, and Column counts bytes
+// from the start of the line.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Span is the part of a file from Start up to, but not including, End.
+// The span of something absent, such as the comment of a line without
+// one, is empty.
+type Span struct {
+	Start, End Position
+}
+
+// Empty reports whether the span covers no bytes.
+func (s Span) Empty() bool {
+	return s.Start.Offset == s.End.Offset
+}
+
+// Node is a line of a file, or a definition together with the lines
+// continuing its value, as returned by ParseWithPositions.
+type Node struct {
+	Kind NodeKind
+	// Span covers the node, without its final line ending.
+	Span Span
+	// Text is the raw text of Span.
+	Text string
+
+	// Key, Type and Profile are the name, declared type suffix and
+	// "@profile" prefix of a NodeVariable; Key is "$NAME" for template
+	// variables and includes the brackets of KEY[] list items.
+	Key     string
+	KeySpan Span
+	Type    string
+	Profile string
+	// Value is the value of a NodeVariable as written, with its quotes
+	// and the backslashes and line breaks of continued lines, and not
+	// expanded.
+	Value     string
+	ValueSpan Span
+
+	// Comment is the inline comment after a value, starting with '#', or
+	// the whole text of a NodeComment or NodeAnnotation.
+	Comment     string
+	CommentSpan Span
+	// Doc holds the indexes of the comment lines directly above a
+	// NodeVariable, with no blank line in between, which describe it.
+	// Annotations between them are skipped.
+	Doc []int
+}
+
+// ParseWithPositions splits the content of r into nodes, one per line
+// except for continued values, recording where each part of a line is
+// in the file, for editor plugins, language servers and linters that
+// report precise diagnostics or rewrite files. Like ParseExample, it
+// reads the text as written in the default syntax: nothing is expanded,
+// no directive is evaluated and every branch of an #if block is
+// returned. A byte-order mark at the start of the file is part of no
+// node.
+//
+// Lines that are not valid definitions are returned as NodeVariable
+// nodes with whatever key they start with, so that callers can report
+// them; only a failure to read r is an error.
+func ParseWithPositions(r io.Reader) ([]Node, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read input: %w: %w", err, ErrIO)
+	}
+	content := string(data)
+	if content == "" {
+		return nil, nil
+	}
+
+	var lineStarts []int
+	for start := 0; ; {
+		lineStarts = append(lineStarts, start)
+		end := strings.IndexByte(content[start:], '\n')
+		if end == -1 || start+end+1 == len(content) {
+			break
+		}
+		start += end + 1
+	}
+	pos := func(offset int) Position {
+		line := sort.SearchInts(lineStarts, offset+1) - 1
+		return Position{Offset: offset, Line: line + 1, Column: offset - lineStarts[line] + 1}
+	}
+	span := func(start, end int) Span {
+		return Span{Start: pos(start), End: pos(end)}
+	}
+	// lineEnd returns the offset of the end of the line starting at start,
+	// before its line ending.
+	lineEnd := func(start int) int {
+		end := strings.IndexByte(content[start:], '\n')
+		if end == -1 {
+			return len(content)
+		}
+		end += start
+		if end > start && content[end-1] == '\r' {
+			end--
+		}
+		return end
+	}
+
+	var nodes []Node
+	var doc []int
+	for i := 0; i < len(lineStarts); i++ {
+		start := lineStarts[i]
+		if i == 0 && strings.HasPrefix(content, "\ufeff") {
+			start += len("\ufeff")
+		}
+		end := lineEnd(start)
+		line := content[start:end]
+		trimmed := strings.TrimSpace(line)
+		lead := start + len(line) - len(strings.TrimLeft(line, " \t"))
+
+		node := Node{Kind: NodeVariable}
+		if _, ok := parseInclude(trimmed); ok {
+			node.Kind = NodeDirective
+		} else if _, _, ok := parseAnnotation(trimmed); ok {
+			node.Kind = NodeAnnotation
+		} else if trimmed == "" {
+			node.Kind = NodeBlank
+		} else if _, _, ok := conditionalDirective(trimmed); ok {
+			node.Kind = NodeDirective
+		} else if strings.HasPrefix(trimmed, "#") {
+			node.Kind = NodeComment
+		}
+
+		if node.Kind == NodeVariable {
+			// Extend the node over the lines continuing its value.
+			for continuesLine(content[lineStarts[i]:end]) && i+1 < len(lineStarts) {
+				i++
+				end = lineEnd(lineStarts[i])
+			}
+			positionVariable(&node, content, lead, end, span)
+			node.Doc = doc
+		}
+		if node.Kind == NodeComment || node.Kind == NodeAnnotation {
+			node.Comment = trimmed
+			node.CommentSpan = span(lead, lead+len(trimmed))
+		}
+		node.Span = span(start, end)
+		node.Text = content[start:end]
+
+		switch node.Kind {
+		case NodeComment:
+			doc = append(doc, len(nodes))
+		case NodeAnnotation:
+		default:
+			doc = nil
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// positionVariable fills in the parts of the definition from offset start
+// to end of content.
+func positionVariable(node *Node, content string, start, end int, span func(int, int) Span) {
+	text := content[start:end]
+	stripped := clearAfterHash(text)
+	if len(stripped) < len(text) {
+		node.Comment = text[len(stripped):]
+		node.CommentSpan = span(start+len(stripped), end)
+	}
+	body := strings.TrimRight(stripped, " \t")
+
+	keyStart := start
+	if profile, rest, ok := splitProfilePrefix(body); ok {
+		node.Profile = profile
+		keyStart += len(body) - len(rest)
+		body = rest
+	}
+
+	key, value := splitLine(body)
+	valueStart := keyStart + len(body) - len(value)
+	if !strings.Contains(body, "=") {
+		valueStart = keyStart + len(body)
+	}
+	name, typ := splitKeyType(key)
+	node.Key, node.Type = name, typ
+	node.KeySpan = span(keyStart, keyStart+len(name))
+	node.Value = value
+	node.ValueSpan = span(valueStart, valueStart+len(value))
+}
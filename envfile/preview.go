@@ -0,0 +1,105 @@
+package envfile
+
+// Cascade describes how the candidate files of a profile combine, as
+// returned by Preview.
+type Cascade struct {
+	// Files lists the candidate files that exist, in order of precedence.
+	// The first is the file Load would try first.
+	Files []string
+	// Keys lists every key defined by any of the files, in the order of
+	// their first definition in the file of highest precedence that
+	// defines them, followed by keys only defined in files of lower
+	// precedence.
+	Keys []CascadeKey
+}
+
+// CascadeKey describes every definition of a key in a Cascade.
+type CascadeKey struct {
+	Key string
+	// Value is the winning value, from File, the file of highest
+	// precedence that defines the key.
+	Value string
+	File  string
+	// Definitions lists the value given to the key by each file that
+	// defines it, in order of precedence, so Definitions[0] is the
+	// winning definition.
+	Definitions []Definition
+}
+
+// Definition is the value a file gives to a key.
+type Definition struct {
+	File  string
+	Value string
+}
+
+// Conflict reports whether the files defining the key disagree on its
+// value.
+func (k CascadeKey) Conflict() bool {
+	for _, d := range k.Definitions[1:] {
+		if d.Value != k.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflicts returns the keys whose files disagree on their value.
+func (c *Cascade) Conflicts() []CascadeKey {
+	var conflicts []CascadeKey
+	for _, k := range c.Keys {
+		if k.Conflict() {
+			conflicts = append(conflicts, k)
+		}
+	}
+	return conflicts
+}
+
+// Preview parses every existing candidate file of the profile env, like
+// Discover finds them, and reports for each key every file defining it
+// and the winning value of a merge of all the files, in which files of
+// higher precedence override files of lower precedence. An empty env
+// selects the profile like Load does. Nothing is set on the process
+// environment.
+//
+// Load itself only loads the first file that succeeds, so for keys that
+// file defines, the winning value is the value Load sets. Preview is
+// meant for debugging layered setups: Conflicts lists the keys defined
+// with different values in several layers. Sources added with WithSource
+// are not included.
+func Preview(env string, opts ...Option) (*Cascade, error) {
+	return New(opts...).Preview(env)
+}
+
+// Preview reports how the candidate files of the profile env combine. See
+// the package-level Preview.
+func (l *Loader) Preview(env string) (*Cascade, error) {
+	files, err := l.Discover("", env)
+	if err != nil {
+		return nil, err
+	}
+
+	cascade := &Cascade{Files: files}
+	index := make(map[string]int)
+	for _, filePath := range files {
+		if err := l.checkFile(filePath); err != nil {
+			return nil, err
+		}
+		variables, err := l.parseFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		// Within a file, the last definition of a key wins.
+		fileEnv := l.newEnvironment(variables)
+		for _, key := range fileEnv.keys {
+			d := Definition{File: filePath, Value: fileEnv.values[key]}
+			i, exists := index[key]
+			if !exists {
+				index[key] = len(cascade.Keys)
+				cascade.Keys = append(cascade.Keys, CascadeKey{Key: key, Value: d.Value, File: filePath, Definitions: []Definition{d}})
+				continue
+			}
+			cascade.Keys[i].Definitions = append(cascade.Keys[i].Definitions, d)
+		}
+	}
+	return cascade, nil
+}
@@ -0,0 +1,155 @@
+package envfile
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultProfileAliases maps common abbreviations of profile names to the
+// built-in profiles. It is used unless WithProfileAliases is set.
+var DefaultProfileAliases = map[string]string{
+	"dev":     "development",
+	"develop": "development",
+	"prod":    "production",
+	"testing": "test",
+}
+
+// WithProfileAliases replaces DefaultProfileAliases with aliases, which
+// maps alternative names of profiles, such as "prod", to the configured
+// profiles. A nil map disables aliases.
+func WithProfileAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		o.parse.profileAliases = joinAliases(aliases)
+		o.parse.profileAliasesSet = true
+	}
+}
+
+// WithUnknownProfileError fails Load with an error wrapping
+// ErrUnknownProfile when GO_ENV, or the profile set with WithProfile,
+// names no configured profile or alias, instead of defaulting to
+// "development". WithStrict implies it.
+func WithUnknownProfileError() Option {
+	return func(o *options) {
+		o.unknownProfileError = true
+	}
+}
+
+// joinAliases encodes aliases as sorted "alias NUL profile NUL" pairs,
+// so that the options stay comparable.
+func joinAliases(aliases map[string]string) string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(strings.ToLower(name))
+		b.WriteByte(0)
+		b.WriteString(aliases[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// profileAlias returns the profile that name is an alias of.
+func (po parseOptions) profileAlias(name string) (string, bool) {
+	if !po.profileAliasesSet {
+		profile, found := DefaultProfileAliases[name]
+		return profile, found
+	}
+	fields := strings.Split(po.profileAliases, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == name {
+			return fields[i+1], true
+		}
+	}
+	return "", false
+}
+
+// profileMap returns the profiles set with WithProfiles, without their
+// candidate files, or the built-in profiles.
+func (po parseOptions) profileMap() map[string][]string {
+	if !po.profilesSet {
+		return envFileMap
+	}
+	profiles := make(map[string][]string)
+	for _, name := range strings.Split(po.profiles, "\x00") {
+		profiles[name] = nil
+	}
+	return profiles
+}
+
+// resolveProfile returns the profile of profiles that env names, directly,
+// in another case or with surrounding spaces, or through an alias. It
+// reports false if env names none of them.
+func (po parseOptions) resolveProfile(env string, profiles map[string][]string) (string, bool) {
+	if _, exists := profiles[env]; exists {
+		return env, true
+	}
+	normalized := strings.ToLower(strings.TrimSpace(env))
+	if _, exists := profiles[normalized]; exists {
+		return normalized, true
+	}
+	if profile, found := po.profileAlias(normalized); found {
+		if _, exists := profiles[profile]; exists {
+			return profile, true
+		}
+	}
+	return env, false
+}
+
+// profileName returns env, or the profile set with WithProfile or GO_ENV
+// if env is empty.
+func (o *options) profileName(env string) string {
+	if env == "" {
+		env = o.parse.profile
+	}
+	if env == "" {
+		env = os.Getenv("GO_ENV")
+	}
+	return env
+}
+
+// selectedProfile returns the configured profile named by env, or by
+// WithProfile or GO_ENV if env is empty, and whether there is one. An
+// unknown profile is returned as given.
+func (o *options) selectedProfile(env string) (string, bool) {
+	profiles := o.profiles
+	if profiles == nil {
+		profiles = envFileMap
+	}
+	return o.parse.resolveProfile(o.profileName(env), profiles)
+}
+
+// patternProfile returns the profile substituted into WithPattern
+// patterns for env: the name given, or the profile it is an alias of, or
+// "development" if none is selected.
+func (o *options) patternProfile(env string) string {
+	env = o.profileName(env)
+	if env == "" {
+		return "development"
+	}
+	if profile, found := o.parse.profileAlias(strings.ToLower(strings.TrimSpace(env))); found {
+		return profile
+	}
+	return env
+}
+
+// resultProfile returns the profile Load uses, for Result.Profile: the
+// selected profile, or "development" in place of an unknown one, or
+// empty if the candidate files were set with WithFilenames.
+func (o *options) resultProfile() string {
+	switch {
+	case o.patterns:
+		return o.patternProfile("")
+	case o.filenames != nil:
+		return ""
+	}
+	if profile, known := o.selectedProfile(""); known {
+		return profile
+	}
+	return "development"
+}
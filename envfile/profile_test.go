@@ -0,0 +1,41 @@
+package envfile_test
+
+import (
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestProfilePrefix(t *testing.T) {
+	const content = "@development KEY=development\n@production KEY=production\n@staging KEY=staging\n"
+	staging := envfile.WithProfiles(map[string][]string{
+		"development": {".env"},
+		"staging":     {".env.staging"},
+	})
+	tests := []struct {
+		name  string
+		goEnv string
+		opts  []envfile.Option
+		want  string
+	}{
+		{name: "default", want: "development"},
+		{name: "GO_ENV", goEnv: "production", want: "production"},
+		{name: "alias", goEnv: "prod", want: "production"},
+		{name: "WithProfile", opts: []envfile.Option{envfile.WithProfile("production")}, want: "production"},
+		{name: "WithProfile over GO_ENV", goEnv: "test", opts: []envfile.Option{envfile.WithProfile("production")}, want: "production"},
+		{name: "WithProfiles", goEnv: "staging", opts: []envfile.Option{staging}, want: "staging"},
+		{name: "unknown profile", goEnv: "staging", want: "development"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GO_ENV", tt.goEnv)
+			env, err := envfile.ParseBytes([]byte(content), append(tt.opts, envfile.WithLogger(nil))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Get("KEY"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package envfile
+
+import "errors"
+
+// NewEnvironment returns the configuration the process would see after
+// Load with opts, without modifying the process environment. It serves
+// as a constructor for dependency injection frameworks, so that
+// components receive the configuration as a dependency instead of
+// reading globals populated at init time:
+//
+//	fx.New(
+//		fx.Provide(func() (*envfile.Environment, error) {
+//			return envfile.NewEnvironment(envfile.WithProfile("production"))
+//		}),
+//		fx.Invoke(func(env *envfile.Environment) { ... }),
+//	)
+//
+// With wire, reference NewEnvironment in a provider set the same way; for
+// gin, echo and plain net/http, call it in main and pass the result to
+// the handlers that need it.
+//
+// The result holds the variables of the file selected as Load does, of
+// the Sources and of the defaults registered with SetDefaults for keys
+// neither defines. Keys already set in the process environment take
+// their process value where Load would keep it: for defaults, and for
+// every key with WithOverride(false). Unlike LoadEnvironment, a missing
+// file is not an error, since deployments commonly configure production
+// through the process environment alone; the result then holds the
+// Sources and defaults.
+func NewEnvironment(opts ...Option) (*Environment, error) {
+	return New(opts...).NewEnvironment()
+}
+
+// NewEnvironment returns the configuration the process would see after
+// Load, without modifying the process environment. See the package-level
+// NewEnvironment.
+func (l *Loader) NewEnvironment() (*Environment, error) {
+	var variables []variable
+	_, err := l.loadFirst(func(filePath string) error {
+		if err := l.checkFile(filePath); err != nil {
+			return err
+		}
+		vars, err := l.parseFile(filePath)
+		if err != nil {
+			return err
+		}
+		variables = vars
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNoFileLoaded) {
+		return nil, err
+	}
+	sourced, err := l.readSources()
+	if err != nil {
+		return nil, err
+	}
+	variables = append(variables[:len(variables):len(variables)], sourced...)
+
+	defined := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		defined[v.key] = true
+	}
+	fromDefaults := make(map[string]bool)
+	for _, v := range l.registeredDefaults() {
+		if !defined[v.key] {
+			variables = append(variables, v)
+			fromDefaults[v.key] = true
+		}
+	}
+
+	filtered := variables[:0:0]
+	for _, v := range variables {
+		if _, denied := l.o.keys.denied(v.key, l.o.foldCase()); !denied {
+			filtered = append(filtered, v)
+		}
+	}
+	env := l.newEnvironment(filtered)
+	// Defaults never replace process variables, and file variables only
+	// do with the default override policy.
+	for _, key := range env.keys {
+		if !l.o.noOverride && !fromDefaults[key] {
+			continue
+		}
+		if value, exists := lookupEnv(key, env.foldCase); exists {
+			env.values[key] = value
+		}
+	}
+	return env, nil
+}
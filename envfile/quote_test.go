@@ -0,0 +1,39 @@
+package envfile_test
+
+import (
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestQuotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		value   string
+	}{
+		{name: "unquoted", content: `KEY=a b`, value: `a b`},
+		{name: "double", content: `KEY="a b"`, value: `a b`},
+		{name: "single", content: `KEY='a b'`, value: `a b`},
+		{name: "empty", content: `KEY=""`, value: ``},
+		{name: "hash", content: `KEY="issue #42" # tracked`, value: `issue #42`},
+		{name: "escapes", content: `KEY="a\nb\t\"c\" \\"`, value: "a\nb\t\"c\" \\"},
+		{name: "escaped quote before hash", content: `KEY="a\"#b" # c`, value: `a"#b`},
+		{name: "other backslashes", content: `KEY="C:\dir\sub"`, value: `C:\dir\sub`},
+		{name: "single is literal", content: `KEY='a\nb'`, value: `a\nb`},
+		{name: "text after quote", content: `KEY="a" b`, value: `"a" b`},
+		{name: "inner quotes", content: `KEY=a "b" c`, value: `a "b" c`},
+		{name: "reference", content: "$HOST=db\nKEY=\"{$HOST}:5432\"", value: `db:5432`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := envfile.ParseBytes([]byte(tt.content + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Get("KEY"); got != tt.value {
+				t.Errorf("got %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package envfile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// MinRedactedLength is the length below which NewRedactor ignores a
+// value, since scrubbing every occurrence of a short string such as "1"
+// or "dev" would mangle ordinary log output.
+const MinRedactedLength = 4
+
+// Redactor scrubs secret values from text, replacing every occurrence
+// with Mask. It can wrap an io.Writer or a slog.Handler, so that secrets
+// leaked by third-party logging never reach the output:
+//
+//	redactor := env.Redactor()
+//	log.SetOutput(redactor.Writer(os.Stderr))
+//	slog.SetDefault(slog.New(redactor.Handler(slog.NewTextHandler(os.Stderr, nil))))
+//
+// A Redactor is safe for concurrent use.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// NewRedactor returns a Redactor for the given secret values. Values
+// shorter than MinRedactedLength are ignored. Longer values are replaced
+// first, so that a secret containing another is scrubbed whole.
+func NewRedactor(secrets ...string) *Redactor {
+	var values []string
+	for _, value := range secrets {
+		if len(value) >= MinRedactedLength && !containsString(values, value) {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	pairs := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		pairs = append(pairs, value, Mask)
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Redactor returns a Redactor for the values of e that DefaultDetector
+// classifies as secrets.
+func (e *Environment) Redactor() *Redactor {
+	return e.RedactorWith(DefaultDetector)
+}
+
+// RedactorWith is like Redactor but classifies secrets with d.
+func (e *Environment) RedactorWith(d *Detector) *Redactor {
+	var secrets []string
+	for _, key := range e.keys {
+		if value := e.values[key]; d.IsSecret(key, value) {
+			secrets = append(secrets, value)
+		}
+	}
+	return NewRedactor(secrets...)
+}
+
+// Redact returns s with every secret replaced by Mask.
+func (r *Redactor) Redact(s string) string {
+	return r.replacer.Replace(s)
+}
+
+// Writer returns an io.Writer that redacts what is written to it before
+// passing it on to w. Each Write is redacted on its own, so a secret
+// split across two calls is not recognized; loggers such as the log
+// package write whole lines. Write reports the length of its input on
+// success, even though a different number of bytes reaches w.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &redactWriter{r: r, w: w}
+}
+
+type redactWriter struct {
+	r *Redactor
+	w io.Writer
+}
+
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.r.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Handler returns a slog.Handler that redacts the message and the
+// attributes of every record before passing it on to h. Values of other
+// kinds than strings are redacted through their fmt form, and replaced by
+// a string only if they contain a secret.
+func (r *Redactor) Handler(h slog.Handler) slog.Handler {
+	return &redactHandler{r: r, h: h}
+}
+
+type redactHandler struct {
+	r *Redactor
+	h slog.Handler
+}
+
+func (rh *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return rh.h.Enabled(ctx, level)
+}
+
+func (rh *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, rh.r.Redact(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(rh.r.attr(a))
+		return true
+	})
+	return rh.h.Handle(ctx, redacted)
+}
+
+func (rh *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = rh.r.attr(a)
+	}
+	return &redactHandler{r: rh.r, h: rh.h.WithAttrs(redacted)}
+}
+
+func (rh *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{r: rh.r, h: rh.h.WithGroup(name)}
+}
+
+// attr returns a with its value redacted.
+func (r *Redactor) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]any, len(group))
+		for i, g := range group {
+			attrs[i] = r.attr(g)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		s := fmt.Sprint(v.Any())
+		if redacted := r.Redact(s); redacted != s {
+			return slog.String(a.Key, redacted)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
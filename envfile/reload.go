@@ -0,0 +1,248 @@
+package envfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Change describes how the value of a key differs between two
+// Environments.
+type Change struct {
+	Key string
+	// Old is the previous value, or empty if Added is set.
+	Old string
+	// New is the current value, or empty if Removed is set.
+	New string
+	// Added reports that the key was not set before.
+	Added bool
+	// Removed reports that the key is no longer set.
+	Removed bool
+}
+
+// Diff returns the changes that turn old into new: changed and added keys
+// in the order of new, followed by removed keys in the order of old.
+// Either Environment may be nil, which is treated as empty.
+func Diff(old, new *Environment) []Change {
+	var changes []Change
+	for _, key := range new.keysOrNil() {
+		value := new.values[key]
+		prior, existed := old.lookupOrNil(key)
+		switch {
+		case !existed:
+			changes = append(changes, Change{Key: key, New: value, Added: true})
+		case prior != value:
+			changes = append(changes, Change{Key: key, Old: prior, New: value})
+		}
+	}
+	for _, key := range old.keysOrNil() {
+		if _, exists := new.lookupOrNil(key); !exists {
+			changes = append(changes, Change{Key: key, Old: old.values[key], Removed: true})
+		}
+	}
+	return changes
+}
+
+func (e *Environment) keysOrNil() []string {
+	if e == nil {
+		return nil
+	}
+	return e.keys
+}
+
+func (e *Environment) lookupOrNil(key string) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	return e.Lookup(key)
+}
+
+// Reloader re-reads the environment on demand or whenever the process
+// receives a signal, the usual way for daemons to pick up configuration
+// changes without restarting:
+//
+//	r := envfile.NewReloader(true)
+//	r.OnReload(func(env *envfile.Environment, changes []envfile.Change) {
+//		log.Printf("reloaded %d variables", len(changes))
+//	})
+//	if _, err := r.Reload(); err != nil {
+//		log.Fatal(err)
+//	}
+//	go r.Run(ctx) // reload on SIGHUP
+//
+// Reloader is safe for concurrent use.
+type Reloader struct {
+	loader *Loader
+	setenv bool
+
+	// reloading serializes reloads, so that their changes are applied in
+	// order.
+	reloading sync.Mutex
+
+	mu        sync.Mutex
+	env       *Environment
+	callbacks []func(*Environment, []Change)
+}
+
+// NewReloader returns a Reloader that selects and reads files the same way
+// LoadEnvironment does with the given options. If setenv is set, each
+// reload also applies its changes to the process environment.
+func NewReloader(setenv bool, opts ...Option) *Reloader {
+	return New(opts...).NewReloader(setenv)
+}
+
+// NewReloader returns a Reloader that reads the environment with l. See
+// the package-level NewReloader.
+func (l *Loader) NewReloader(setenv bool) *Reloader {
+	return &Reloader{loader: l, setenv: setenv}
+}
+
+// OnReload registers fn to be called after every reload that changes the
+// environment, with the new snapshot and the changes. Callbacks run in
+// the order they were registered, on the goroutine that reloads.
+func (r *Reloader) OnReload(fn func(env *Environment, changes []Change)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callbacks = append(r.callbacks, fn)
+}
+
+// Environment returns the snapshot read by the last successful reload, or
+// nil before the first one.
+func (r *Reloader) Environment() *Environment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.env
+}
+
+// Reload reads the environment again and returns its changes since the
+// previous reload; the first reload reports every key as added. If
+// reading fails, the previous snapshot is kept and nothing is applied;
+// if applying the changes fails, the previous snapshot is also kept, so
+// that the next reload retries them.
+//
+// When applying changes to the process environment, key filters and
+// Hooks.OnSet are honored, WithOverride(false) keeps values that were not
+// set by the Reloader, and keys set are reverted by Unload, but merge
+// strategies are not applied again, so that a reload never appends to a
+// value twice. A removed key is only unset if its process value is still
+// the one that was loaded.
+func (r *Reloader) Reload() ([]Change, error) {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+
+	if m := r.loader.o.metrics; m != nil {
+		start := time.Now()
+		changes, err := r.reload()
+		m.Reloaded(time.Since(start), len(changes), err)
+		return changes, err
+	}
+	return r.reload()
+}
+
+// reload implements Reload.
+func (r *Reloader) reload() ([]Change, error) {
+	env, err := r.loader.Environment()
+	if err != nil {
+		r.loader.o.hooks.error("reload", err)
+		return nil, err
+	}
+
+	// Reloads are serialized by r.reloading, so r.env cannot change until
+	// the new snapshot is stored.
+	r.mu.Lock()
+	changes := Diff(r.env, env)
+	r.mu.Unlock()
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if r.setenv {
+		// Keep the previous snapshot if the changes cannot be applied, so
+		// that the next reload applies them again.
+		if err := r.loader.applyChanges(changes); err != nil {
+			return changes, err
+		}
+	}
+
+	r.mu.Lock()
+	r.env = env
+	callbacks := r.callbacks
+	r.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(env, changes)
+	}
+	return changes, nil
+}
+
+// Run reloads whenever the process receives one of sigs, or SIGHUP if
+// none are given, until ctx is done, and then returns ctx.Err(). Failed
+// reloads are logged and reported to Hooks.OnError, and Run keeps
+// waiting for the next signal. On platforms without SIGHUP, such as
+// js and wasip1, sigs must be given; otherwise Run returns an error
+// wrapping errors.ErrUnsupported.
+func (r *Reloader) Run(ctx context.Context, sigs ...os.Signal) error {
+	if len(sigs) == 0 {
+		sigs = reloadSignals
+	}
+	if len(sigs) == 0 {
+		return fmt.Errorf("error: no default reload signal on this platform: %w", errors.ErrUnsupported)
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-ch:
+			if _, err := r.Reload(); err != nil {
+				r.loader.o.parse.logf("Error: Failed to reload environment variables on %v: %v", sig, err)
+			}
+		}
+	}
+}
+
+// applyChanges sets the values of changes on the process environment.
+func (l *Loader) applyChanges(changes []Change) error {
+	o := l.o
+
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+
+	for _, c := range changes {
+		if reason, denied := o.keys.denied(c.Key, o.foldCase()); denied {
+			o.hooks.skip(c.Key, "reload", reason)
+			continue
+		}
+		if c.Removed {
+			if current, exists := os.LookupEnv(c.Key); !exists || current != c.Old {
+				continue
+			}
+			loadedRestorePoint.record(c.Key)
+			if err := os.Unsetenv(c.Key); err != nil {
+				return fmt.Errorf("error: unable to unset environment variable '%s': %v", c.Key, err)
+			}
+			continue
+		}
+		if o.noOverride {
+			// Only replace values that were loaded, not ones set otherwise.
+			if current, exists := lookupEnv(c.Key, o.foldCase()); exists && (c.Added || current != c.Old) {
+				o.hooks.skip(c.Key, "reload", "key is already set in the process environment")
+				continue
+			}
+		}
+		if !o.hooks.set(c.Key, c.New, "reload") {
+			continue
+		}
+		loadedRestorePoint.record(c.Key)
+		if err := os.Setenv(c.Key, c.New); err != nil {
+			return fmt.Errorf("error: unable to set environment variable '%s': %v", c.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !(unix || windows)
+
+package envfile
+
+import "os"
+
+// reloadSignals is empty where the platform has no SIGHUP, so Run needs
+// the signals to be given.
+var reloadSignals []os.Signal
@@ -0,0 +1,74 @@
+package envfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		changes []envfile.Change
+	}{
+		{name: "changed", before: "RELOAD_A=1\n", after: "RELOAD_A=22\n", changes: []envfile.Change{{Key: "RELOAD_A", Old: "1", New: "22"}}},
+		{name: "added", before: "RELOAD_A=1\n", after: "RELOAD_A=1\nRELOAD_B=2\n", changes: []envfile.Change{{Key: "RELOAD_B", New: "2", Added: true}}},
+		{name: "removed", before: "RELOAD_A=1\nRELOAD_B=2\n", after: "RELOAD_A=1\n", changes: []envfile.Change{{Key: "RELOAD_B", Old: "2", Removed: true}}},
+		{name: "unchanged", before: "RELOAD_A=1\n", after: "RELOAD_A=1\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RELOAD_A", "")
+			t.Setenv("RELOAD_B", "")
+			os.Unsetenv("RELOAD_A")
+			os.Unsetenv("RELOAD_B")
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".env")
+			if err := os.WriteFile(path, []byte(tt.before), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			r := envfile.NewReloader(true, envfile.WithDir(dir), envfile.WithFilenames(".env"), envfile.WithLogger(nil))
+			var notified [][]envfile.Change
+			r.OnReload(func(env *envfile.Environment, changes []envfile.Change) {
+				notified = append(notified, changes)
+			})
+			if _, err := r.Reload(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := envfile.WriteFileAtomic(path, []byte(tt.after), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			changes, err := r.Reload()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("changes: got %+v, want %+v", changes, tt.changes)
+			}
+			if tt.changes != nil && (len(notified) != 2 || !reflect.DeepEqual(notified[1], tt.changes)) {
+				t.Errorf("OnReload: got %+v", notified)
+			}
+
+			want, err := envfile.Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Environment().Map(); !reflect.DeepEqual(got, want.Map()) {
+				t.Errorf("environment: got %v, want %v", got, want.Map())
+			}
+			for _, key := range []string{"RELOAD_A", "RELOAD_B"} {
+				value, exists := os.LookupEnv(key)
+				if wantValue, wantExists := want.Lookup(key); value != wantValue || exists != wantExists {
+					t.Errorf("process %s: got %q, %v, want %q, %v", key, value, exists, wantValue, wantExists)
+				}
+			}
+		})
+	}
+}
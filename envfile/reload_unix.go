@@ -0,0 +1,11 @@
+//go:build unix || windows
+
+package envfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals are the signals Run waits for when none are given.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,170 @@
+package envfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report describes a call to Load: the files it considered, the file it
+// chose, the variables it set and the warnings it logged. It is written
+// as JSON by WithReport so that deployment tooling can archive the
+// configuration a process started with.
+type Report struct {
+	// Profile is the selected profile, or empty if the candidate files
+	// were set with WithFilenames or WithPattern.
+	Profile string `json:"profile,omitempty"`
+	// Dir is the directory searched for candidate files.
+	Dir string `json:"dir,omitempty"`
+	// Candidates lists the candidate files in order of precedence.
+	Candidates []ReportCandidate `json:"candidates"`
+	// File, Files, Sources, Keys and Denied are copied from the Result.
+	File    string   `json:"file,omitempty"`
+	Files   []string `json:"files,omitempty"`
+	Sources []string `json:"sources,omitempty"`
+	Keys    []string `json:"keys,omitempty"`
+	Denied  []string `json:"denied,omitempty"`
+	// Origins is copied from the Result.
+	Origins map[string]string `json:"origins,omitempty"`
+	// Variables holds the values of the keys Load set, with values that
+	// DefaultDetector classifies as secrets replaced by Mask.
+	Variables map[string]string `json:"variables,omitempty"`
+	// Warnings lists the warnings logged while loading.
+	Warnings []string `json:"warnings,omitempty"`
+	// Error is the error returned by Load, if any.
+	Error string `json:"error,omitempty"`
+	// Started is the time Load was called.
+	Started time.Time `json:"started"`
+	// Duration is the time Load took, in nanoseconds.
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// ReportCandidate describes a candidate file in a Report.
+type ReportCandidate struct {
+	// Path is the path of the file.
+	Path string `json:"path"`
+	// Exists reports whether the file existed.
+	Exists bool `json:"exists"`
+	// Loaded reports whether the file was loaded.
+	Loaded bool `json:"loaded"`
+	// Error is the reason the file failed to load, if it did.
+	Error string `json:"error,omitempty"`
+	// Duration is the time spent loading the file, in nanoseconds. It is
+	// zero for files that were not tried.
+	Duration time.Duration `json:"duration_ns,omitempty"`
+}
+
+// WithReport makes Load write a Report of every call to w as indented
+// JSON, whether it succeeds or fails. Errors writing the report are
+// logged and do not fail the load. Environment, Read and Parse do not
+// write reports.
+func WithReport(w io.Writer) Option {
+	return func(o *options) {
+		o.report = w
+	}
+}
+
+// LoadReport loads like Load and returns a Report of the call, whether
+// or not WithReport is set.
+func (l *Loader) LoadReport() (*Report, *Result, error) {
+	report := &Report{Started: time.Now()}
+	logger := &reportLogger{next: l.o.parse.logger, report: report}
+	o := *l.o
+	o.parse.logger = logger
+	reported := &Loader{o: &o}
+
+	quiet := *l.o
+	quiet.parse.logger = log.New(io.Discard, "", 0)
+	if l.o.filenames == nil {
+		report.Profile = quiet.profileName("")
+	}
+	if dir, names, err := (&Loader{o: &quiet}).candidates(); err == nil {
+		report.Dir = dir
+		for _, d := range reported.searchDirs(dir) {
+			for _, name := range names {
+				path := filepath.Join(d, name)
+				info, err := os.Stat(path)
+				report.Candidates = append(report.Candidates, ReportCandidate{
+					Path:   path,
+					Exists: err == nil && !info.IsDir(),
+				})
+			}
+		}
+	}
+
+	result, err := reported.load(report)
+
+	report.File = result.File
+	report.Files = result.Files
+	report.Sources = result.Sources
+	report.Keys = result.Keys
+	report.Denied = result.Denied
+	report.Origins = result.Origins
+	for _, key := range result.Keys {
+		if report.Variables == nil {
+			report.Variables = make(map[string]string, len(result.Keys))
+		}
+		value := os.Getenv(key)
+		if IsSecret(key, value) {
+			value = Mask
+		}
+		report.Variables[key] = value
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	report.Duration = time.Since(report.Started)
+	return report, result, err
+}
+
+// attempt records the outcome of loading a candidate file.
+func (r *Report) attempt(filePath string, d time.Duration, err error) {
+	for i := range r.Candidates {
+		c := &r.Candidates[i]
+		if c.Path != filePath {
+			continue
+		}
+		c.Duration = d
+		if err != nil {
+			c.Error = err.Error()
+		} else {
+			c.Loaded = true
+		}
+		return
+	}
+}
+
+// write writes the report to w as indented JSON.
+func (r *Report) write(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// reportLogger forwards log messages and records warnings in a Report.
+type reportLogger struct {
+	next   Logger
+	mu     sync.Mutex
+	report *Report
+}
+
+func (rl *reportLogger) Printf(format string, v ...any) {
+	message := fmt.Sprintf(format, v...)
+	if strings.HasPrefix(message, "Warning:") {
+		rl.mu.Lock()
+		rl.report.Warnings = append(rl.report.Warnings, message)
+		rl.mu.Unlock()
+	}
+	if rl.next != nil {
+		rl.next.Printf("%s", message)
+	}
+}
@@ -0,0 +1,65 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrRequiredFile is wrapped by the error returned when a file marked
+// with WithRequiredFiles is missing or fails to load.
+var ErrRequiredFile = errors.New("error: required file not loaded")
+
+// WithRequiredFiles marks the candidate files called names as required.
+// Candidates are optional by default: a missing one is skipped, and if
+// none loads, Load logs a warning and returns ErrNoFileLoaded after
+// applying the defaults. If a required file is among the candidates of
+// the selected profile, Load instead fails with an error wrapping
+// ErrRequiredFile when it does not exist, even if a file of higher
+// precedence is loaded, or when it is tried and fails to load, rather
+// than falling back to the next candidate. For example, to abort in
+// production without .env.production, while .env.production.local stays
+// optional:
+//
+//	envfile.New(envfile.WithRequiredFiles(".env.production"))
+//
+// Names are matched against the candidate names, after the placeholders
+// of WithPattern are expanded; a name that is not a candidate of the
+// selected profile has no effect. Files loaded from a vault are not
+// checked.
+func WithRequiredFiles(names ...string) Option {
+	return func(o *options) {
+		o.required = append(o.required, names...)
+	}
+}
+
+// requiredPaths returns the paths among paths, the candidate files found
+// in dir and the directories set with WithConfigDirs, of the files marked
+// with WithRequiredFiles, encrypted or not. It fails if a required name
+// among names has no file in paths.
+func (l *Loader) requiredPaths(dir string, names, paths []string) (map[string]bool, error) {
+	if len(l.o.required) == 0 {
+		return nil, nil
+	}
+	required := make(map[string]bool)
+	for _, name := range names {
+		if !containsString(l.o.required, name) {
+			continue
+		}
+		found := false
+		for _, path := range paths {
+			base := filepath.Base(path)
+			if base == name || strings.HasPrefix(base, name) && containsString(decryptorExtensions(), base[len(name):]) {
+				required[path] = true
+				found = true
+			}
+		}
+		if !found {
+			filePath := filepath.Join(dir, name)
+			l.o.parse.logf("Error: Required file '%s' does not exist.", filePath)
+			return nil, fmt.Errorf("error: required file '%s' does not exist: %w", filePath, ErrRequiredFile)
+		}
+	}
+	return required, nil
+}
@@ -0,0 +1,47 @@
+package envfile
+
+// Resolve returns the candidate file names Load would try for the profile
+// env, in order of precedence, without touching the file system. An empty
+// env selects the profile like Load does, from WithProfile or GO_ENV.
+// Options such as WithProfiles and WithFilenames are honored. An
+// unrecognized profile resolves to the development files, or to nil in
+// strict mode.
+//
+// Tools such as linters and generators can use Resolve to follow the same
+// precedence rules as the application.
+func Resolve(env string, opts ...Option) []string {
+	return New(opts...).Resolve(env)
+}
+
+// Discover returns the paths of the candidate files for the profile env
+// that exist in dir, in order of precedence, without loading them. The
+// first path is the file Load would try first. An empty dir means the
+// directory set with WithDir, or the current working directory. The
+// directories set with WithConfigDirs are searched after dir.
+func Discover(dir, env string, opts ...Option) ([]string, error) {
+	return New(opts...).Discover(dir, env)
+}
+
+// Resolve returns the candidate file names for the profile env. See the
+// package-level Resolve.
+func (l *Loader) Resolve(env string) []string {
+	names, err := l.names(env)
+	if err != nil {
+		return nil
+	}
+	return append([]string{}, names...)
+}
+
+// Discover returns the paths of the candidate files for the profile env
+// that exist in dir. See the package-level Discover.
+func (l *Loader) Discover(dir, env string) ([]string, error) {
+	names, err := l.names(env)
+	if err != nil {
+		return nil, err
+	}
+	dir, err = l.dir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return l.search(dir, names)
+}
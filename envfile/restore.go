@@ -0,0 +1,96 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// priorValue is the state of a process variable before it was changed.
+type priorValue struct {
+	value  string
+	exists bool
+}
+
+// restorePoint records the original state of every key it is asked to
+// remember, so the process environment can later be put back as it was.
+// Only the first recording of a key is kept.
+type restorePoint struct {
+	keys  []string
+	prior map[string]priorValue
+}
+
+func (r *restorePoint) record(key string) {
+	if r.prior == nil {
+		r.prior = make(map[string]priorValue)
+	}
+	if _, exists := r.prior[key]; exists {
+		return
+	}
+	value, exists := os.LookupEnv(key)
+	r.prior[key] = priorValue{value: value, exists: exists}
+	r.keys = append(r.keys, key)
+}
+
+// restore puts every recorded key back to its original state and clears
+// the restore point.
+func (r *restorePoint) restore() error {
+	var errs []error
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		key := r.keys[i]
+		prior := r.prior[key]
+
+		var err error
+		if prior.exists {
+			err = os.Setenv(key, prior.value)
+		} else {
+			err = os.Unsetenv(key)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error: unable to restore environment variable '%s': %v", key, err))
+		}
+	}
+	r.keys = nil
+	r.prior = nil
+	return errors.Join(errs...)
+}
+
+// loadedRestorePoint remembers the state of every key changed by Load
+// since the last call to Unload. It is guarded by setenvMu.
+var loadedRestorePoint restorePoint
+
+// Unload reverts every variable set by Load since the last call to
+// Unload, restoring the value it had before it was first loaded or
+// unsetting it if it did not exist.
+func Unload() error {
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+	return loadedRestorePoint.restore()
+}
+
+// Apply sets the variables of e on the process environment and returns a
+// function that restores every affected variable to its state before the
+// call. The restore function is meant to be called once, typically with
+// defer. If setting a variable fails, the variables already set are
+// restored before the error is returned.
+func (e *Environment) Apply() (restore func() error, err error) {
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+
+	point := &restorePoint{}
+	for _, key := range e.keys {
+		point.record(key)
+		if err := os.Setenv(key, e.values[key]); err != nil {
+			if restoreErr := point.restore(); restoreErr != nil {
+				err = errors.Join(err, restoreErr)
+			}
+			return nil, fmt.Errorf("error: unable to set environment variable '%s': %v", key, err)
+		}
+	}
+
+	return func() error {
+		setenvMu.Lock()
+		defer setenvMu.Unlock()
+		return point.restore()
+	}, nil
+}
@@ -0,0 +1,85 @@
+package envfile
+
+import "strings"
+
+// Result describes the outcome of Load.
+type Result struct {
+	// Profile is the profile whose candidate files Load searched, after
+	// resolving aliases, or "development" if the selected profile is not
+	// configured. It is empty if the files were set with WithFilenames.
+	Profile string
+	// File is the path of the file that was loaded, or empty if no file
+	// was loaded. When several files are loaded, it is the last one.
+	File string
+	// Files lists the paths of every file that was loaded, in order.
+	Files []string
+	// Sources lists the names of the Sources added with WithSource that
+	// were loaded, in order.
+	Sources []string
+	// Keys lists every key Load set on the process environment, in the
+	// order they were first set. It includes keys set from a file that
+	// failed to load part way through.
+	Keys []string
+	// Denied lists the keys that were not set because of
+	// WithAllowedKeys or WithDeniedKeys, in the order they were read.
+	Denied []string
+	// Translated lists the keys renamed by WithKeyMap or
+	// WithKeyTranslator that Load set, in the order they were set.
+	Translated []Translation
+	// Origins maps every key Load set or kept to the layer its value
+	// comes from: the path of a file, the name of a Source, "defaults",
+	// or OSOrigin for a key whose process value ranks above the layers
+	// that define it, as set with WithOSLayer.
+	Origins map[string]string
+
+	// osOverridden is set once the layer set with OSBefore is applied.
+	osOverridden bool
+	// keys indexes Keys, so that loading many keys stays linear.
+	keys map[string]struct{}
+}
+
+func (r *Result) addKey(key string) {
+	if !r.hasKey(key) {
+		r.Keys = append(r.Keys, key)
+		r.keys[key] = struct{}{}
+	}
+}
+
+func (r *Result) hasKey(key string) bool {
+	if r.keys == nil || len(r.keys) != len(r.Keys) {
+		// Rebuild the index if Keys was changed directly.
+		r.keys = make(map[string]struct{}, len(r.Keys))
+		for _, k := range r.Keys {
+			r.keys[k] = struct{}{}
+		}
+	}
+	_, exists := r.keys[key]
+	return exists
+}
+
+func (r *Result) setOrigin(key, origin string) {
+	if r.Origins == nil {
+		r.Origins = make(map[string]string)
+	}
+	r.Origins[key] = origin
+}
+
+// Scrub returns a copy of environ, a list of "key=value" entries such as
+// os.Environ() or exec.Cmd.Env, without the keys that Load set. Process
+// supervisors can use it to keep loaded configuration away from less
+// trusted child processes.
+func (r *Result) Scrub(environ []string) []string {
+	loaded := make(map[string]struct{}, len(r.Keys))
+	for _, key := range r.Keys {
+		loaded[key] = struct{}{}
+	}
+
+	scrubbed := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, exists := loaded[key]; !exists {
+			scrubbed = append(scrubbed, kv)
+		}
+	}
+	return scrubbed
+}
@@ -0,0 +1,131 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultSanitizePolicy removes the variables that commonly hold
+// credentials, named like *_TOKEN, *_SECRET, *_PASSWORD or *_KEY or whose
+// values DefaultDetector classifies as secrets, while keeping the
+// variables in DefaultCleanAllowlist.
+var DefaultSanitizePolicy = &SanitizePolicy{
+	Remove:  []string{"*_TOKEN", "*_SECRET", "*_PASSWORD", "*_KEY"},
+	Keep:    DefaultCleanAllowlist,
+	Secrets: DefaultDetector,
+}
+
+// SanitizePolicy selects the process variables changed by Sanitize.
+// Patterns use path.Match syntax, such as "*_TOKEN", and are matched
+// case-insensitively on Windows, where variable names are.
+type SanitizePolicy struct {
+	// Remove lists the patterns of the variables to unset.
+	Remove []string
+	// Mask lists the patterns of the variables whose value is replaced by
+	// Mask, for children that only check that a variable is set.
+	Mask []string
+	// Keep lists the patterns of the variables left untouched even if
+	// they match Remove or Mask or are classified as secrets.
+	Keep []string
+	// Secrets, if not nil, also unsets the variables it classifies as
+	// secrets, whatever their names.
+	Secrets *Detector
+}
+
+// action returns the change the policy makes to the variable key with
+// value: "remove", "mask" or "" for none.
+func (p *SanitizePolicy) action(key, value string) string {
+	switch {
+	case MatchKey(key, p.Keep...):
+		return ""
+	case MatchKey(key, p.Remove...):
+		return "remove"
+	case MatchKey(key, p.Mask...):
+		return "mask"
+	case p.Secrets != nil && p.Secrets.IsSecret(key, value):
+		return "remove"
+	}
+	return ""
+}
+
+// Sanitize removes or masks the process variables selected by policy,
+// the counterpart of Load for security hygiene before spawning untrusted
+// subprocesses, and returns a function that restores every affected
+// variable, as Environment.Apply does. A nil policy means
+// DefaultSanitizePolicy.
+//
+//	restore, err := envfile.Sanitize(&envfile.SanitizePolicy{Remove: []string{"*_TOKEN"}})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer restore()
+//	exec.Command("./untrusted-plugin").Run()
+//
+// If changing a variable fails, the variables already changed are
+// restored before the error is returned. To sanitize the environment of
+// a single child without changing the parent, set its Env to
+// SanitizeEnviron(os.Environ(), policy) instead.
+func Sanitize(policy *SanitizePolicy) (restore func() error, err error) {
+	if policy == nil {
+		policy = DefaultSanitizePolicy
+	}
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+
+	environ := os.Environ()
+	sort.Strings(environ)
+	point := &restorePoint{}
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if key == "" {
+			continue
+		}
+		var err error
+		switch policy.action(key, value) {
+		case "remove":
+			point.record(key)
+			err = os.Unsetenv(key)
+		case "mask":
+			point.record(key)
+			err = os.Setenv(key, Mask)
+		default:
+			continue
+		}
+		if err != nil {
+			if restoreErr := point.restore(); restoreErr != nil {
+				err = errors.Join(err, restoreErr)
+			}
+			return nil, fmt.Errorf("error: unable to sanitize environment variable '%s': %v", key, err)
+		}
+	}
+
+	return func() error {
+		setenvMu.Lock()
+		defer setenvMu.Unlock()
+		return point.restore()
+	}, nil
+}
+
+// SanitizeEnviron returns a copy of environ, in the "key=value" form of
+// os.Environ and exec.Cmd.Env, with the variables selected by policy
+// removed or masked. A nil policy means DefaultSanitizePolicy.
+func SanitizeEnviron(environ []string, policy *SanitizePolicy) []string {
+	if policy == nil {
+		policy = DefaultSanitizePolicy
+	}
+	result := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		switch policy.action(key, value) {
+		case "remove":
+		case "mask":
+			result = append(result, key+"="+Mask)
+		default:
+			result = append(result, kv)
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package envfile
+
+import "fmt"
+
+// Schema describes the variables an application expects, so that an
+// Environment can be checked before it is deployed. Its entries are
+// typically read from a schema or example file with ReadExample.
+type Schema struct {
+	Entries []ExampleEntry
+	// AllowUnknown accepts keys that are not listed in Entries.
+	AllowUnknown bool
+}
+
+// ReadSchema reads a Schema from the file at filePath, written in the
+// format described for ParseExample.
+func ReadSchema(filePath string) (*Schema, error) {
+	entries, err := ReadExample(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Entries: entries}, nil
+}
+
+// Validate checks env against the schema and returns an Issue for every
+// required key that is not set (RuleMissingKey), every value invalid for
+// its declared type (RuleInvalidType) and, unless AllowUnknown is set,
+// every key the schema does not list (RuleUnknownKey). The issues concern
+// the environment as a whole, so their Line is 0.
+func (s *Schema) Validate(env *Environment) []Issue {
+	var issues []Issue
+	report := func(rule Rule, format string, args ...any) {
+		issues = append(issues, Issue{
+			Rule:     rule,
+			Severity: ruleSeverity[rule],
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	listed := make(map[string]bool, len(s.Entries))
+	for _, entry := range s.Entries {
+		listed[entry.Key] = true
+		value, exists := env.Lookup(entry.Key)
+		if !exists || value == "" && entry.Default == "" {
+			if entry.Required {
+				report(RuleMissingKey, "required key '%s' is not set", entry.Key)
+			}
+			continue
+		}
+		if entry.Type != "" {
+			if err := checkType(entry.Type, value); err != nil {
+				report(RuleInvalidType, "value of '%s': %v", entry.Key, err)
+			}
+		}
+	}
+
+	if !s.AllowUnknown {
+		for _, key := range env.Keys() {
+			if !listed[key] {
+				report(RuleUnknownKey, "key '%s' is not in the schema", key)
+			}
+		}
+	}
+	return issues
+}
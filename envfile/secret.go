@@ -0,0 +1,154 @@
+package envfile
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// SecretReason explains why a variable was classified as a secret.
+type SecretReason string
+
+const (
+	// SecretNone means the variable does not look like a secret.
+	SecretNone SecretReason = ""
+	// SecretKeyName means the key name suggests a secret, such as
+	// DB_PASSWORD or GITHUB_TOKEN.
+	SecretKeyName SecretReason = "key-name"
+	// SecretTokenFormat means the value matches a known credential
+	// format, such as an AWS access key ID.
+	SecretTokenFormat SecretReason = "token-format"
+	// SecretEntropy means the value is long and random enough to be a
+	// generated credential.
+	SecretEntropy SecretReason = "entropy"
+)
+
+// Mask is the replacement shown instead of a secret value.
+const Mask = "********"
+
+// Detector classifies variables as secrets. The zero value detects
+// nothing; use DefaultDetector or copy and adjust its fields.
+type Detector struct {
+	// KeyWords are substrings that mark a key as secret, matched against
+	// the upper-cased key.
+	KeyWords []string
+	// TokenPatterns match values in known credential formats.
+	TokenPatterns []*regexp.Regexp
+	// MinEntropy is the Shannon entropy, in bits per character, above
+	// which a value is considered random. Zero disables the check.
+	MinEntropy float64
+	// MinEntropyLength is the minimum value length for the entropy check.
+	MinEntropyLength int
+}
+
+// DefaultDetector is the Detector used by IsSecret and Masked.
+var DefaultDetector = &Detector{
+	KeyWords: []string{
+		"SECRET",
+		"PASSWORD",
+		"PASSWD",
+		"TOKEN",
+		"API_KEY",
+		"APIKEY",
+		"ACCESS_KEY",
+		"PRIVATE_KEY",
+		"CREDENTIAL",
+	},
+	TokenPatterns: []*regexp.Regexp{
+		regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`),                              // AWS access key ID
+		regexp.MustCompile(`^gh[opsur]_[A-Za-z0-9]{36,}$`),                           // GitHub token
+		regexp.MustCompile(`^github_pat_[A-Za-z0-9_]{22,}$`),                         // GitHub fine-grained token
+		regexp.MustCompile(`^xox[abposr]-[A-Za-z0-9-]{10,}$`),                        // Slack token
+		regexp.MustCompile(`^(sk|rk)_(live|test)_[A-Za-z0-9]{16,}$`),                 // Stripe key
+		regexp.MustCompile(`^AIza[0-9A-Za-z_-]{35}$`),                                // Google API key
+		regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`), // JSON Web Token
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),                     // PEM private key
+	},
+	MinEntropy:       4.0,
+	MinEntropyLength: 20,
+}
+
+var tokenCharsRegex = regexp.MustCompile(`^[A-Za-z0-9+/=_\-.]+$`)
+
+// Classify reports why the variable looks like a secret, or SecretNone if
+// it does not. Empty values and template references are never secrets.
+func (d *Detector) Classify(key, value string) SecretReason {
+	if value == "" || strings.HasPrefix(value, "{$") {
+		return SecretNone
+	}
+
+	upper := strings.ToUpper(key)
+	for _, word := range d.KeyWords {
+		if strings.Contains(upper, word) {
+			return SecretKeyName
+		}
+	}
+
+	for _, pattern := range d.TokenPatterns {
+		if pattern.MatchString(value) {
+			return SecretTokenFormat
+		}
+	}
+
+	if d.MinEntropy > 0 && len(value) >= d.MinEntropyLength && tokenCharsRegex.MatchString(value) && Entropy(value) >= d.MinEntropy {
+		return SecretEntropy
+	}
+
+	return SecretNone
+}
+
+// IsSecret reports whether the variable looks like a secret.
+func (d *Detector) IsSecret(key, value string) bool {
+	return d.Classify(key, value) != SecretNone
+}
+
+// IsSecret reports whether DefaultDetector classifies the variable as a
+// secret.
+func IsSecret(key, value string) bool {
+	return DefaultDetector.IsSecret(key, value)
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Masked returns a copy of e in which every value that DefaultDetector
+// classifies as a secret is replaced by Mask. It is meant for logging and
+// display; the original Environment is unchanged.
+func (e *Environment) Masked() *Environment {
+	return e.MaskedWith(DefaultDetector)
+}
+
+// MaskedWith is like Masked but classifies secrets with d.
+func (e *Environment) MaskedWith(d *Detector) *Environment {
+	masked := &Environment{
+		values: make(map[string]string, len(e.values)),
+		keys:   e.Keys(),
+		types:  e.types,
+
+		foldCase: e.foldCase,
+		folded:   e.folded,
+	}
+	for key, value := range e.values {
+		if d.IsSecret(key, value) {
+			value = Mask
+		}
+		masked.values[key] = value
+	}
+	return masked
+}
@@ -0,0 +1,63 @@
+package envfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		value   string
+		want    string
+	}{
+		{name: "plain", value: `value`, want: "KEY=value\n"},
+		{name: "spaces", value: `a b`, want: "KEY=\"a b\"\n"},
+		{name: "hash", value: `a#b`, want: "KEY=\"a#b\"\n"},
+		{name: "quotes", value: `"a"`, want: "KEY=\"\\\"a\\\"\"\n"},
+		{name: "line break", value: "a\nb", want: "KEY=\"a\\nb\"\n"},
+		{name: "backslash", value: `C:\dir\`, want: "KEY=\"C:\\\\dir\\\\\"\n"},
+		{name: "surrounding whitespace", value: ` a `, want: "KEY=\" a \"\n"},
+		{name: "in place", content: "KEY=old # note\n", value: `a b`, want: "KEY=\"a b\" # note\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := envfile.Set(path, "KEY", tt.value); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("got %q, want %q", content, tt.want)
+			}
+			env, err := envfile.ParseBytes(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Get("KEY"); got != tt.value {
+				t.Errorf("read back %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetRejects(t *testing.T) {
+	for _, value := range []string{"a\x00b", "{$HOST}"} {
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := envfile.Set(path, "KEY", value); err == nil {
+			t.Errorf("Set(%q) succeeded", value)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package envfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Snapshot returns the entire process environment as an Environment,
+// whatever set it, so that it can later be put back with Restore. Keys
+// starting with '=', which Windows uses for per-drive directories, are
+// not included, since they cannot be set.
+func Snapshot() *Environment {
+	environ := os.Environ()
+	variables := make([]variable, 0, len(environ))
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if key == "" {
+			continue
+		}
+		variables = append(variables, variable{key: key, value: value})
+	}
+	return newEnvironment(variables)
+}
+
+// Restore resets the process environment to snapshot, typically taken
+// with Snapshot: variables missing from snapshot are unset, and the
+// others are set to their value in snapshot. It is meant for sandboxed
+// experiments, REPLs and tests that change more than the keys Load sets;
+// Unload only reverts the changes made by Load. Restore does not change
+// what Unload reverts.
+//
+// Restore attempts every change and reports the ones that failed
+// together.
+func Restore(snapshot *Environment) error {
+	setenvMu.Lock()
+	defer setenvMu.Unlock()
+
+	var errs []error
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if key == "" {
+			continue
+		}
+		if _, exists := snapshot.values[key]; exists {
+			continue
+		}
+		if err := os.Unsetenv(key); err != nil {
+			errs = append(errs, fmt.Errorf("error: unable to unset environment variable '%s': %v", key, err))
+		}
+	}
+	for _, key := range snapshot.keys {
+		value := snapshot.values[key]
+		if current, exists := os.LookupEnv(key); exists && current == value {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("error: unable to restore environment variable '%s': %v", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package envfile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Source supplies variables from somewhere other than a local file, such
+// as a key-value store. The envfile/kv package provides Sources for
+// Consul and etcd.
+type Source interface {
+	// Name identifies the source in errors, hooks and Result.Sources.
+	Name() string
+	// Fetch returns the variables currently held by the source.
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// WithSource merges the variables of src over those of the selected file
+// in Load and Environment. Sources are read in the order they were added,
+// after the file, so values from later sources override earlier ones.
+func WithSource(src Source) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, src)
+	}
+}
+
+// fetchSource returns the variables of src sorted by key, so that they
+// are applied in a deterministic order.
+func fetchSource(src Source) ([]variable, error) {
+	values, err := src.Fetch(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("error: failed to read environment variables from '%s': %w", src.Name(), err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	variables := make([]variable, len(keys))
+	for i, key := range keys {
+		variables[i] = variable{key: key, value: values[key]}
+	}
+	return variables, nil
+}
+
+// loadSources applies every configured source to the process
+// environment.
+func (l *Loader) loadSources(result *Result) error {
+	for _, src := range l.o.sources {
+		variables, err := fetchSource(src)
+		if err == nil {
+			variables, err = l.process(src.Name(), variables)
+		}
+		if err == nil {
+			err = l.apply(src.Name(), variables, result)
+		}
+		if err != nil {
+			l.o.hooks.error(src.Name(), err)
+			return err
+		}
+		result.Sources = append(result.Sources, src.Name())
+	}
+	return nil
+}
+
+// readSources returns the variables of every configured source, in
+// order.
+func (l *Loader) readSources() ([]variable, error) {
+	var variables []variable
+	for _, src := range l.o.sources {
+		vars, err := fetchSource(src)
+		if err == nil {
+			vars, err = l.process(src.Name(), vars)
+		}
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, vars...)
+	}
+	return variables, nil
+}
@@ -0,0 +1,166 @@
+package envfile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// CachedSource wraps a Source, such as a Consul, etcd or Vault backend,
+// and serves its variables from a cache, so that a transient outage of
+// the backend does not break loads and reloads:
+//
+//	src := &envfile.CachedSource{
+//		Source:               consul,
+//		TTL:                  time.Minute,
+//		StaleWhileRevalidate: time.Hour,
+//		Path:                 "/var/cache/app/consul.json",
+//	}
+//	envfile.Load(envfile.WithSource(src))
+//
+// Variables fetched less than TTL ago are served from the cache. Older
+// variables are still served for up to StaleWhileRevalidate longer, while
+// a refresh runs in the background. Past that, Fetch reads the backend
+// and fails if it cannot.
+//
+// If Path is set, every successful fetch is also written to that file,
+// readable only by the owner, and a new process starts from its contents,
+// so that applications can restart during an outage. The file holds the
+// values in clear text; place it accordingly.
+//
+// A CachedSource is safe for concurrent use. Its fields must not be
+// changed after the first call to Fetch.
+type CachedSource struct {
+	Source Source
+	// TTL is how long fetched variables are fresh.
+	TTL time.Duration
+	// StaleWhileRevalidate is how long after TTL stale variables are
+	// still served while they are refreshed in the background.
+	StaleWhileRevalidate time.Duration
+	// Path, if not empty, is the file that persists the cache across
+	// restarts.
+	Path string
+	// OnRefreshError, if not nil, receives the errors of background
+	// refreshes, which Fetch cannot return.
+	OnRefreshError func(err error)
+
+	mu         sync.Mutex
+	loaded     bool
+	values     map[string]string
+	fetched    time.Time
+	refreshing bool
+}
+
+// cachedValues is the format of the file at CachedSource.Path.
+type cachedValues struct {
+	Fetched time.Time         `json:"fetched"`
+	Values  map[string]string `json:"values"`
+}
+
+// Name returns the name of the wrapped Source.
+func (c *CachedSource) Name() string {
+	return c.Source.Name()
+}
+
+// Fetch returns the cached variables, or reads them from the wrapped
+// Source if the cache is empty or too old.
+func (c *CachedSource) Fetch(ctx context.Context) (map[string]string, error) {
+	c.mu.Lock()
+	if !c.loaded {
+		c.loaded = true
+		c.readFile()
+	}
+	if c.values != nil {
+		age := time.Since(c.fetched)
+		if age < c.TTL {
+			values := copyValues(c.values)
+			c.mu.Unlock()
+			return values, nil
+		}
+		if age < c.TTL+c.StaleWhileRevalidate {
+			if !c.refreshing {
+				c.refreshing = true
+				go c.refresh()
+			}
+			values := copyValues(c.values)
+			c.mu.Unlock()
+			return values, nil
+		}
+	}
+	c.mu.Unlock()
+
+	values, err := c.Source.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.store(values)
+	return copyValues(values), nil
+}
+
+// refresh reads the wrapped Source in the background.
+func (c *CachedSource) refresh() {
+	values, err := c.Source.Fetch(context.Background())
+	if err == nil {
+		c.store(values)
+	}
+
+	c.mu.Lock()
+	c.refreshing = false
+	c.mu.Unlock()
+
+	if err != nil && c.OnRefreshError != nil {
+		c.OnRefreshError(err)
+	}
+}
+
+// store records freshly fetched values and persists them.
+func (c *CachedSource) store(values map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values = copyValues(values)
+	c.fetched = time.Now()
+	if c.Path != "" {
+		if err := c.writeFile(); err != nil && c.OnRefreshError != nil {
+			c.OnRefreshError(err)
+		}
+	}
+}
+
+// readFile fills the cache from Path. A missing or unreadable file
+// leaves the cache empty.
+func (c *CachedSource) readFile() {
+	if c.Path == "" {
+		return
+	}
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return
+	}
+	var cached cachedValues
+	if json.Unmarshal(data, &cached) != nil || cached.Values == nil {
+		return
+	}
+	c.values = cached.Values
+	c.fetched = cached.Fetched
+}
+
+// writeFile replaces the file at Path with the cache atomically, so that
+// a crash never leaves a truncated cache behind.
+func (c *CachedSource) writeFile() error {
+	data, err := json.Marshal(cachedValues{Fetched: c.fetched, Values: c.values})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.Path, data, 0o600, false)
+}
+
+func copyValues(values map[string]string) map[string]string {
+	copied := make(map[string]string, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,53 @@
+package envfile_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestSpacing(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    []envfile.Option
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "no spaces", content: "KEY=value", key: "KEY", value: "value"},
+		{name: "spaces", content: "KEY = value", key: "KEY", value: "value"},
+		{name: "space before", content: "KEY =value", key: "KEY", value: "value"},
+		{name: "space after", content: "KEY= value", key: "KEY", value: "value"},
+		{name: "tabs", content: "KEY\t=\tvalue", key: "KEY", value: "value"},
+		{name: "inner spaces kept", content: "KEY = a b", key: "KEY", value: "a b"},
+		{name: "empty value", content: "KEY = ", key: "KEY", value: ""},
+		{name: "typed", content: "PORT:int = 8080", key: "PORT", value: "8080"},
+		{name: "strict without spaces", content: "KEY=value", opts: []envfile.Option{envfile.WithStrict()}, key: "KEY", value: "value"},
+		{name: "strict", content: "KEY = value", opts: []envfile.Option{envfile.WithStrict()}, wantErr: true},
+		{name: "strict tab", content: "KEY\t=value", opts: []envfile.Option{envfile.WithStrict()}, wantErr: true},
+		{name: "reject", content: "KEY= value", opts: []envfile.Option{envfile.WithSpacing(envfile.SpacingReject)}, wantErr: true},
+		{name: "strict trim", content: "KEY = value", opts: []envfile.Option{envfile.WithStrict(), envfile.WithSpacing(envfile.SpacingTrim)}, key: "KEY", value: "value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := envfile.ParseBytes([]byte(tt.content+"\n"), tt.opts...)
+			if tt.wantErr {
+				if !errors.Is(err, envfile.ErrSyntax) {
+					t.Fatalf("got error %v, want one wrapping ErrSyntax", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Keys(); len(got) != 1 || got[0] != tt.key {
+				t.Fatalf("got keys %q, want [%q]", got, tt.key)
+			}
+			if got := env.Get(tt.key); got != tt.value {
+				t.Errorf("got value %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package envfile
+
+import "strings"
+
+// WithCommentPrefixes sets the prefixes that start a comment, replacing
+// the default "#", so that files written for tools using ";" or "//"
+// can be read:
+//
+//	envfile.WithCommentPrefixes("#", "//")
+//
+// A line whose first non-blank characters are a prefix is a comment. An
+// inline comment starts at the first '#' outside a quoted value, as
+// usual, or at another prefix outside a quoted value that follows a space
+// or a tab, so that values such as URLs are not cut at "//". Directives
+// such as #include, #if and "# @type" are recognized whatever the
+// prefixes.
+func WithCommentPrefixes(prefixes ...string) Option {
+	return func(o *options) {
+		o.parse.commentPrefixes = strings.Join(prefixes, "\x00")
+		o.parse.commentPrefixesSet = true
+	}
+}
+
+// WithSeparators sets the strings separating keys from values, replacing
+// the default "=". A line is split at the first occurrence of any of
+// them, so WithSeparators("=", ":") reads both KEY=value and KEY: value.
+// With ":" as a separator, the KEY:type=value syntax cannot be used;
+// declare types with "# @type" annotations instead.
+func WithSeparators(separators ...string) Option {
+	return func(o *options) {
+		o.parse.separators = strings.Join(separators, "\x00")
+	}
+}
+
+// WithInlineComments controls whether comments may follow a value on the
+// same line. The default is true. When false, only lines starting with a
+// comment prefix are comments, and a '#' elsewhere is part of the value.
+func WithInlineComments(enabled bool) Option {
+	return func(o *options) {
+		o.parse.noInlineComments = !enabled
+	}
+}
+
+// customSyntax reports whether the syntax differs from the default.
+func (po parseOptions) customSyntax() bool {
+	return po.commentPrefixesSet || po.separators != "" || po.noInlineComments
+}
+
+func (po parseOptions) prefixes() []string {
+	if !po.commentPrefixesSet {
+		return []string{"#"}
+	}
+	if po.commentPrefixes == "" {
+		return nil
+	}
+	return strings.Split(po.commentPrefixes, "\x00")
+}
+
+// separatorIndex returns the position and length of the first separator
+// in s, or -1 and 0 if there is none.
+func (po parseOptions) separatorIndex(s string) (int, int) {
+	if po.separators == "" {
+		return strings.Index(s, "="), 1
+	}
+	index, length := -1, 0
+	for _, sep := range strings.Split(po.separators, "\x00") {
+		if sep == "" {
+			continue
+		}
+		if i := strings.Index(s, sep); i != -1 && (index == -1 || i < index) {
+			index, length = i, len(sep)
+		}
+	}
+	return index, length
+}
+
+// stripComment returns line without its comment, following the
+// configured syntax.
+func (po parseOptions) stripComment(line string) string {
+	if !po.customSyntax() {
+		return clearAfterHash(line)
+	}
+
+	prefixes := po.prefixes()
+	trimmed := strings.TrimLeft(line, " \t")
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(trimmed, prefix) {
+			return ""
+		}
+	}
+	if po.noInlineComments {
+		return line
+	}
+
+	// Comments start after a quoted value.
+	start := 0
+	if index, length := po.separatorIndex(line); index != -1 {
+		value := strings.TrimLeft(line[index+length:], " \t")
+		if end := closingQuote(value); end != -1 {
+			start = len(line) - len(value) + end + 1
+		}
+	}
+
+	end := len(line)
+	for _, prefix := range prefixes {
+		if i := inlineComment(line[start:], prefix); i != -1 && start+i < end {
+			end = start + i
+		}
+	}
+	return line[:end]
+}
+
+// inlineComment returns the position of the comment starting with prefix
+// in s, or -1. A '#' starts a comment anywhere; other prefixes only at
+// the start of s or after a space or a tab.
+func inlineComment(s, prefix string) int {
+	if prefix == "" {
+		return -1
+	}
+	if prefix == "#" {
+		return strings.Index(s, "#")
+	}
+	for i := 0; i < len(s); {
+		j := strings.Index(s[i:], prefix)
+		if j == -1 {
+			return -1
+		}
+		j += i
+		if j == 0 || s[j-1] == ' ' || s[j-1] == '\t' {
+			return j
+		}
+		i = j + 1
+	}
+	return -1
+}
+
+// splitLine is like the package-level splitLine, but splits at the
+// configured separators.
+func (po parseOptions) splitLine(s string) (key string, value string) {
+	if po.separators == "" {
+		return splitLine(s)
+	}
+	index, length := po.separatorIndex(s)
+	if index == -1 {
+		return s, ""
+	}
+	return strings.TrimRight(s[:index], " \t"), strings.TrimLeft(s[index+length:], " \t")
+}
+
+// rejectsSpacing reports whether whitespace around '=' is an error.
+func (po parseOptions) rejectsSpacing() bool {
+	if po.spacingSet {
+		return po.spacing == SpacingReject
+	}
+	return po.strict
+}
+
+// spacedAssignment is like the package-level spacedAssignment, but for
+// the configured separators.
+func (po parseOptions) spacedAssignment(s string) bool {
+	index, length := po.separatorIndex(s)
+	if index == -1 {
+		return false
+	}
+	before, after := s[:index], s[index+length:]
+	return strings.HasSuffix(before, " ") || strings.HasSuffix(before, "\t") || strings.HasPrefix(after, " ") || strings.HasPrefix(after, "\t")
+}
@@ -0,0 +1,129 @@
+package envfile
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateOptions configures rendering of env files through text/template
+// before they are parsed.
+type templateOptions struct {
+	data  any
+	funcs template.FuncMap
+}
+
+// WithTemplate renders each env file through text/template before it is
+// parsed, with data as the template's dot value. In addition to the
+// standard template functions, TemplateFuncs are available, such as
+// {{ env "HOME" }} or {{ hostname }}. Files rendered as templates are never
+// served from the parse cache, since their output may change between loads.
+func WithTemplate(data any) Option {
+	return func(o *options) {
+		if o.parse.template == nil {
+			o.parse.template = &templateOptions{}
+		}
+		o.parse.template.data = data
+	}
+}
+
+// WithTemplateFuncs adds functions available to templates rendered because
+// of WithTemplate, overriding TemplateFuncs of the same name. It implies
+// WithTemplate(nil) unless WithTemplate is also given.
+func WithTemplateFuncs(funcs template.FuncMap) Option {
+	return func(o *options) {
+		if o.parse.template == nil {
+			o.parse.template = &templateOptions{}
+		}
+		if o.parse.template.funcs == nil {
+			o.parse.template.funcs = make(template.FuncMap)
+		}
+		for name, fn := range funcs {
+			o.parse.template.funcs[name] = fn
+		}
+	}
+}
+
+// TemplateFuncs returns the helper functions available to env file
+// templates:
+//
+//	env KEY             value of a process variable
+//	default DEF VALUE   VALUE, or DEF if VALUE is empty
+//	required MSG VALUE  VALUE, or an error with MSG if VALUE is empty
+//	hostname            the host name reported by os.Hostname
+//	upper, lower, trim  strings.ToUpper, strings.ToLower, strings.TrimSpace
+//	replace OLD NEW S   strings.ReplaceAll(S, OLD, NEW)
+//	split SEP S         strings.Split(S, SEP)
+//	join SEP LIST       strings.Join(LIST, SEP)
+//	quote S             S wrapped in double quotes
+//	b64enc, b64dec      standard base64 encoding and decoding
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"env": os.Getenv,
+		"default": func(def string, value any) string {
+			s := fmt.Sprint(value)
+			if value == nil || s == "" {
+				return def
+			}
+			return s
+		},
+		"required": func(message string, value any) (string, error) {
+			s := fmt.Sprint(value)
+			if value == nil || s == "" {
+				return "", fmt.Errorf("%s", message)
+			}
+			return s, nil
+		},
+		"hostname": os.Hostname,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"split": func(sep, s string) []string {
+			return strings.Split(s, sep)
+		},
+		"join": func(sep string, list []string) string {
+			return strings.Join(list, sep)
+		},
+		"quote": func(s string) string {
+			return fmt.Sprintf("%q", s)
+		},
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"b64dec": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+	}
+}
+
+// render executes the content of r as a template named source.
+func (t *templateOptions) render(r io.Reader, source string) (io.Reader, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := TemplateFuncs()
+	for name, fn := range t.funcs {
+		funcs[name] = fn
+	}
+
+	tmpl, err := template.New(source).Funcs(funcs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to parse template '%s': %v", source, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, t.data); err != nil {
+		return nil, fmt.Errorf("error: unable to render template '%s': %v", source, err)
+	}
+	return &buf, nil
+}
@@ -0,0 +1,133 @@
+package envfile
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Transform computes a value from the argument of a {name:argument}
+// expression, such as {lower:{$HOST}}. Transforms must be deterministic,
+// since parsed files may be cached.
+type Transform func(arg string) (string, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]Transform{
+		"lower":     func(s string) (string, error) { return strings.ToLower(s), nil },
+		"upper":     func(s string) (string, error) { return strings.ToUpper(s), nil },
+		"trim":      func(s string) (string, error) { return strings.TrimSpace(s), nil },
+		"urlencode": func(s string) (string, error) { return url.QueryEscape(s), nil },
+		"b64encode": func(s string) (string, error) { return base64.StdEncoding.EncodeToString([]byte(s)), nil },
+		"b64decode": transformBase64Decode,
+	}
+)
+
+// transformRegex matches an innermost {name:argument} expression.
+var transformRegex = regexp.MustCompile(`\{([a-z][a-z0-9_]*):([^{}]*)\}`)
+
+// maxTransformDepth bounds the nesting of transform expressions, so that
+// a transform whose result is itself an expression cannot loop forever.
+const maxTransformDepth = 16
+
+// RegisterTransform makes fn available as {name:argument} in values,
+// replacing any transform already registered for name. The built-in
+// transforms are:
+//
+//	lower      the argument in lower case
+//	upper      the argument in upper case
+//	trim       the argument without leading and trailing white space
+//	urlencode  the argument escaped for use in a URL query
+//	b64encode  the standard base64 encoding of the argument
+//	b64decode  the standard or URL-safe base64 decoding of the argument
+//
+// Expressions are evaluated after {$name} references are substituted,
+// innermost first, so they can be nested: {upper:{trim:{$RAW}}}. Text
+// in braces that does not name a registered transform is left as it is.
+// The name "generate" is reserved for {generate:...} directives.
+func RegisterTransform(name string, fn Transform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = fn
+}
+
+// Transforms returns the names of the registered transforms in sorted
+// order.
+func Transforms() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupTransform(name string) (Transform, bool) {
+	if name == "generate" {
+		return nil, false
+	}
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	fn, exists := transforms[name]
+	return fn, exists
+}
+
+func transformBase64Decode(s string) (string, error) {
+	return decodeBase64(s, "")
+}
+
+// transform evaluates the {name:argument} expressions in value, unless
+// expansion is disabled.
+func (p *parser) transform(value string) (string, error) {
+	if p.options.expansion == ExpandNone || !strings.Contains(value, ":") {
+		return value, nil
+	}
+
+	// Each pass evaluates the innermost expressions, so that the result
+	// of an inner expression becomes the argument of the outer one.
+	for depth := 0; ; depth++ {
+		if depth == maxTransformDepth {
+			return "", fmt.Errorf("error: '%s' at line %d: transforms nested more than %d levels deep: %w", p.source, p.lineNumber, maxTransformDepth, ErrSyntax)
+		}
+		var err error
+		replaced := false
+		next := transformRegex.ReplaceAllStringFunc(value, func(s string) string {
+			m := transformRegex.FindStringSubmatch(s)
+			fn, exists := lookupTransform(m[1])
+			if !exists || err != nil {
+				return s
+			}
+			result, ferr := fn(m[2])
+			if ferr != nil {
+				err = fmt.Errorf("error: '%s' at line %d: {%s:...}: %v: %w", p.source, p.lineNumber, m[1], ferr, ErrSyntax)
+				return s
+			}
+			replaced = true
+			return result
+		})
+		if err != nil {
+			return "", err
+		}
+		if !replaced {
+			return next, nil
+		}
+		value = next
+	}
+}
+
+// hasTransform reports whether value contains an expression naming a
+// registered transform.
+func hasTransform(value string) bool {
+	for _, m := range transformRegex.FindAllStringSubmatch(value, -1) {
+		if _, exists := lookupTransform(m[1]); exists {
+			return true
+		}
+	}
+	return false
+}
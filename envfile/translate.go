@@ -0,0 +1,53 @@
+package envfile
+
+// Translation records a key that was renamed by WithKeyMap or
+// WithKeyTranslator.
+type Translation struct {
+	// From is the key as written in the source, and To the key it was
+	// loaded as.
+	From, To string
+	// Source is the file or Source the key was read from.
+	Source string
+}
+
+// WithKeyMap renames keys while they are loaded, so that files still
+// using legacy names keep working during a migration:
+//
+//	envfile.WithKeyMap(map[string]string{"DB_URL": "DATABASE_URL"})
+//
+// Keys not in m are kept. See WithKeyTranslator.
+func WithKeyMap(m map[string]string) Option {
+	translations := make(map[string]string, len(m))
+	for from, to := range m {
+		translations[from] = to
+	}
+	return WithKeyTranslator(func(key string) string {
+		if to, found := translations[key]; found {
+			return to
+		}
+		return key
+	})
+}
+
+// WithKeyTranslator renames every parsed key to translate(key) before
+// Middleware, WithKeys and the other key options see it, and before it is
+// set by Load or returned by Environment, Read and Parse. Returning the
+// key unchanged keeps it. Calling WithKeyTranslator or WithKeyMap more
+// than once applies the translations in order.
+//
+// Every key that is renamed is logged as a warning, so that the files can
+// be updated, and Load lists it in Result.Translated. If a file defines
+// both the old and the new name, the definition that comes later wins.
+func WithKeyTranslator(translate func(key string) string) Option {
+	return func(o *options) {
+		o.translators = append(o.translators[:len(o.translators):len(o.translators)], translate)
+	}
+}
+
+// translate applies the configured translators to key.
+func (o *options) translate(key string) string {
+	for _, translate := range o.translators {
+		key = translate(key)
+	}
+	return key
+}
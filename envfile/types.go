@@ -0,0 +1,150 @@
+package envfile
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// typeCheckers validates values of each type that can be declared with
+// the KEY:type=value syntax or a "# @type KEY type" annotation.
+var typeCheckers = map[string]func(string) error{
+	"string": func(string) error { return nil },
+	"int": func(s string) error {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err
+	},
+	"uint": func(s string) error {
+		_, err := strconv.ParseUint(s, 10, 64)
+		return err
+	},
+	"float": func(s string) error {
+		_, err := strconv.ParseFloat(s, 64)
+		return err
+	},
+	"bool": func(s string) error {
+		_, err := strconv.ParseBool(s)
+		return err
+	},
+	"duration": func(s string) error {
+		_, err := time.ParseDuration(s)
+		return err
+	},
+	"url": func(s string) error {
+		u, err := url.Parse(s)
+		if err == nil && (u.Scheme == "" || u.Host == "" && u.Opaque == "") {
+			return fmt.Errorf("missing scheme or host")
+		}
+		return err
+	},
+}
+
+// CheckType reports whether value is valid for typ, one of the types
+// that can be declared with the KEY:type=value syntax or a
+// "# @type KEY type" annotation: string, int, uint, float, bool, duration
+// or url. An empty value is valid for every type, as a key that is
+// declared but not set, such as PORT:int= in an example file.
+func CheckType(typ, value string) error {
+	return checkType(typ, value)
+}
+
+func checkType(typ, value string) error {
+	check, known := typeCheckers[typ]
+	if !known {
+		return fmt.Errorf("unknown type '%s'", typ)
+	}
+	if value == "" {
+		return nil
+	}
+	if err := check(value); err != nil {
+		return fmt.Errorf("'%s' is not a valid %s", value, typ)
+	}
+	return nil
+}
+
+// splitKeyType splits a key written as KEY:type into its name and type.
+// A key without a known type suffix is returned unchanged with an empty
+// type.
+func splitKeyType(key string) (string, string) {
+	index := strings.LastIndex(key, ":")
+	if index == -1 {
+		return key, ""
+	}
+	name, typ := key[:index], key[index+1:]
+	if _, known := typeCheckers[typ]; !known {
+		return key, ""
+	}
+	return name, typ
+}
+
+// Type returns the type declared for key in the file, such as "int" or
+// "duration", and whether a type was declared.
+func (e *Environment) Type(key string) (string, bool) {
+	typ, declared := e.types[key]
+	if !declared && e.folded != nil {
+		typ, declared = e.types[e.folded[strings.ToUpper(key)]]
+	}
+	return typ, declared
+}
+
+// GetInt returns the value of key parsed as an int.
+func (e *Environment) GetInt(key string) (int, error) {
+	value, err := e.lookupRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("error: value '%s' of '%s' is not a valid int", value, key)
+	}
+	return n, nil
+}
+
+// GetBool returns the value of key parsed with strconv.ParseBool.
+func (e *Environment) GetBool(key string) (bool, error) {
+	value, err := e.lookupRequired(key)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("error: value '%s' of '%s' is not a valid bool", value, key)
+	}
+	return b, nil
+}
+
+// GetFloat returns the value of key parsed as a float64.
+func (e *Environment) GetFloat(key string) (float64, error) {
+	value, err := e.lookupRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error: value '%s' of '%s' is not a valid float", value, key)
+	}
+	return f, nil
+}
+
+// GetDuration returns the value of key parsed with time.ParseDuration.
+func (e *Environment) GetDuration(key string) (time.Duration, error) {
+	value, err := e.lookupRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("error: value '%s' of '%s' is not a valid duration", value, key)
+	}
+	return d, nil
+}
+
+func (e *Environment) lookupRequired(key string) (string, error) {
+	value, exists := e.Lookup(key)
+	if !exists {
+		return "", fmt.Errorf("error: variable '%s' is not set", key)
+	}
+	return value, nil
+}
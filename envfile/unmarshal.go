@@ -0,0 +1,354 @@
+package envfile
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal decodes the process environment into the struct pointed to by
+// v. See Environment.Unmarshal for the supported tags and field types.
+func Unmarshal(v any) error {
+	return unmarshal(&decodeSource{lookup: os.LookupEnv, keys: environKeys}, v)
+}
+
+// Unmarshal decodes the variables of e into the struct pointed to by v.
+//
+// Each exported field tagged `env:"KEY"` receives the value of KEY. A
+// `default:"value"` tag supplies a value for unset keys, and the tag option
+// `env:"KEY,required"` fails decoding if KEY is unset and has no default.
+// Untagged struct fields are decoded recursively; other untagged fields
+// and fields tagged `env:"-"` are skipped. All problems are reported
+// together in the returned error.
+//
+// Keys can express hierarchy with "__" or ".", following the conventions
+// of ASP.NET configuration and viper. A struct field tagged `env:"KEY"`
+// decodes its own fields from keys below KEY, so DATABASE__PRIMARY__HOST
+// (or DATABASE.PRIMARY.HOST) fills
+//
+//	type Config struct {
+//		Database struct {
+//			Primary struct {
+//				Host string `env:"HOST"`
+//			} `env:"PRIMARY"`
+//		} `env:"DATABASE"`
+//	}
+//
+// A map field with string keys tagged `env:"KEY"` collects every key below
+// KEY: map[string]string receives the rest of each key, such as "EU" for
+// REPLICAS__EU, while a map of structs groups keys by their next segment,
+// so REPLICAS__EU__HOST fills the Host field of the "EU" entry.
+//
+// Supported field types are strings, booleans, integers, unsigned
+// integers, floats, time.Duration, time.Time, url.URL, types implementing
+// encoding.TextUnmarshaler such as net.IP and netip.Addr, pointers to
+// these, and slices of these, whose values are separated by commas or
+// written as a JSON array.
+//
+// time.Time values are parsed as RFC 3339 unless a `layout` tag gives
+// another layout in the syntax of time.Parse. Times without a zone offset
+// are read in UTC, or in the IANA time zone named by a `timezone` tag:
+//
+//	type Config struct {
+//		Launch time.Time `env:"LAUNCH" layout:"2006-01-02 15:04" timezone:"Europe/Paris"`
+//	}
+func (e *Environment) Unmarshal(v any) error {
+	return unmarshal(&decodeSource{lookup: e.Lookup, keys: e.Keys}, v)
+}
+
+// decodeSource provides the variables decoded by Unmarshal.
+type decodeSource struct {
+	lookup func(string) (string, bool)
+	keys   func() []string
+}
+
+// lookupPath returns the value of the key made of path, joined by "__"
+// or, failing that, by ".".
+func (s *decodeSource) lookupPath(path []string) (string, bool) {
+	if value, exists := s.lookup(strings.Join(path, "__")); exists {
+		return value, true
+	}
+	if len(path) > 1 {
+		return s.lookup(strings.Join(path, "."))
+	}
+	return "", false
+}
+
+// below returns the remaining segments of every key below path.
+func (s *decodeSource) below(path []string) [][]string {
+	var rests [][]string
+	for _, key := range s.keys() {
+		segments := splitNestedKey(key)
+		if len(segments) <= len(path) {
+			continue
+		}
+		matches := true
+		for i, segment := range path {
+			if segments[i] != segment {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			rests = append(rests, segments[len(path):])
+		}
+	}
+	return rests
+}
+
+// splitNestedKey splits a key at "__", or at "." if it has no "__".
+func splitNestedKey(key string) []string {
+	if strings.Contains(key, "__") {
+		return strings.Split(key, "__")
+	}
+	return strings.Split(key, ".")
+}
+
+func environKeys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if key, _, found := strings.Cut(kv, "="); found && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func unmarshal(src *decodeSource, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("error: Unmarshal requires a non-nil pointer to a struct, got %T", v)
+	}
+	return decodeStruct(src, nil, rv.Elem())
+}
+
+func decodeStruct(src *decodeSource, path []string, rv reflect.Value) error {
+	var errs []error
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+
+		tag, tagged := field.Tag.Lookup("env")
+		if !tagged {
+			if isNested(fv) {
+				if err := decodeStruct(src, path, fv); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		required := false
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "required" {
+				required = true
+			}
+		}
+		fieldPath := append(path[:len(path):len(path)], key)
+
+		if isNested(fv) {
+			if err := decodeStruct(src, fieldPath, fv); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Map && fv.Type().Key().Kind() == reflect.String {
+			if err := decodeMap(src, fieldPath, fv); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		value, exists := src.lookupPath(fieldPath)
+		if !exists {
+			if def, hasDefault := field.Tag.Lookup("default"); hasDefault {
+				value, exists = def, true
+			}
+		}
+		name := strings.Join(fieldPath, "__")
+		if !exists {
+			if required {
+				errs = append(errs, fmt.Errorf("error: required variable '%s' is not set", name))
+			}
+			continue
+		}
+
+		if err := decodeValue(fv, value, field.Tag); err != nil {
+			errs = append(errs, fmt.Errorf("error: unable to decode '%s' into field %s: %v", name, field.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isNested reports whether fv is a struct decoded field by field rather
+// than from a single value.
+func isNested(fv reflect.Value) bool {
+	return fv.Kind() == reflect.Struct && fv.Type() != urlType && !implementsTextUnmarshaler(fv)
+}
+
+// decodeMap fills a map field from the keys below path.
+func decodeMap(src *decodeSource, path []string, fv reflect.Value) error {
+	mt := fv.Type()
+	m := reflect.MakeMap(mt)
+	elem := reflect.New(mt.Elem()).Elem()
+	nested := isNested(elem)
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, rest := range src.below(path) {
+		name := strings.Join(rest, "__")
+		if nested {
+			name = rest[0]
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		value := reflect.New(mt.Elem()).Elem()
+		if nested {
+			if err := decodeStruct(src, append(path[:len(path):len(path)], name), value); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		} else {
+			raw, _ := src.lookupPath(append(path[:len(path):len(path)], rest...))
+			if err := decodeValue(value, raw, ""); err != nil {
+				errs = append(errs, fmt.Errorf("error: unable to decode '%s' into map entry '%s': %v", strings.Join(append(path[:len(path):len(path)], rest...), "__"), name, err))
+				continue
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(name).Convert(mt.Key()), value)
+	}
+	if m.Len() > 0 {
+		fv.Set(m)
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func implementsTextUnmarshaler(fv reflect.Value) bool {
+	return fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType)
+}
+
+// decodeValue decodes value into fv, reading the `layout` and `timezone`
+// tags of the field for times.
+func decodeValue(fv reflect.Value, value string, tag reflect.StructTag) error {
+	switch fv.Type() {
+	case timeType:
+		t, err := parseTime(value, tag)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	if implementsTextUnmarshaler(fv) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Pointer:
+		ptr := reflect.New(fv.Type().Elem())
+		if err := decodeValue(ptr.Elem(), value, tag); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		parts, err := splitList(value, DefaultListSeparator)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := decodeValue(slice.Index(i), part, tag); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// parseTime parses value with the `layout` tag, RFC 3339 by default, in
+// the location named by the `timezone` tag, UTC by default.
+func parseTime(value string, tag reflect.StructTag) (time.Time, error) {
+	layout := time.RFC3339
+	if l, ok := tag.Lookup("layout"); ok && l != "" {
+		layout = l
+	}
+	loc := time.UTC
+	if name, ok := tag.Lookup("timezone"); ok && name != "" {
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
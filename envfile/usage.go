@@ -0,0 +1,63 @@
+package envfile
+
+import "sync"
+
+// usage records the keys read from an Environment.
+type usage struct {
+	mu   sync.Mutex
+	read map[string]bool
+}
+
+func (u *usage) record(key string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.read[key] = true
+}
+
+// Tracked returns a copy of e that records which keys the application
+// reads, through Get, Lookup and the functions built on them, such as
+// GetInt, GetStringSlice, Unmarshal and BindFlags, so that Unused can
+// report the keys that were loaded but never read:
+//
+//	env = env.Tracked()
+//	run(env)
+//	for _, key := range env.Unused() {
+//		log.Printf("configuration key %s is never used", key)
+//	}
+//
+// Keys, Entries, Map and Range do not count as reads. Recording is safe
+// for concurrent use; the values of the copy are as immutable as those
+// of e.
+func (e *Environment) Tracked() *Environment {
+	tracked := *e
+	tracked.usage = &usage{read: make(map[string]bool)}
+	return &tracked
+}
+
+// Used returns the keys read from e since Tracked returned it, in the
+// order they were first defined. It returns nil if e is not tracked.
+func (e *Environment) Used() []string {
+	return e.usageKeys(true)
+}
+
+// Unused returns the keys of e that were not read since Tracked returned
+// it, in the order they were first defined. It returns nil if e is not
+// tracked.
+func (e *Environment) Unused() []string {
+	return e.usageKeys(false)
+}
+
+func (e *Environment) usageKeys(read bool) []string {
+	if e.usage == nil {
+		return nil
+	}
+	e.usage.mu.Lock()
+	defer e.usage.mu.Unlock()
+	keys := []string{}
+	for _, key := range e.keys {
+		if e.usage.read[key] == read {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
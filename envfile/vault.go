@@ -0,0 +1,178 @@
+package envfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VaultFile is the name of the encrypted bundle read when WithVault is
+// set.
+const VaultFile = ".env.vault"
+
+// ErrVault is wrapped by the error returned when a .env.vault file cannot
+// be decrypted: DOTENV_KEY is malformed, names an environment the vault
+// does not contain, or holds the wrong key.
+var ErrVault = errors.New("vault decryption failed")
+
+// WithVault enables the dotenv-vault format. When DOTENV_KEY is set and a
+// .env.vault file exists in the search directory, the environment named
+// by DOTENV_KEY is decrypted from the vault and loaded instead of the
+// candidate files. If DOTENV_KEY is not set, or the vault does not exist,
+// the candidate files are loaded as usual.
+//
+// DOTENV_KEY has the form
+//
+//	dotenv://:key_<64 hex digits>@dotenv.org/vault/.env.vault?environment=production
+//
+// and may hold several comma-separated keys, which are tried in order,
+// to allow rotating keys.
+func WithVault() Option {
+	return func(o *options) {
+		o.vault = true
+	}
+}
+
+// DecryptVault decrypts the environment named by dotenvKey from the
+// .env.vault file at filePath and returns its content.
+func DecryptVault(filePath, dotenvKey string) ([]byte, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	plaintext, err := decryptVault(content, dotenvKey)
+	if err != nil {
+		return nil, fmt.Errorf("error: '%s': %w", filePath, err)
+	}
+	return plaintext, nil
+}
+
+// decryptVault tries each comma-separated key of dotenvKey in turn.
+func decryptVault(content []byte, dotenvKey string) ([]byte, error) {
+	blobs := readVault(content)
+	var errs []error
+	for _, k := range strings.Split(dotenvKey, ",") {
+		plaintext, err := decryptVaultKey(blobs, strings.TrimSpace(k))
+		if err == nil {
+			return plaintext, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func decryptVaultKey(blobs map[string]string, dotenvKey string) ([]byte, error) {
+	u, err := url.Parse(dotenvKey)
+	if err != nil || u.Scheme != "dotenv" || u.User == nil {
+		return nil, fmt.Errorf("DOTENV_KEY is not a dotenv:// URI: %w", ErrVault)
+	}
+	password, _ := u.User.Password()
+	if len(password) < 64 {
+		return nil, fmt.Errorf("DOTENV_KEY is missing its key: %w", ErrVault)
+	}
+	key, err := hex.DecodeString(password[len(password)-64:])
+	if err != nil {
+		return nil, fmt.Errorf("DOTENV_KEY holds an invalid key: %w", ErrVault)
+	}
+	environment := u.Query().Get("environment")
+	if environment == "" {
+		return nil, fmt.Errorf("DOTENV_KEY is missing the environment parameter: %w", ErrVault)
+	}
+
+	name := "DOTENV_VAULT_" + strings.ToUpper(environment)
+	blob, exists := blobs[name]
+	if !exists {
+		return nil, fmt.Errorf("the vault has no %s entry: %w", name, ErrVault)
+	}
+	data, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid base64: %w", name, ErrVault)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrVault)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrVault)
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is too short: %w", name, ErrVault)
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt %s: the key is wrong or the entry is corrupt: %w", name, ErrVault)
+	}
+	return plaintext, nil
+}
+
+// readVault returns the DOTENV_VAULT_* entries of a vault file, with the
+// surrounding quotes removed.
+func readVault(content []byte) map[string]string {
+	blobs := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		blobs[strings.TrimSpace(key)] = value
+	}
+	return blobs
+}
+
+// vaultPath returns the path of the vault to load instead of the
+// candidate files in dir, or an empty string if the vault is not in use.
+func (l *Loader) vaultPath(dir string) string {
+	if !l.o.vault || os.Getenv("DOTENV_KEY") == "" {
+		return ""
+	}
+	path := filepath.Join(dir, VaultFile)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		l.o.parse.logf("Warning: DOTENV_KEY is set but '%s' does not exist. Loading the .env files instead.", path)
+		return ""
+	}
+	return path
+}
+
+// isVault reports whether filePath is read as a vault.
+func (l *Loader) isVault(filePath string) bool {
+	return l.o.vault && filepath.Base(filePath) == VaultFile
+}
+
+// parseVault decrypts the vault at filePath with DOTENV_KEY and parses
+// the result.
+func (l *Loader) parseVault(filePath string) ([]variable, error) {
+	encrypted, err := l.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decryptVault(encrypted, os.Getenv("DOTENV_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("error: '%s': %w", filePath, err)
+	}
+	po, err := l.parseOpts()
+	if err != nil {
+		return nil, err
+	}
+	return parseData(content, filePath, po)
+}
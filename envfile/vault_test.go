@@ -0,0 +1,102 @@
+package envfile_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+// writeVault writes a .env.vault file to dir holding plaintext as the
+// production environment, encrypted with key as dotenv-vault does.
+func writeVault(t *testing.T, dir string, key []byte, plaintext string) {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	blob := base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil))
+	content := "DOTENV_VAULT_PRODUCTION=\"" + blob + "\"\n"
+	if err := os.WriteFile(filepath.Join(dir, envfile.VaultFile), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dotenvKey(key []byte, environment string) string {
+	return "dotenv://:key_" + hex.EncodeToString(key) + "@dotenv.org/vault/.env.vault?environment=" + environment
+}
+
+func TestVault(t *testing.T) {
+	key := make([]byte, 32)
+	wrong := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rand.Read(wrong); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		dotenvKey string
+		ok        bool
+	}{
+		{name: "key", dotenvKey: dotenvKey(key, "production"), ok: true},
+		{name: "rotated keys", dotenvKey: dotenvKey(wrong, "production") + "," + dotenvKey(key, "production"), ok: true},
+		{name: "wrong key", dotenvKey: dotenvKey(wrong, "production")},
+		{name: "missing environment", dotenvKey: dotenvKey(key, "staging")},
+		{name: "malformed", dotenvKey: "key_" + hex.EncodeToString(key)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeVault(t, dir, key, "SECRET=value\n")
+			if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=plain\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			plaintext, err := envfile.DecryptVault(filepath.Join(dir, envfile.VaultFile), tt.dotenvKey)
+			if tt.ok != (err == nil) {
+				t.Fatalf("DecryptVault: got %v", err)
+			}
+			if !tt.ok && !errors.Is(err, envfile.ErrVault) {
+				t.Fatalf("DecryptVault: got %v, want an error wrapping ErrVault", err)
+			}
+			if tt.ok && string(plaintext) != "SECRET=value\n" {
+				t.Fatalf("DecryptVault: got %q", plaintext)
+			}
+
+			// A vault that fails to decrypt is not replaced by the .env file;
+			// WithStrict returns the reason along with ErrNoFileLoaded.
+			t.Setenv("DOTENV_KEY", tt.dotenvKey)
+			env, err := envfile.New(envfile.WithVault(), envfile.WithDir(dir), envfile.WithProfile("development"), envfile.WithStrict(), envfile.WithLogger(nil)).Environment()
+			if !tt.ok {
+				if !errors.Is(err, envfile.ErrVault) {
+					t.Fatalf("got %v, want an error wrapping ErrVault", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env.Get("SECRET"); got != "value" {
+				t.Errorf("got %q, want %q", got, "value")
+			}
+		})
+	}
+}
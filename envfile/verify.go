@@ -0,0 +1,210 @@
+package envfile
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrVerification is wrapped by the error returned when a file required
+// to carry a checksum or signature has none, or has one that does not
+// match its content.
+var ErrVerification = errors.New("verification failed")
+
+// checksumPrefix starts the checksum header written by WriteChecksum.
+const checksumPrefix = "# @sha256 "
+
+// verifyOptions lists the verification a file must pass before it is
+// parsed.
+type verifyOptions struct {
+	checksum bool
+	keys     []ed25519.PublicKey
+}
+
+// WithChecksum refuses to load files that do not start with a valid
+// "# @sha256 HEX" header, which holds the SHA-256 checksum of the rest of
+// the file. It detects accidental or careless edits; use WithPublicKey
+// to protect against deliberate tampering. Included files are verified
+// too. WriteChecksum and "envfile checksum" add the header.
+func WithChecksum() Option {
+	return func(o *options) {
+		if o.parse.verify == nil {
+			o.parse.verify = &verifyOptions{}
+		}
+		o.parse.verify.checksum = true
+	}
+}
+
+// WithPublicKey refuses to load files that do not have a detached
+// ed25519 signature made with the private key of one of keys. The
+// signature of FILE is read from FILE.minisig, in the format written by
+// "minisign -S -l", or from FILE.sig, holding the base64-encoded
+// signature written by Sign and "envfile sign". Included files are
+// verified too.
+func WithPublicKey(keys ...ed25519.PublicKey) Option {
+	return func(o *options) {
+		if o.parse.verify == nil {
+			o.parse.verify = &verifyOptions{}
+		}
+		o.parse.verify.keys = append(o.parse.verify.keys, keys...)
+	}
+}
+
+// verify reads the file at filePath and checks it against the configured
+// requirements. It returns the content it checked, which is the content
+// to parse: opening the file again would let it be replaced in between.
+func (v *verifyOptions) verify(filePath string) ([]byte, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	if v.checksum {
+		if err := verifyChecksum(content); err != nil {
+			return nil, fmt.Errorf("error: '%s': %v: %w", filePath, err, ErrVerification)
+		}
+	}
+	if len(v.keys) > 0 {
+		if err := verifySignature(filePath, content, v.keys); err != nil {
+			return nil, fmt.Errorf("error: '%s': %v: %w", filePath, err, ErrVerification)
+		}
+	}
+	return content, nil
+}
+
+// splitChecksum separates the checksum header from the content it
+// covers. The header is empty if the content has none.
+func splitChecksum(content []byte) (header string, body []byte) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	line, rest, _ := bytes.Cut(content, []byte("\n"))
+	if !bytes.HasPrefix(line, []byte(checksumPrefix)) {
+		return "", content
+	}
+	return strings.TrimSpace(string(line[len(checksumPrefix):])), rest
+}
+
+func verifyChecksum(content []byte) error {
+	header, body := splitChecksum(content)
+	if header == "" {
+		return fmt.Errorf("missing '%s' checksum header", strings.TrimSpace(checksumPrefix))
+	}
+	sum := sha256.Sum256(body)
+	if !strings.EqualFold(header, hex.EncodeToString(sum[:])) {
+		return fmt.Errorf("checksum does not match the content")
+	}
+	return nil
+}
+
+// WriteChecksum adds or replaces the checksum header of the file at
+// filePath, for use with WithChecksum. The file is locked with LockFile
+// and replaced atomically, and opts may include WithBackup to keep its
+// previous content.
+func WriteChecksum(filePath string, opts ...Option) error {
+	lock, err := LockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	_, body := splitChecksum(content)
+	sum := sha256.Sum256(body)
+
+	header := checksumPrefix + hex.EncodeToString(sum[:]) + "\n"
+	return WriteFileAtomic(filePath, append([]byte(header), body...), 0o600, opts...)
+}
+
+func verifySignature(filePath string, content []byte, keys []ed25519.PublicKey) error {
+	if data, err := os.ReadFile(filePath + ".minisig"); err == nil {
+		return verifyMinisign(data, content, keys)
+	}
+
+	data, err := os.ReadFile(filePath + ".sig")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("file is not signed")
+		}
+		return fmt.Errorf("unable to read signature: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed signature in '%s.sig'", filePath)
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, content, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match the content or any trusted key")
+}
+
+// verifyMinisign checks a minisign signature file. Only legacy,
+// non-prehashed signatures are supported, since prehashed ones use
+// BLAKE2b, which the standard library does not provide.
+func verifyMinisign(data, content []byte, keys []ed25519.PublicKey) error {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("malformed minisign signature")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("malformed minisign signature")
+	}
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		return fmt.Errorf("prehashed minisign signatures are not supported; sign with 'minisign -S -l'")
+	default:
+		return fmt.Errorf("unknown minisign signature algorithm")
+	}
+	signature := sig[10:]
+
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed minisign signature")
+	}
+	trusted := []byte(strings.TrimPrefix(lines[2], "trusted comment: "))
+
+	for _, key := range keys {
+		if ed25519.Verify(key, content, signature) && ed25519.Verify(key, append(append([]byte{}, signature...), trusted...), global) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match the content or any trusted key")
+}
+
+// ParsePublicKey parses a public key for WithPublicKey: either the
+// base64-encoded key written by "envfile sign -genkey", or a minisign
+// public key on its own or as the content of a minisign.pub file.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	switch {
+	case err != nil:
+	case len(data) == ed25519.PublicKeySize:
+		return ed25519.PublicKey(data), nil
+	case len(data) == 2+8+ed25519.PublicKeySize && string(data[:2]) == "Ed":
+		return ed25519.PublicKey(data[10:]), nil
+	}
+	return nil, fmt.Errorf("error: malformed public key")
+}
+
+// Sign writes the base64-encoded ed25519 signature of the file at
+// filePath to filePath + ".sig", for use with WithPublicKey.
+func Sign(filePath string, key ed25519.PrivateKey) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error: unable to read file '%s': %w: %w", filePath, err, ErrIO)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, content)) + "\n"
+	return writeFileAtomic(filePath+".sig", []byte(signature), 0o644, false)
+}
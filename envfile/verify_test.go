@@ -0,0 +1,103 @@
+package envfile_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucap9056/go-envfile/envfile"
+)
+
+func TestVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := func(t *testing.T, path string) {
+		if err := envfile.WriteChecksum(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sign := func(t *testing.T, path string) {
+		if err := envfile.Sign(path, private); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tamper := func(t *testing.T, path string) {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString("INJECTED=1\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opt     envfile.Option
+		prepare func(t *testing.T, main, included string)
+		ok      bool
+	}{
+		{name: "checksum", opt: envfile.WithChecksum(), prepare: func(t *testing.T, main, included string) {
+			checksum(t, main)
+			checksum(t, included)
+		}, ok: true},
+		{name: "checksum tampered", opt: envfile.WithChecksum(), prepare: func(t *testing.T, main, included string) {
+			checksum(t, main)
+			checksum(t, included)
+			tamper(t, main)
+		}},
+		{name: "checksum include tampered", opt: envfile.WithChecksum(), prepare: func(t *testing.T, main, included string) {
+			checksum(t, main)
+			checksum(t, included)
+			tamper(t, included)
+		}},
+		{name: "signature", opt: envfile.WithPublicKey(public), prepare: func(t *testing.T, main, included string) {
+			sign(t, main)
+			sign(t, included)
+		}, ok: true},
+		{name: "signature tampered", opt: envfile.WithPublicKey(public), prepare: func(t *testing.T, main, included string) {
+			sign(t, main)
+			sign(t, included)
+			tamper(t, main)
+		}},
+		{name: "signature include tampered", opt: envfile.WithPublicKey(public), prepare: func(t *testing.T, main, included string) {
+			sign(t, main)
+			sign(t, included)
+			tamper(t, included)
+		}},
+		{name: "unsigned", opt: envfile.WithPublicKey(public), prepare: func(t *testing.T, main, included string) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			main := filepath.Join(dir, ".env")
+			included := filepath.Join(dir, "shared.env")
+			if err := os.WriteFile(main, []byte("#include shared.env\nKEY=value\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(included, []byte("SHARED=value\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			tt.prepare(t, main, included)
+
+			env, err := envfile.New(tt.opt).Read(main)
+			if !tt.ok {
+				if !errors.Is(err, envfile.ErrVerification) {
+					t.Fatalf("got %v, want an error wrapping ErrVerification", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if env.Get("KEY") != "value" || env.Get("SHARED") != "value" {
+				t.Errorf("got %v", env.Map())
+			}
+		})
+	}
+}
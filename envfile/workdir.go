@@ -0,0 +1,71 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DirError reports that the directory searched for candidate files could
+// not be determined or read, for example because the process runs from a
+// directory that was deleted. It wraps both the underlying error and
+// ErrIO.
+type DirError struct {
+	// Op is "getwd" when the current working directory could not be
+	// determined, "resolve" when symlinks in Dir could not be resolved
+	// and "read" when Dir could not be read.
+	Op string
+	// Dir is the directory, or empty if Op is "getwd".
+	Dir string
+	Err error
+}
+
+func (e *DirError) Error() string {
+	switch e.Op {
+	case "getwd":
+		return fmt.Sprintf("error: could not get the current working directory: %v", e.Err)
+	case "resolve":
+		return fmt.Sprintf("error: could not resolve symlinks in the directory '%s': %v", e.Dir, e.Err)
+	default:
+		return fmt.Sprintf("error: could not read the directory '%s': %v", e.Dir, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error and ErrIO.
+func (e *DirError) Unwrap() []error {
+	return []error{e.Err, ErrIO}
+}
+
+// WithResolveSymlinks resolves symlinks in the search directory before
+// looking for candidate files, so that the paths in Result and in log
+// messages, and the directories include paths are relative to, name the
+// real directory instead of a symlink to it.
+func WithResolveSymlinks() Option {
+	return func(o *options) {
+		o.resolveSymlinks = true
+	}
+}
+
+// WithExecutableDirFallback searches the directory containing the running
+// executable when the current working directory cannot be determined,
+// which happens when the process runs from a directory that was deleted.
+// Without it, Load fails with a *DirError. The fallback is logged. It has
+// no effect if WithDir is set.
+func WithExecutableDirFallback() Option {
+	return func(o *options) {
+		o.executableFallback = true
+	}
+}
+
+// executableDir returns the directory of the running executable, with
+// symlinks resolved.
+func executableDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Dir(exe), nil
+}